@@ -3,6 +3,7 @@ package adapter
 import (
 	"context"
 	"net/netip"
+	"time"
 
 	C "github.com/sagernet/sing-box/constant"
 	"github.com/sagernet/sing-box/log"
@@ -28,6 +29,7 @@ type DNSClient interface {
 	LookupCache(domain string, strategy C.DomainStrategy) ([]netip.Addr, bool)
 	ExchangeCache(ctx context.Context, message *dns.Msg) (*dns.Msg, bool)
 	ClearCache()
+	ClearMemoryCache()
 }
 
 type DNSQueryOptions struct {
@@ -36,6 +38,8 @@ type DNSQueryOptions struct {
 	DisableCache bool
 	RewriteTTL   *uint32
 	ClientSubnet netip.Prefix
+	RewriteName  string
+	DNSSEC       bool
 }
 
 type RDRCStore interface {
@@ -44,6 +48,14 @@ type RDRCStore interface {
 	SaveRDRCAsync(transportName string, qName string, qType uint16, logger logger.Logger)
 }
 
+// DNSCacheStore persists DNS responses across restarts.
+// An empty transport name refers to the cache shared by all transports.
+type DNSCacheStore interface {
+	LoadDNSCache(transportName string, question dns.Question) (response *dns.Msg, expireAt time.Time, loaded bool)
+	SaveDNSCacheAsync(transportName string, question dns.Question, response *dns.Msg, expireAt time.Time, logger logger.Logger)
+	ClearDNSCache() error
+}
+
 type DNSTransport interface {
 	Type() string
 	Tag() string
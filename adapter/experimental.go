@@ -6,17 +6,48 @@ import (
 	"encoding/binary"
 	"time"
 
+	M "github.com/sagernet/sing/common/metadata"
 	"github.com/sagernet/sing/common/varbin"
 )
 
 type ClashServer interface {
 	LifecycleService
 	ConnectionTracker
+	Tracer
+	DNSQueryRecorder
 	Mode() string
 	ModeList() []string
 	HistoryStorage() URLTestHistoryStorage
 }
 
+// Tracer receives routing, DNS and dial events.
+// Callers should skip building events when TracingEnabled returns false.
+type Tracer interface {
+	TracingEnabled() bool
+	TraceRuleMatch(ctx context.Context, metadata InboundContext, matchedRule Rule, matchOutbound string, duration time.Duration, err error)
+	TraceDial(ctx context.Context, metadata InboundContext, outbound string, duration time.Duration, err error)
+	TraceDNS(ctx context.Context, name string, queryType string, transport string, answers []string, cached bool, duration time.Duration, err error)
+}
+
+// DNSQueryRecorder receives every DNS query answered by the DNS router.
+type DNSQueryRecorder interface {
+	RecordDNSQuery(record DNSQueryRecord)
+}
+
+type DNSQueryRecord struct {
+	Source    M.Socksaddr
+	Inbound   string
+	Domain    string
+	QueryType string
+	Rule      string
+	Transport string
+	RCode     string
+	Answers   []string
+	Cached    bool
+	Duration  time.Duration
+	Error     error
+}
+
 type URLTestHistory struct {
 	Time  time.Time `json:"time"`
 	Delay uint16    `json:"delay"`
@@ -44,6 +75,9 @@ type CacheFile interface {
 	StoreRDRC() bool
 	RDRCStore
 
+	StoreDNSCache() bool
+	DNSCacheStore
+
 	LoadMode() string
 	StoreMode(mode string) error
 	LoadSelected(group string) string
@@ -52,6 +86,12 @@ type CacheFile interface {
 	StoreGroupExpand(group string, expand bool) error
 	LoadRuleSet(tag string) *SavedBinary
 	SaveRuleSet(tag string, set *SavedBinary) error
+	LoadProvider(tag string) *SavedProvider
+	SaveProvider(tag string, provider *SavedProvider) error
+	LoadProviderHistory(tag string) map[string]*URLTestHistory
+	SaveProviderHistory(tag string, history map[string]*URLTestHistory) error
+	LoadUserTraffic(inbound string, user string) uint64
+	StoreUserTraffic(inbound string, user string, traffic uint64) error
 }
 
 type SavedBinary struct {
@@ -105,6 +145,73 @@ func (s *SavedBinary) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+type SavedProvider struct {
+	Content     []byte
+	LastUpdated time.Time
+	LastEtag    string
+	Upload      int64
+	Download    int64
+	Total       int64
+	Expire      int64
+}
+
+func (s *SavedProvider) MarshalBinary() ([]byte, error) {
+	var buffer bytes.Buffer
+	err := binary.Write(&buffer, binary.BigEndian, uint8(1))
+	if err != nil {
+		return nil, err
+	}
+	err = varbin.Write(&buffer, binary.BigEndian, s.Content)
+	if err != nil {
+		return nil, err
+	}
+	err = binary.Write(&buffer, binary.BigEndian, s.LastUpdated.Unix())
+	if err != nil {
+		return nil, err
+	}
+	err = varbin.Write(&buffer, binary.BigEndian, s.LastEtag)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range []int64{s.Upload, s.Download, s.Total, s.Expire} {
+		err = binary.Write(&buffer, binary.BigEndian, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+func (s *SavedProvider) UnmarshalBinary(data []byte) error {
+	reader := bytes.NewReader(data)
+	var version uint8
+	err := binary.Read(reader, binary.BigEndian, &version)
+	if err != nil {
+		return err
+	}
+	err = varbin.Read(reader, binary.BigEndian, &s.Content)
+	if err != nil {
+		return err
+	}
+	var lastUpdated int64
+	err = binary.Read(reader, binary.BigEndian, &lastUpdated)
+	if err != nil {
+		return err
+	}
+	s.LastUpdated = time.Unix(lastUpdated, 0)
+	err = varbin.Read(reader, binary.BigEndian, &s.LastEtag)
+	if err != nil {
+		return err
+	}
+	for _, value := range []*int64{&s.Upload, &s.Download, &s.Total, &s.Expire} {
+		err = binary.Read(reader, binary.BigEndian, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type OutboundGroup interface {
 	Outbound
 	Now() string
@@ -11,6 +11,8 @@ type FakeIPStore interface {
 	Contains(address netip.Addr) bool
 	Create(domain string, isIPv6 bool) (netip.Addr, error)
 	Lookup(address netip.Addr) (string, bool)
+	LookupDomain(domain string, isIPv6 bool) (netip.Addr, bool)
+	Range(f func(address netip.Addr, domain string) bool) error
 	Reset() error
 }
 
@@ -22,10 +24,12 @@ type FakeIPStorage interface {
 	FakeIPStoreAsync(address netip.Addr, domain string, logger logger.Logger)
 	FakeIPLoad(address netip.Addr) (string, bool)
 	FakeIPLoadDomain(domain string, isIPv6 bool) (netip.Addr, bool)
+	FakeIPRange(f func(address netip.Addr, domain string) bool) error
 	FakeIPReset() error
 }
 
 type FakeIPTransport interface {
 	DNSTransport
 	Store() FakeIPStore
+	Excluded(domain string) bool
 }
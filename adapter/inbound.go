@@ -2,6 +2,7 @@ package adapter
 
 import (
 	"context"
+	"net"
 	"net/netip"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/sagernet/sing-box/log"
 	"github.com/sagernet/sing-box/option"
 	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
 )
 
 type Inbound interface {
@@ -28,6 +30,39 @@ type UDPInjectableInbound interface {
 	PacketConnectionHandlerEx
 }
 
+// ManagedUserInbound is a server inbound whose users can be changed at runtime without reloading.
+type ManagedUserInbound interface {
+	Inbound
+	AddUser(user InboundUser) error
+	RemoveUser(name string) error
+	User(name string) (InboundUser, bool)
+	ListUsers() []InboundUser
+}
+
+// InboundUser is a user of a ManagedUserInbound, each protocol only uses the fields it needs.
+type InboundUser struct {
+	Name     string `json:"name"`
+	UUID     string `json:"uuid,omitempty"`
+	Password string `json:"password,omitempty"`
+	Flow     string `json:"flow,omitempty"`
+	AlterID  int    `json:"alter_id,omitempty"`
+
+	TrafficLimit uint64 `json:"traffic_limit,omitempty"`
+	ExpireAt     int64  `json:"expire_at,omitempty"`
+	SpeedLimit   uint64 `json:"speed_limit,omitempty"`
+}
+
+// InboundUserLimiter enforces the traffic limit, expiry and speed limit of ManagedUserInbound users.
+type InboundUserLimiter interface {
+	LifecycleService
+	// LimitConnection is called by the router before the outbound is dialed,
+	// it returns an error if the user of the connection has reached a limit.
+	LimitConnection(ctx context.Context, conn net.Conn, metadata InboundContext) (net.Conn, error)
+	LimitPacketConnection(ctx context.Context, conn N.PacketConn, metadata InboundContext) (N.PacketConn, error)
+	TrafficUsage(inbound string, user string) uint64
+	ResetTrafficUsage(inbound string, user string) error
+}
+
 type InboundRegistry interface {
 	option.InboundOptionsRegistry
 	Create(ctx context.Context, router Router, logger log.ContextLogger, tag string, inboundType string, options any) (Inbound, error)
@@ -58,6 +93,9 @@ type InboundContext struct {
 	Client       string
 	SniffContext any
 
+	// ReverseMappingDomain is the domain of the destination IP found by the DNS reverse mapping.
+	ReverseMappingDomain string
+
 	// cache
 
 	// Deprecated: implement in rule action
@@ -97,6 +135,9 @@ type InboundContext struct {
 	DestinationPortMatch         bool
 	DidMatch                     bool
 	IgnoreDestinationIPCIDRMatch bool
+
+	// ReverseMappingMatch reports whether a domain item matched the domain found by the DNS reverse mapping.
+	ReverseMappingMatch bool
 }
 
 func (c *InboundContext) ResetRuleCache() {
@@ -14,6 +14,7 @@ type OutboundProvider interface {
 	HealthcheckUrl() string
 	Outbounds() []Outbound
 	Outbound(tag string) (Outbound, bool)
+	OutboundOptions() []option.Outbound
 	UpdateTime() time.Time
 
 	Start() error
@@ -55,7 +55,9 @@ type myProviderAdapter struct {
 	healthcheckInterval time.Duration
 	outboundOverride    *option.OutboundOverrideOptions
 	healchcheckHistory  adapter.URLTestHistoryStorage
+	cacheFile           adapter.CacheFile
 	providerType        string
+	format              string
 	lastUpdated         time.Time
 	outbounds           []adapter.Outbound
 	outboundByTag       map[string]adapter.Outbound
@@ -105,6 +107,12 @@ func (a *myProviderAdapter) Outbounds() []adapter.Outbound {
 	return outbounds
 }
 
+func (a *myProviderAdapter) OutboundOptions() []option.Outbound {
+	var outbounds []option.Outbound
+	outbounds = append(outbounds, a.lastOuts...)
+	return outbounds
+}
+
 func (a *myProviderAdapter) firstStart(ports []string) error {
 	if !O.CheckType(a.types) {
 		return E.New("invalid types")
@@ -179,7 +187,7 @@ func (a *myProviderAdapter) createOutbounds(ctx context.Context, router adapter.
 		otype := outbound.Type
 		tag := outbound.Tag
 		switch otype {
-		case C.TypeDirect, C.TypeBlock, C.TypeDNS, C.TypeSelector, C.TypeURLTest:
+		case C.TypeDirect, C.TypeBlock, C.TypeDNS, C.TypeSelector, C.TypeURLTest, C.TypeFallback, C.TypeLoadBalance:
 			continue
 		default:
 			out, err := a.router.OutboundManager().CreateOutbound(
@@ -226,6 +234,8 @@ func (p *myProviderAdapter) getContentFromFile(router adapter.Router) (SubInfo,
 	info, ok := parseSubInfo(firstLine)
 	if ok {
 		content = others
+	} else {
+		info, _ = p.parseContentSubInfo(content)
 	}
 	return info, content
 }
@@ -270,9 +280,7 @@ func (p *myProviderAdapter) parseOutbounds(ctx context.Context, router adapter.R
 	if err != nil {
 		return nil, err
 	}
-	finalOuts := common.Filter(outbounds, func(it option.Outbound) bool {
-		return O.TestIncludes(it.Tag, p.includes) && O.TestExcludes(it.Tag, p.excludes) && O.TestTypes(it.Type, p.types) && O.TestPorts(it.Port(), p.ports)
-	})
+	finalOuts := p.filterOutbounds(outbounds)
 	if !p.checkChange(finalOuts) {
 		return nil, nil
 	}
@@ -280,6 +288,12 @@ func (p *myProviderAdapter) parseOutbounds(ctx context.Context, router adapter.R
 	return p.createOutbounds(ctx, router, finalOuts)
 }
 
+func (p *myProviderAdapter) filterOutbounds(outbounds []option.Outbound) []option.Outbound {
+	return common.Filter(outbounds, func(it option.Outbound) bool {
+		return O.TestIncludes(it.Tag, p.includes) && O.TestExcludes(it.Tag, p.excludes) && O.TestTypes(it.Type, p.types) && O.TestPorts(it.Port(), p.ports)
+	})
+}
+
 func (p *myProviderAdapter) updateProviderFromContent(ctx context.Context, router adapter.Router, content string) (bool, error) {
 	outbounds, err := p.parseOutbounds(ctx, router, decodeBase64Safe(content))
 	if err != nil {
@@ -471,6 +485,7 @@ func (p *myProviderAdapter) healthcheck(ctx context.Context, link string) map[st
 		})
 	}
 	b.Wait()
+	p.saveHistory()
 	for _, outbound := range p.router.OutboundManager().Outbounds() {
 		group, isGroup := outbound.(adapter.OutboundGroup)
 		if !isGroup {
@@ -485,6 +500,36 @@ func (p *myProviderAdapter) healthcheck(ctx context.Context, link string) map[st
 	return result
 }
 
+// loadHistory restores the delay history saved by the last run,
+// so that groups have usable outbounds before the first health check finishes.
+func (p *myProviderAdapter) loadHistory() {
+	if p.cacheFile == nil {
+		return
+	}
+	for tag, history := range p.cacheFile.LoadProviderHistory(p.tag) {
+		if p.healchcheckHistory.LoadURLTestHistory(tag) == nil {
+			p.healchcheckHistory.StoreURLTestHistory(tag, history)
+		}
+	}
+}
+
+func (p *myProviderAdapter) saveHistory() {
+	if p.cacheFile == nil {
+		return
+	}
+	history := make(map[string]*adapter.URLTestHistory)
+	for _, outbound := range p.outbounds {
+		tag := outbound.Tag()
+		if item := p.healchcheckHistory.LoadURLTestHistory(tag); item != nil {
+			history[tag] = item
+		}
+	}
+	err := p.cacheFile.SaveProviderHistory(p.tag, history)
+	if err != nil {
+		p.logger.Warn("save outbound provider ", p.tag, " history: ", err)
+	}
+}
+
 func (p *myProviderAdapter) InterfaceUpdated() {
 	if !p.enableHealthcheck {
 		return
@@ -282,6 +282,43 @@ func convertGRPCTransport(proxy map[string]any) option.V2RayGRPCOptions {
 	return options
 }
 
+func convertSplitHTTPTransport(proxy map[string]any) option.V2RaySplitHTTPOptions {
+	options := option.V2RaySplitHTTPOptions{
+		Headers: map[string]badoption.Listable[string]{},
+	}
+	opts, exists := proxy["xhttp-opts"].(map[string]any)
+	if !exists {
+		opts, exists = proxy["splithttp-opts"].(map[string]any)
+	}
+	if exists {
+		if path, exists := opts["path"].(string); exists {
+			options.Path = path
+		}
+		if host, exists := opts["host"].(string); exists {
+			options.Host = host
+		}
+		if headers, exists := opts["headers"].(map[string]any); exists {
+			for key, valueRaw := range headers {
+				valueArr := []string{}
+				switch value := valueRaw.(type) {
+				case []any:
+					for _, item := range value {
+						valueArr = append(valueArr, fmt.Sprint(item))
+					}
+				default:
+					valueArr = append(valueArr, fmt.Sprint(value))
+				}
+				if strings.ToLower(key) == "host" {
+					options.Host = valueArr[0]
+					continue
+				}
+				options.Headers[key] = valueArr
+			}
+		}
+	}
+	return options
+}
+
 func convertDialerOption(proxy map[string]any) option.DialerOptions {
 	options := option.DialerOptions{}
 	if tfo, exists := proxy["tcp-fast-open"].(bool); exists && tfo {
@@ -715,6 +752,9 @@ func newVMessClashParser(proxy map[string]any) (option.Outbound, error) {
 		case "grpc":
 			Transport.Type = C.V2RayTransportTypeGRPC
 			Transport.GRPCOptions = convertGRPCTransport(proxy)
+		case "xhttp", "splithttp":
+			Transport.Type = C.V2RayTransportTypeSplitHTTP
+			Transport.SplitHTTPOptions = convertSplitHTTPTransport(proxy)
 		}
 		options.Transport = &Transport
 	}
@@ -752,6 +792,9 @@ func newVLESSClashParser(proxy map[string]any) (option.Outbound, error) {
 		case "grpc":
 			Transport.Type = C.V2RayTransportTypeGRPC
 			Transport.GRPCOptions = convertGRPCTransport(proxy)
+		case "xhttp", "splithttp":
+			Transport.Type = C.V2RayTransportTypeSplitHTTP
+			Transport.SplitHTTPOptions = convertSplitHTTPTransport(proxy)
 		}
 		options.Transport = &Transport
 	}
@@ -823,6 +866,9 @@ func newTrojanClashParser(proxy map[string]any) (option.Outbound, error) {
 		case "grpc":
 			Transport.Type = C.V2RayTransportTypeGRPC
 			Transport.GRPCOptions = convertGRPCTransport(proxy)
+		case "xhttp", "splithttp":
+			Transport.Type = C.V2RayTransportTypeSplitHTTP
+			Transport.SplitHTTPOptions = convertSplitHTTPTransport(proxy)
 		}
 		options.Transport = &Transport
 	}
@@ -927,6 +973,9 @@ func newHysteria2ClashParser(proxy map[string]any) (option.Outbound, error) {
 	if port, exists := proxy["port"]; exists {
 		options.ServerPort = stringToUint16(fmt.Sprint(port))
 	}
+	if ports, exists := proxy["ports"]; exists {
+		options.ServerPorts = parseServerPorts(fmt.Sprint(ports))
+	}
 	if password, exists := proxy["password"].(string); exists {
 		options.Password = password
 	}
@@ -0,0 +1,715 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/option"
+	O "github.com/sagernet/sing-box/protocol/group"
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+	"github.com/sagernet/sing/common/json"
+	"github.com/sagernet/sing/common/rw"
+
+	R "github.com/dlclark/regexp2"
+	"gopkg.in/yaml.v3"
+)
+
+// Export serializes outbounds into a subscription of the given format.
+// Outbounds that can not be represented in the format are skipped.
+func Export(ctx context.Context, outbounds []option.Outbound, format string) ([]byte, error) {
+	switch format {
+	case "", C.ProviderFormatURI:
+		return exportURI(outbounds), nil
+	case C.ProviderFormatClash:
+		return exportClash(outbounds)
+	case C.ProviderFormatSingBox:
+		return exportSingBox(ctx, outbounds)
+	default:
+		return nil, E.New("unsupported export format: ", format)
+	}
+}
+
+// ReadOutbounds parses the content cached at the provider path with the filters
+// and overrides of options applied, without creating any outbound.
+func ReadOutbounds(ctx context.Context, options option.OutboundProvider) ([]option.Outbound, error) {
+	if options.Path == "" {
+		return nil, E.New("provider path missing")
+	}
+	path := providerPath(ctx, options.Path)
+	if !rw.IsFile(path) {
+		return nil, E.New("provider content not found: ", path)
+	}
+	provider := &myProviderAdapter{
+		tag:              options.Tag,
+		path:             path,
+		outboundOverride: options.OutboundOverride,
+		types:            options.Types,
+		ports:            make(map[int]bool),
+	}
+	switch options.Type {
+	case C.ProviderTypeLocal:
+		provider.format = options.LocalOptions.Format
+	case C.ProviderTypeRemote:
+		provider.format = options.RemoteOptions.Format
+	default:
+		return nil, E.New("invalid provider type: ", options.Type)
+	}
+	if len(options.Includes) > 0 {
+		includes := make([]*R.Regexp, 0, len(options.Includes))
+		for i, include := range options.Includes {
+			regex, err := R.Compile(include, R.IgnoreCase)
+			if err != nil {
+				return nil, E.Cause(err, "parse includes[", i, "]")
+			}
+			includes = append(includes, regex)
+		}
+		provider.includes = includes
+	}
+	if options.Excludes != "" {
+		regex, err := R.Compile(options.Excludes, R.IgnoreCase)
+		if err != nil {
+			return nil, E.Cause(err, "parse excludes")
+		}
+		provider.excludes = regex
+	}
+	if !O.CheckType(provider.types) {
+		return nil, E.New("invalid types")
+	}
+	portMap, err := O.CreatePortsMap(options.Ports)
+	if err != nil {
+		return nil, err
+	}
+	provider.ports = portMap
+	_, content := provider.getContentFromFile(nil)
+	outbounds, err := provider.newParser(decodeBase64Safe(content))
+	if err != nil {
+		return nil, err
+	}
+	return provider.filterOutbounds(outbounds), nil
+}
+
+func exportOptions[T any](outbound option.Outbound) (T, bool) {
+	switch options := outbound.Options.(type) {
+	case T:
+		return options, true
+	case *T:
+		if options != nil {
+			return *options, true
+		}
+	}
+	var defaultValue T
+	return defaultValue, false
+}
+
+func encodeURIComponent(content string) string {
+	return strings.ReplaceAll(url.QueryEscape(content), "+", "%20")
+}
+
+func exportURI(outbounds []option.Outbound) []byte {
+	var links []string
+	for _, outbound := range outbounds {
+		link, loaded := exportOutboundURI(outbound)
+		if !loaded {
+			continue
+		}
+		links = append(links, link)
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(links, "\n") + "\n")
+}
+
+func exportOutboundURI(outbound option.Outbound) (string, bool) {
+	switch outbound.Type {
+	case C.TypeShadowsocks:
+		options, loaded := exportOptions[option.ShadowsocksOutboundOptions](outbound)
+		if !loaded {
+			return "", false
+		}
+		link := url.URL{
+			Scheme: "ss",
+			User:   url.User(base64.RawURLEncoding.EncodeToString([]byte(options.Method + ":" + options.Password))),
+			Host:   net.JoinHostPort(options.Server, strconv.Itoa(int(options.ServerPort))),
+		}
+		if options.Plugin != "" {
+			plugin := options.Plugin
+			if options.PluginOptions != "" {
+				plugin += ";" + options.PluginOptions
+			}
+			link.Path = "/"
+			link.RawQuery = "plugin=" + encodeURIComponent(plugin)
+		}
+		return link.String() + "#" + encodeURIComponent(outbound.Tag), true
+	case C.TypeVMess:
+		options, loaded := exportOptions[option.VMessOutboundOptions](outbound)
+		if !loaded {
+			return "", false
+		}
+		proxy := map[string]any{
+			"v":    "2",
+			"ps":   outbound.Tag,
+			"add":  options.Server,
+			"port": strconv.Itoa(int(options.ServerPort)),
+			"id":   options.UUID,
+			"aid":  strconv.Itoa(options.AlterId),
+			"scy":  options.Security,
+			"net":  "tcp",
+			"type": "none",
+		}
+		if options.Security == "" {
+			proxy["scy"] = "auto"
+		}
+		if options.TLS != nil && options.TLS.Enabled {
+			proxy["tls"] = "tls"
+			if options.TLS.ServerName != "" {
+				proxy["sni"] = options.TLS.ServerName
+			}
+			if len(options.TLS.ALPN) > 0 {
+				proxy["alpn"] = strings.Join(options.TLS.ALPN, ",")
+			}
+			if options.TLS.UTLS != nil && options.TLS.UTLS.Enabled {
+				proxy["fp"] = options.TLS.UTLS.Fingerprint
+			}
+			if options.TLS.Insecure {
+				proxy["insecure"] = "1"
+			}
+		}
+		if options.PacketEncoding != "" {
+			proxy["packet_encoding"] = options.PacketEncoding
+		}
+		if options.Transport != nil {
+			switch options.Transport.Type {
+			case C.V2RayTransportTypeWebsocket:
+				proxy["net"] = "ws"
+				proxy["host"] = websocketHost(options.Transport.WebsocketOptions)
+				proxy["path"] = websocketPath(options.Transport.WebsocketOptions)
+			case C.V2RayTransportTypeHTTP:
+				if options.TLS != nil && options.TLS.Enabled {
+					proxy["net"] = "h2"
+				} else {
+					proxy["type"] = "http"
+				}
+				proxy["host"] = strings.Join(options.Transport.HTTPOptions.Host, ",")
+				proxy["path"] = options.Transport.HTTPOptions.Path
+			case C.V2RayTransportTypeGRPC:
+				proxy["net"] = "grpc"
+				proxy["path"] = options.Transport.GRPCOptions.ServiceName
+			case C.V2RayTransportTypeHTTPUpgrade:
+				proxy["net"] = "httpupgrade"
+				proxy["host"] = options.Transport.HTTPUpgradeOptions.Host
+				proxy["path"] = options.Transport.HTTPUpgradeOptions.Path
+			case C.V2RayTransportTypeSplitHTTP:
+				proxy["net"] = "xhttp"
+				proxy["host"] = options.Transport.SplitHTTPOptions.Host
+				proxy["path"] = options.Transport.SplitHTTPOptions.Path
+			default:
+				return "", false
+			}
+		}
+		content, err := json.Marshal(proxy)
+		if err != nil {
+			return "", false
+		}
+		return "vmess://" + base64.StdEncoding.EncodeToString(content), true
+	case C.TypeVLESS:
+		options, loaded := exportOptions[option.VLESSOutboundOptions](outbound)
+		if !loaded {
+			return "", false
+		}
+		query := url.Values{}
+		query.Set("encryption", "none")
+		if options.Flow != "" {
+			query.Set("flow", options.Flow)
+		}
+		exportTLSQuery(query, options.TLS, true)
+		if !exportTransportQuery(query, options.Transport) {
+			return "", false
+		}
+		return exportUserURI("vless", options.UUID, options.ServerOptions, query, outbound.Tag), true
+	case C.TypeTrojan:
+		options, loaded := exportOptions[option.TrojanOutboundOptions](outbound)
+		if !loaded {
+			return "", false
+		}
+		query := url.Values{}
+		exportTLSQuery(query, options.TLS, false)
+		if !exportTransportQuery(query, options.Transport) {
+			return "", false
+		}
+		return exportUserURI("trojan", options.Password, options.ServerOptions, query, outbound.Tag), true
+	case C.TypeHysteria2:
+		options, loaded := exportOptions[option.Hysteria2OutboundOptions](outbound)
+		if !loaded {
+			return "", false
+		}
+		query := url.Values{}
+		exportTLSQuery(query, options.TLS, false)
+		query.Del("security")
+		if options.Obfs != nil && options.Obfs.Type != "" {
+			query.Set("obfs", options.Obfs.Type)
+			query.Set("obfs-password", options.Obfs.Password)
+		}
+		if len(options.ServerPorts) > 0 {
+			query.Set("mport", exportServerPorts(options.ServerPorts))
+		}
+		if options.UpMbps > 0 {
+			query.Set("up", strconv.Itoa(options.UpMbps))
+		}
+		if options.DownMbps > 0 {
+			query.Set("down", strconv.Itoa(options.DownMbps))
+		}
+		return exportUserURI("hy2", options.Password, options.ServerOptions, query, outbound.Tag), true
+	case C.TypeTUIC:
+		options, loaded := exportOptions[option.TUICOutboundOptions](outbound)
+		if !loaded {
+			return "", false
+		}
+		query := url.Values{}
+		exportTLSQuery(query, options.TLS, false)
+		query.Del("security")
+		if options.CongestionControl != "" {
+			query.Set("congestion_control", options.CongestionControl)
+		}
+		if options.UDPRelayMode != "" {
+			query.Set("udp_relay_mode", options.UDPRelayMode)
+		}
+		if options.UDPOverStream {
+			query.Set("udp_over_stream", "1")
+		}
+		if options.ZeroRTTHandshake {
+			query.Set("reduce_rtt", "1")
+		}
+		link := url.URL{
+			Scheme:   "tuic",
+			User:     url.UserPassword(options.UUID, options.Password),
+			Host:     net.JoinHostPort(options.Server, strconv.Itoa(int(options.ServerPort))),
+			RawQuery: query.Encode(),
+		}
+		return link.String() + "#" + encodeURIComponent(outbound.Tag), true
+	default:
+		return "", false
+	}
+}
+
+func exportUserURI(scheme string, user string, server option.ServerOptions, query url.Values, tag string) string {
+	link := url.URL{
+		Scheme:   scheme,
+		User:     url.User(user),
+		Host:     net.JoinHostPort(server.Server, strconv.Itoa(int(server.ServerPort))),
+		RawQuery: query.Encode(),
+	}
+	return link.String() + "#" + encodeURIComponent(tag)
+}
+
+// exportServerPorts converts port ranges from the start:end form of sing-box to the start-end form of share links and Clash.
+func exportServerPorts(serverPorts []string) string {
+	return strings.Join(common.Map(serverPorts, func(it string) string {
+		start, end, isRange := strings.Cut(it, ":")
+		if !isRange {
+			return it
+		}
+		if start == "" {
+			start = "0"
+		}
+		if end == "" {
+			end = "65535"
+		}
+		if start == end {
+			return start
+		}
+		return start + "-" + end
+	}), ",")
+}
+
+func exportTLSQuery(query url.Values, options *option.OutboundTLSOptions, withSecurity bool) {
+	if options == nil || !options.Enabled {
+		if withSecurity {
+			query.Set("security", "none")
+		}
+		return
+	}
+	query.Set("security", "tls")
+	if options.ServerName != "" {
+		query.Set("sni", options.ServerName)
+	}
+	if len(options.ALPN) > 0 {
+		query.Set("alpn", strings.Join(options.ALPN, ","))
+	}
+	if options.Insecure {
+		query.Set("insecure", "1")
+	}
+	if options.UTLS != nil && options.UTLS.Enabled {
+		query.Set("fp", options.UTLS.Fingerprint)
+	}
+	if options.Reality != nil && options.Reality.Enabled {
+		query.Set("security", "reality")
+		query.Set("pbk", options.Reality.PublicKey)
+		if options.Reality.ShortID != "" {
+			query.Set("sid", options.Reality.ShortID)
+		}
+	}
+}
+
+func exportTransportQuery(query url.Values, options *option.V2RayTransportOptions) bool {
+	if options == nil || options.Type == "" {
+		query.Set("type", "tcp")
+		return true
+	}
+	switch options.Type {
+	case C.V2RayTransportTypeWebsocket:
+		query.Set("type", "ws")
+		if host := websocketHost(options.WebsocketOptions); host != "" {
+			query.Set("host", host)
+		}
+		if path := websocketPath(options.WebsocketOptions); path != "" {
+			query.Set("path", path)
+		}
+	case C.V2RayTransportTypeHTTP:
+		query.Set("type", "http")
+		if len(options.HTTPOptions.Host) > 0 {
+			query.Set("host", strings.Join(options.HTTPOptions.Host, ","))
+		}
+		if options.HTTPOptions.Path != "" {
+			query.Set("path", options.HTTPOptions.Path)
+		}
+	case C.V2RayTransportTypeGRPC:
+		query.Set("type", "grpc")
+		if options.GRPCOptions.ServiceName != "" {
+			query.Set("serviceName", options.GRPCOptions.ServiceName)
+		}
+	case C.V2RayTransportTypeHTTPUpgrade:
+		query.Set("type", "httpupgrade")
+		if options.HTTPUpgradeOptions.Host != "" {
+			query.Set("host", options.HTTPUpgradeOptions.Host)
+		}
+		if options.HTTPUpgradeOptions.Path != "" {
+			query.Set("path", options.HTTPUpgradeOptions.Path)
+		}
+	case C.V2RayTransportTypeSplitHTTP:
+		query.Set("type", "xhttp")
+		if options.SplitHTTPOptions.Host != "" {
+			query.Set("host", options.SplitHTTPOptions.Host)
+		}
+		if options.SplitHTTPOptions.Path != "" {
+			query.Set("path", options.SplitHTTPOptions.Path)
+		}
+	case C.V2RayTransportTypeQUIC:
+		query.Set("type", "quic")
+	default:
+		return false
+	}
+	return true
+}
+
+func websocketHost(options option.V2RayWebsocketOptions) string {
+	if options.Host != "" {
+		return options.Host
+	}
+	for key, values := range options.Headers {
+		if strings.EqualFold(key, "Host") && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func websocketPath(options option.V2RayWebsocketOptions) string {
+	if options.MaxEarlyData > 0 && options.EarlyDataHeaderName == "Sec-WebSocket-Protocol" {
+		return options.Path + "?ed=" + strconv.Itoa(int(options.MaxEarlyData))
+	}
+	return options.Path
+}
+
+func exportClash(outbounds []option.Outbound) ([]byte, error) {
+	proxies := []map[string]any{}
+	for _, outbound := range outbounds {
+		proxy, loaded := exportOutboundClash(outbound)
+		if !loaded {
+			continue
+		}
+		proxies = append(proxies, proxy)
+	}
+	buffer := new(bytes.Buffer)
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	err := encoder.Encode(map[string]any{
+		"proxies": proxies,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func exportOutboundClash(outbound option.Outbound) (map[string]any, bool) {
+	proxy := map[string]any{
+		"name": outbound.Tag,
+	}
+	switch outbound.Type {
+	case C.TypeShadowsocks:
+		options, loaded := exportOptions[option.ShadowsocksOutboundOptions](outbound)
+		if !loaded {
+			return nil, false
+		}
+		proxy["type"] = "ss"
+		proxy["server"] = options.Server
+		proxy["port"] = options.ServerPort
+		proxy["cipher"] = options.Method
+		proxy["password"] = options.Password
+		proxy["udp"] = true
+		if options.UDPOverTCP != nil && options.UDPOverTCP.Enabled {
+			proxy["udp-over-tcp"] = true
+		}
+		if options.Plugin != "" {
+			pluginOptions := make(map[string]any)
+			for _, item := range strings.Split(options.PluginOptions, ";") {
+				key, value, _ := strings.Cut(item, "=")
+				if key == "" {
+					continue
+				}
+				switch key {
+				case "obfs":
+					pluginOptions["mode"] = value
+				case "obfs-host":
+					pluginOptions["host"] = value
+				case "tls":
+					pluginOptions["tls"] = true
+				default:
+					pluginOptions[key] = value
+				}
+			}
+			switch options.Plugin {
+			case "obfs-local":
+				proxy["plugin"] = "obfs"
+			default:
+				proxy["plugin"] = options.Plugin
+			}
+			proxy["plugin-opts"] = pluginOptions
+		}
+	case C.TypeVMess:
+		options, loaded := exportOptions[option.VMessOutboundOptions](outbound)
+		if !loaded {
+			return nil, false
+		}
+		proxy["type"] = "vmess"
+		proxy["server"] = options.Server
+		proxy["port"] = options.ServerPort
+		proxy["uuid"] = options.UUID
+		proxy["alterId"] = options.AlterId
+		proxy["cipher"] = options.Security
+		if options.Security == "" {
+			proxy["cipher"] = "auto"
+		}
+		proxy["udp"] = true
+		if options.PacketEncoding == "xudp" {
+			proxy["xudp"] = true
+		}
+		exportClashTLS(proxy, options.TLS, "servername")
+		if !exportClashTransport(proxy, options.Transport, options.TLS) {
+			return nil, false
+		}
+	case C.TypeVLESS:
+		options, loaded := exportOptions[option.VLESSOutboundOptions](outbound)
+		if !loaded {
+			return nil, false
+		}
+		proxy["type"] = "vless"
+		proxy["server"] = options.Server
+		proxy["port"] = options.ServerPort
+		proxy["uuid"] = options.UUID
+		proxy["udp"] = true
+		if options.Flow != "" {
+			proxy["flow"] = options.Flow
+		}
+		exportClashTLS(proxy, options.TLS, "servername")
+		if !exportClashTransport(proxy, options.Transport, options.TLS) {
+			return nil, false
+		}
+	case C.TypeTrojan:
+		options, loaded := exportOptions[option.TrojanOutboundOptions](outbound)
+		if !loaded {
+			return nil, false
+		}
+		proxy["type"] = "trojan"
+		proxy["server"] = options.Server
+		proxy["port"] = options.ServerPort
+		proxy["password"] = options.Password
+		proxy["udp"] = true
+		exportClashTLS(proxy, options.TLS, "sni")
+		delete(proxy, "tls")
+		if !exportClashTransport(proxy, options.Transport, options.TLS) {
+			return nil, false
+		}
+	case C.TypeHysteria2:
+		options, loaded := exportOptions[option.Hysteria2OutboundOptions](outbound)
+		if !loaded {
+			return nil, false
+		}
+		proxy["type"] = "hysteria2"
+		proxy["server"] = options.Server
+		proxy["port"] = options.ServerPort
+		proxy["password"] = options.Password
+		if len(options.ServerPorts) > 0 {
+			proxy["ports"] = exportServerPorts(options.ServerPorts)
+		}
+		if options.UpMbps > 0 {
+			proxy["up"] = options.UpMbps
+		}
+		if options.DownMbps > 0 {
+			proxy["down"] = options.DownMbps
+		}
+		if options.Obfs != nil && options.Obfs.Type != "" {
+			proxy["obfs"] = options.Obfs.Type
+			proxy["obfs-password"] = options.Obfs.Password
+		}
+		exportClashTLS(proxy, options.TLS, "sni")
+		delete(proxy, "tls")
+	case C.TypeTUIC:
+		options, loaded := exportOptions[option.TUICOutboundOptions](outbound)
+		if !loaded {
+			return nil, false
+		}
+		proxy["type"] = "tuic"
+		proxy["server"] = options.Server
+		proxy["port"] = options.ServerPort
+		proxy["uuid"] = options.UUID
+		proxy["password"] = options.Password
+		if options.CongestionControl != "" {
+			proxy["congestion-controller"] = options.CongestionControl
+		}
+		if options.UDPRelayMode != "" {
+			proxy["udp-relay-mode"] = options.UDPRelayMode
+		}
+		if options.ZeroRTTHandshake {
+			proxy["reduce-rtt"] = true
+		}
+		exportClashTLS(proxy, options.TLS, "sni")
+		delete(proxy, "tls")
+	default:
+		return nil, false
+	}
+	return proxy, true
+}
+
+func exportClashTLS(proxy map[string]any, options *option.OutboundTLSOptions, serverNameKey string) {
+	if options == nil || !options.Enabled {
+		return
+	}
+	proxy["tls"] = true
+	if options.ServerName != "" {
+		proxy[serverNameKey] = options.ServerName
+	}
+	if options.Insecure {
+		proxy["skip-cert-verify"] = true
+	}
+	if len(options.ALPN) > 0 {
+		proxy["alpn"] = []string(options.ALPN)
+	}
+	if options.UTLS != nil && options.UTLS.Enabled {
+		proxy["client-fingerprint"] = options.UTLS.Fingerprint
+	}
+	if options.Reality != nil && options.Reality.Enabled {
+		realityOptions := map[string]any{
+			"public-key": options.Reality.PublicKey,
+		}
+		if options.Reality.ShortID != "" {
+			realityOptions["short-id"] = options.Reality.ShortID
+		}
+		proxy["reality-opts"] = realityOptions
+	}
+}
+
+func exportClashTransport(proxy map[string]any, options *option.V2RayTransportOptions, tlsOptions *option.OutboundTLSOptions) bool {
+	if options == nil || options.Type == "" {
+		return true
+	}
+	switch options.Type {
+	case C.V2RayTransportTypeWebsocket:
+		wsOptions := map[string]any{
+			"path": options.WebsocketOptions.Path,
+		}
+		if host := websocketHost(options.WebsocketOptions); host != "" {
+			wsOptions["headers"] = map[string]any{"Host": host}
+		}
+		if options.WebsocketOptions.MaxEarlyData > 0 {
+			wsOptions["max-early-data"] = options.WebsocketOptions.MaxEarlyData
+			wsOptions["early-data-header-name"] = options.WebsocketOptions.EarlyDataHeaderName
+		}
+		proxy["network"] = "ws"
+		proxy["ws-opts"] = wsOptions
+	case C.V2RayTransportTypeHTTPUpgrade:
+		wsOptions := map[string]any{
+			"path":               options.HTTPUpgradeOptions.Path,
+			"v2ray-http-upgrade": true,
+		}
+		if options.HTTPUpgradeOptions.Host != "" {
+			wsOptions["headers"] = map[string]any{"Host": options.HTTPUpgradeOptions.Host}
+		}
+		proxy["network"] = "ws"
+		proxy["ws-opts"] = wsOptions
+	case C.V2RayTransportTypeHTTP:
+		if tlsOptions != nil && tlsOptions.Enabled {
+			h2Options := map[string]any{
+				"path": options.HTTPOptions.Path,
+			}
+			if len(options.HTTPOptions.Host) > 0 {
+				h2Options["host"] = []string(options.HTTPOptions.Host)
+			}
+			proxy["network"] = "h2"
+			proxy["h2-opts"] = h2Options
+		} else {
+			httpOptions := map[string]any{
+				"path": []string{options.HTTPOptions.Path},
+			}
+			if options.HTTPOptions.Method != "" {
+				httpOptions["method"] = options.HTTPOptions.Method
+			}
+			if len(options.HTTPOptions.Host) > 0 {
+				httpOptions["headers"] = map[string]any{"Host": []string(options.HTTPOptions.Host)}
+			}
+			proxy["network"] = "http"
+			proxy["http-opts"] = httpOptions
+		}
+	case C.V2RayTransportTypeGRPC:
+		proxy["network"] = "grpc"
+		proxy["grpc-opts"] = map[string]any{
+			"grpc-service-name": options.GRPCOptions.ServiceName,
+		}
+	case C.V2RayTransportTypeSplitHTTP:
+		xhttpOptions := map[string]any{
+			"path": options.SplitHTTPOptions.Path,
+		}
+		if options.SplitHTTPOptions.Host != "" {
+			xhttpOptions["host"] = options.SplitHTTPOptions.Host
+		}
+		proxy["network"] = "xhttp"
+		proxy["xhttp-opts"] = xhttpOptions
+	default:
+		return false
+	}
+	return true
+}
+
+func exportSingBox(ctx context.Context, outbounds []option.Outbound) ([]byte, error) {
+	if outbounds == nil {
+		outbounds = []option.Outbound{}
+	}
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoderContext(ctx, buffer)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(&option.OutboundProviderOptions{
+		Outbounds: outbounds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
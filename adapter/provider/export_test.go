@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"testing"
+
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/option"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testHysteria2Outbound() option.Outbound {
+	return option.Outbound{
+		Type: C.TypeHysteria2,
+		Tag:  "hy2 node",
+		Options: &option.Hysteria2OutboundOptions{
+			ServerOptions: option.ServerOptions{
+				Server:     "example.org",
+				ServerPort: 443,
+			},
+			ServerPorts: []string{"20000:30000", "40000:40000"},
+			UpMbps:      100,
+			DownMbps:    200,
+			Obfs: &option.Hysteria2Obfs{
+				Type:     "salamander",
+				Password: "obfs password",
+			},
+			Password: "password",
+			OutboundTLSOptionsContainer: option.OutboundTLSOptionsContainer{
+				TLS: &option.OutboundTLSOptions{
+					Enabled:    true,
+					ServerName: "sni.example.org",
+					Insecure:   true,
+				},
+			},
+		},
+	}
+}
+
+func requireHysteria2RoundTrip(t *testing.T, outbounds []option.Outbound) {
+	require.Len(t, outbounds, 1)
+	expected := testHysteria2Outbound()
+	expectedOptions := expected.Options.(*option.Hysteria2OutboundOptions)
+	require.Equal(t, expected.Type, outbounds[0].Type)
+	require.Equal(t, expected.Tag, outbounds[0].Tag)
+	options := outbounds[0].Options.(option.Hysteria2OutboundOptions)
+	require.Equal(t, expectedOptions.ServerOptions, options.ServerOptions)
+	require.Equal(t, expectedOptions.ServerPorts, options.ServerPorts)
+	require.Equal(t, expectedOptions.UpMbps, options.UpMbps)
+	require.Equal(t, expectedOptions.DownMbps, options.DownMbps)
+	require.Equal(t, expectedOptions.Obfs, options.Obfs)
+	require.Equal(t, expectedOptions.Password, options.Password)
+	require.Equal(t, expectedOptions.TLS.ServerName, options.TLS.ServerName)
+	require.Equal(t, expectedOptions.TLS.Insecure, options.TLS.Insecure)
+}
+
+func TestExportURIRoundTrip(t *testing.T) {
+	t.Parallel()
+	content := exportURI([]option.Outbound{testHysteria2Outbound()})
+	require.Contains(t, string(content), "mport=20000-30000%2C40000")
+	outbounds, err := newNativeURIParser(string(content))
+	require.NoError(t, err)
+	requireHysteria2RoundTrip(t, outbounds)
+}
+
+func TestExportClashRoundTrip(t *testing.T) {
+	t.Parallel()
+	content, err := exportClash([]option.Outbound{testHysteria2Outbound()})
+	require.NoError(t, err)
+	require.Contains(t, string(content), "ports: 20000-30000,40000")
+	outbounds, err := newClashParser(string(content))
+	require.NoError(t, err)
+	requireHysteria2RoundTrip(t, outbounds)
+}
+
+func TestExportServerPorts(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "1000-2000,3000,0-10,60000-65535", exportServerPorts([]string{"1000:2000", "3000:3000", ":10", "60000:"}))
+	require.Equal(t, []string{"1000:2000", "3000:3000"}, parseServerPorts("1000-2000, 3000"))
+}
@@ -39,6 +39,11 @@ func NewLocalProvider(ctx context.Context, manager *Manager, router adapter.Rout
 	if interval == 0 {
 		interval = C.DefaultURLTestInterval
 	}
+	if localOptions.Format != "" {
+		if _, loaded := loadParser(localOptions.Format); !loaded {
+			return nil, E.New("unknown format: ", localOptions.Format)
+		}
+	}
 	ctx, cancel := context.WithCancel(ctx)
 	provider := &LocalProvider{
 		myProviderAdapter: myProviderAdapter{
@@ -56,6 +61,7 @@ func NewLocalProvider(ctx context.Context, manager *Manager, router adapter.Rout
 			types:               options.Types,
 			ports:               make(map[int]bool),
 			providerType:        C.ProviderTypeLocal,
+			format:              localOptions.Format,
 			close:               make(chan struct{}),
 			pauseManager:        service.FromContext[pause.Manager](ctx),
 			subInfo:             SubInfo{},
@@ -94,6 +100,8 @@ func (p *LocalProvider) Start() error {
 	} else {
 		p.healchcheckHistory = urltest.NewHistoryStorage()
 	}
+	p.cacheFile = service.FromContext[adapter.CacheFile](p.ctx)
+	p.loadHistory()
 	return nil
 }
 
@@ -162,13 +162,7 @@ func (m *Manager) Create(ctx context.Context, router adapter.Router, tag string,
 	if options.Path == "" {
 		return E.New("provider path missing")
 	}
-	path, _ := C.FindPath(options.Path)
-	if foundPath, loaded := C.FindPath(path); loaded {
-		path = foundPath
-	}
-	if !rw.FileExists(path) {
-		path = filemanager.BasePath(ctx, path)
-	}
+	path := providerPath(ctx, options.Path)
 	if stat, err := os.Stat(path); err == nil {
 		if stat.IsDir() {
 			return E.New("provider path is a directory: ", path)
@@ -256,3 +250,14 @@ func (m *Manager) OutboundProvider(tag string) (adapter.OutboundProvider, bool)
 	provider, found := m.outboundProviderByTag[tag]
 	return provider, found
 }
+
+func providerPath(ctx context.Context, path string) string {
+	path, _ = C.FindPath(path)
+	if foundPath, loaded := C.FindPath(path); loaded {
+		path = foundPath
+	}
+	if !rw.FileExists(path) {
+		path = filemanager.BasePath(ctx, path)
+	}
+	return path
+}
@@ -81,6 +81,23 @@ func stringToUint32(content string) uint32 {
 	return uint32(intNum)
 }
 
+// parseServerPorts converts port ranges from the start-end form of share links and Clash to the start:end form of sing-box.
+func parseServerPorts(content string) []string {
+	var serverPorts []string
+	for _, portRange := range strings.Split(content, ",") {
+		portRange = strings.TrimSpace(portRange)
+		if portRange == "" {
+			continue
+		}
+		start, end, isRange := strings.Cut(portRange, "-")
+		if !isRange {
+			end = start
+		}
+		serverPorts = append(serverPorts, start+":"+end)
+	}
+	return serverPorts
+}
+
 func decodeURIComponent(content string) string {
 	result, _ := url.QueryUnescape(content)
 	return result
@@ -388,6 +405,14 @@ func newVMessNativeParser(content string) (option.Outbound, error) {
 				if host, exists := proxy["host"]; exists && host != "" {
 					Transport.GRPCOptions.ServiceName = host
 				}
+			case "xhttp", "splithttp":
+				Transport.Type = C.V2RayTransportTypeSplitHTTP
+				if host, exists := proxy["host"]; exists && host != "" {
+					Transport.SplitHTTPOptions.Host = host
+				}
+				if path, exists := proxy["path"]; exists && path != "" {
+					Transport.SplitHTTPOptions.Path = path
+				}
 			}
 			options.Transport = &Transport
 		case "tfo", "tcp-fast-open", "tcp_fast_open":
@@ -483,6 +508,14 @@ func newVLESSNativeParser(content string) (option.Outbound, error) {
 				if serviceName, exists := proxy["serviceName"]; exists && serviceName != "" {
 					Transport.GRPCOptions.ServiceName = serviceName
 				}
+			case "xhttp", "splithttp":
+				Transport.Type = C.V2RayTransportTypeSplitHTTP
+				if host, exists := proxy["host"]; exists && host != "" {
+					Transport.SplitHTTPOptions.Host = host
+				}
+				if path, exists := proxy["path"]; exists && path != "" {
+					Transport.SplitHTTPOptions.Path = path
+				}
 			}
 			options.Transport = &Transport
 		case "security":
@@ -601,6 +634,14 @@ func newTrojanNativeParser(content string) (option.Outbound, error) {
 				if serviceName, exists := proxy["grpc-service-name"]; exists && serviceName != "" {
 					Transport.GRPCOptions.ServiceName = serviceName
 				}
+			case "xhttp", "splithttp":
+				Transport.Type = C.V2RayTransportTypeSplitHTTP
+				if host, exists := proxy["host"]; exists && host != "" {
+					Transport.SplitHTTPOptions.Host = host
+				}
+				if path, exists := proxy["path"]; exists && path != "" {
+					Transport.SplitHTTPOptions.Path = path
+				}
 			}
 			options.Transport = &Transport
 		case "tfo", "tcp-fast-open", "tcp_fast_open":
@@ -679,7 +720,7 @@ func newHysteria2NativeParser(content string) (option.Outbound, error) {
 	if len(result) == 0 {
 		return outbound, E.New("invalid hysteria2 uri")
 	}
-	outbound.Tag = result[5]
+	outbound.Tag = decodeURIComponent(result[5])
 	options := option.Hysteria2OutboundOptions{}
 	TLSOptions := option.OutboundTLSOptions{
 		Enabled: true,
@@ -690,32 +731,43 @@ func newHysteria2NativeParser(content string) (option.Outbound, error) {
 	options.ServerPort = uint16(443)
 	options.Server = result[2]
 	TLSOptions.ServerName = result[2]
-	options.Password = result[1]
+	options.Password = decodeURIComponent(result[1])
 	if strings.Contains(result[1], ":") {
-		options.Password = strings.Split(result[1], ":")[1]
+		options.Password = decodeURIComponent(strings.Split(result[1], ":")[1])
 	}
 	if result[3] != "" {
 		options.ServerPort = stringToUint16(result[3])
 	}
-	for _, addon := range strings.Split(result[4], "\n") {
+	obfsOptions := option.Hysteria2Obfs{}
+	for _, addon := range strings.Split(result[4], "&") {
 		key, value := splitKeyValueWithEqual(addon)
+		value = decodeURIComponent(value)
 		switch key {
 		case "up":
 			options.UpMbps, _ = strconv.Atoi(value)
 		case "down":
 			options.DownMbps, _ = strconv.Atoi(value)
+		case "mport":
+			options.ServerPorts = parseServerPorts(value)
 		case "obfs":
 			if value == "salamander" {
-				options.Obfs.Type = "salamander"
+				obfsOptions.Type = "salamander"
 			}
 		case "obfs-password":
-			options.Obfs.Password = value
+			obfsOptions.Password = value
+		case "sni", "peer":
+			TLSOptions.ServerName = value
+		case "alpn":
+			TLSOptions.ALPN = strings.Split(value, ",")
 		case "insecure", "skip-cert-verify":
 			if value == "1" || value == "true" {
 				TLSOptions.Insecure = true
 			}
 		}
 	}
+	if obfsOptions.Type != "" {
+		options.Obfs = &obfsOptions
+	}
 	options.TLS = &TLSOptions
 	outbound.Options = options
 	return outbound, nil
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"encoding/json"
 	"reflect"
 	"strings"
 
@@ -8,33 +9,133 @@ import (
 	"github.com/sagernet/sing-box/option"
 	"github.com/sagernet/sing/common"
 	E "github.com/sagernet/sing/common/exceptions"
+
+	"gopkg.in/yaml.v3"
 )
 
-func (p *myProviderAdapter) newParser(content string) ([]option.Outbound, error) {
-	var outbounds []option.Outbound
-	var err error
-	switch true {
-	case strings.Contains(content, "\"outbounds\""):
-		var options option.OutboundProviderOptions
-		err = options.UnmarshalJSON([]byte(content))
-		if err != nil {
-			return nil, E.Cause(err, "decode config at ")
+type ParseFunc func(content string) ([]option.Outbound, error)
+
+type parser struct {
+	name    string
+	detect  func(content string) bool
+	parse   ParseFunc
+	subInfo func(content string) (SubInfo, bool)
+}
+
+var parsers []parser
+
+func init() {
+	RegisterParser(C.ProviderFormatSingBox, detectSingBoxContent, newSingBoxParser)
+	registerParser(parser{C.ProviderFormatSIP008, detectSIP008Content, newSIP008Parser, parseSIP008SubInfo})
+	RegisterParser(C.ProviderFormatClash, detectClashContent, newClashParser)
+	RegisterParser(C.ProviderFormatURI, detectNativeURIContent, newNativeURIParser)
+}
+
+// RegisterParser registers a subscription format.
+// Formats are detected in registration order when the provider has no explicit format.
+func RegisterParser(name string, detect func(content string) bool, parse ParseFunc) {
+	registerParser(parser{name: name, detect: detect, parse: parse})
+}
+
+func registerParser(contentParser parser) {
+	for i, it := range parsers {
+		if it.name == contentParser.name {
+			parsers[i] = contentParser
+			return
 		}
-		outbounds = options.Outbounds
-	case strings.Contains(content, "proxies"):
-		outbounds, err = newClashParser(content)
-		if err != nil {
-			return nil, err
+	}
+	parsers = append(parsers, contentParser)
+}
+
+func loadParser(name string) (parser, bool) {
+	for _, it := range parsers {
+		if it.name == name {
+			return it, true
+		}
+	}
+	return parser{}, false
+}
+
+func detectParser(content string) (parser, bool) {
+	for _, it := range parsers {
+		if it.detect(content) {
+			return it, true
 		}
-	default:
-		outbounds, err = newNativeURIParser(content)
-		if err != nil {
-			return nil, err
+	}
+	return parser{}, false
+}
+
+func (p *myProviderAdapter) contentParser(content string) (parser, error) {
+	if p.format != "" {
+		contentParser, loaded := loadParser(p.format)
+		if !loaded {
+			return parser{}, E.New("unknown provider format: ", p.format)
 		}
+		return contentParser, nil
+	}
+	contentParser, loaded := detectParser(content)
+	if !loaded {
+		return parser{}, E.New("unknown provider content")
+	}
+	return contentParser, nil
+}
+
+// parseContentSubInfo reads subscription info carried in the content itself,
+// for formats that have no subscription-userinfo header.
+func (p *myProviderAdapter) parseContentSubInfo(content string) (SubInfo, bool) {
+	contentParser, err := p.contentParser(content)
+	if err != nil || contentParser.subInfo == nil {
+		return SubInfo{}, false
+	}
+	return contentParser.subInfo(content)
+}
+
+func (p *myProviderAdapter) newParser(content string) ([]option.Outbound, error) {
+	contentParser, err := p.contentParser(content)
+	if err != nil {
+		return nil, err
+	}
+	outbounds, err := contentParser.parse(content)
+	if err != nil {
+		return nil, E.Cause(err, "parse ", contentParser.name, " content")
 	}
 	return p.overrideOutbounds(outbounds), nil
 }
 
+func detectSingBoxContent(content string) bool {
+	if !strings.HasPrefix(strings.TrimSpace(content), "{") {
+		return false
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal([]byte(content), &object) != nil {
+		return false
+	}
+	_, loaded := object["outbounds"]
+	return loaded
+}
+
+func newSingBoxParser(content string) ([]option.Outbound, error) {
+	var options option.OutboundProviderOptions
+	err := options.UnmarshalJSON([]byte(content))
+	if err != nil {
+		return nil, E.Cause(err, "decode config")
+	}
+	return options.Outbounds, nil
+}
+
+func detectClashContent(content string) bool {
+	var object map[string]any
+	if yaml.Unmarshal([]byte(content), &object) != nil {
+		return false
+	}
+	_, isList := object["proxies"].([]any)
+	return isList
+}
+
+func detectNativeURIContent(content string) bool {
+	return strings.Contains(content, "://")
+}
+
 func (p *myProviderAdapter) overrideOutbounds(outbounds []option.Outbound) []option.Outbound {
 	var tags []string
 	for _, outbound := range outbounds {
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/common/signature"
 	"github.com/sagernet/sing-box/common/urltest"
 	C "github.com/sagernet/sing-box/constant"
 	"github.com/sagernet/sing-box/log"
@@ -39,6 +40,7 @@ type RemoteProvider struct {
 	lastEtag string
 	detour   string
 	dialer   N.Dialer
+	verifier *signature.Verifier
 
 	updateTicker     *time.Ticker
 	firstStartCancel context.CancelFunc
@@ -64,6 +66,18 @@ func NewRemoteProvider(ctx context.Context, manager *Manager, router adapter.Rou
 	default:
 		return nil, E.New("invalid url scheme")
 	}
+	if remoteOptions.Format != "" {
+		if _, loaded := loadParser(remoteOptions.Format); !loaded {
+			return nil, E.New("unknown format: ", remoteOptions.Format)
+		}
+	}
+	var verifier *signature.Verifier
+	if remoteOptions.Verify != nil {
+		verifier, err = signature.NewVerifier(*remoteOptions.Verify)
+		if err != nil {
+			return nil, E.Cause(err, "parse verify options")
+		}
+	}
 	if ua == "" {
 		ua = "sing-box " + C.Version + "; PuerNya fork"
 	}
@@ -92,6 +106,7 @@ func NewRemoteProvider(ctx context.Context, manager *Manager, router adapter.Rou
 			types:               options.Types,
 			ports:               make(map[int]bool),
 			providerType:        C.ProviderTypeRemote,
+			format:              remoteOptions.Format,
 			outboundOverride:    options.OutboundOverride,
 			close:               make(chan struct{}),
 			pauseManager:        service.FromContext[pause.Manager](ctx),
@@ -103,6 +118,7 @@ func NewRemoteProvider(ctx context.Context, manager *Manager, router adapter.Rou
 		ua:       ua,
 		interval: downloadInterval,
 		detour:   remoteOptions.Detour,
+		verifier: verifier,
 	}
 	if len(options.Includes) > 0 {
 		includes := make([]*R.Regexp, 0, len(options.Includes))
@@ -153,9 +169,56 @@ func (p *RemoteProvider) Start() error {
 	} else {
 		p.healchcheckHistory = urltest.NewHistoryStorage()
 	}
+	p.cacheFile = service.FromContext[adapter.CacheFile](p.ctx)
+	if p.cacheFile != nil {
+		if savedProvider := p.cacheFile.LoadProvider(p.tag); savedProvider != nil {
+			p.loadSavedProvider(savedProvider)
+		}
+	}
+	p.loadHistory()
 	return nil
 }
 
+// loadSavedProvider restores the last successful subscription from the cache file,
+// which takes precedence over the content of path.
+func (p *RemoteProvider) loadSavedProvider(savedProvider *adapter.SavedProvider) {
+	outbounds, err := p.parseOutbounds(p.ctx, p.router, string(savedProvider.Content))
+	if err != nil {
+		p.logger.Warn("restore cached outbound provider ", p.tag, ": ", err)
+		return
+	}
+	if outbounds != nil {
+		p.outbounds = outbounds
+		p.UpdateOutboundByTag()
+	}
+	p.lastUpdated = savedProvider.LastUpdated
+	p.lastEtag = savedProvider.LastEtag
+	p.subInfo = SubInfo{
+		upload:   savedProvider.Upload,
+		download: savedProvider.Download,
+		total:    savedProvider.Total,
+		expire:   savedProvider.Expire,
+	}
+}
+
+func (p *RemoteProvider) saveProvider(ctx context.Context, content []byte) {
+	if p.cacheFile == nil {
+		return
+	}
+	err := p.cacheFile.SaveProvider(p.tag, &adapter.SavedProvider{
+		Content:     content,
+		LastUpdated: p.lastUpdated,
+		LastEtag:    p.lastEtag,
+		Upload:      p.subInfo.upload,
+		Download:    p.subInfo.download,
+		Total:       p.subInfo.total,
+		Expire:      p.subInfo.expire,
+	})
+	if err != nil {
+		p.logger.ErrorContext(ctx, "save outbound provider ", p.tag, " to cache file: ", err)
+	}
+}
+
 func (p *RemoteProvider) loopUpdateCheck() {
 	p.updateTicker = time.NewTicker(p.interval)
 	func() {
@@ -239,6 +302,11 @@ func (p *RemoteProvider) fetchOnce(ctx context.Context, router adapter.Router) e
 	case http.StatusNotModified:
 		p.logger.InfoContext(ctx, "update outbound provider ", p.tag, ": not modified")
 		p.updateCacheFileModTime(subInfo)
+		if p.cacheFile != nil {
+			if savedProvider := p.cacheFile.LoadProvider(p.tag); savedProvider != nil {
+				p.saveProvider(ctx, savedProvider.Content)
+			}
+		}
 		return nil
 	default:
 		return E.New("unexpected status: ", response.Status)
@@ -253,10 +321,11 @@ func (p *RemoteProvider) fetchOnce(ctx context.Context, router adapter.Router) e
 	if len(contentRaw) == 0 {
 		return E.New("empty response")
 	}
-
-	eTagHeader := response.Header.Get("Etag")
-	if eTagHeader != "" {
-		p.lastEtag = eTagHeader
+	if p.verifier != nil {
+		err = p.verifier.Verify(ctx, httpClient, request, response, contentRaw)
+		if err != nil {
+			return E.Cause(err, "verify provider content")
+		}
 	}
 
 	content := decodeBase64Safe(string(contentRaw))
@@ -270,13 +339,20 @@ func (p *RemoteProvider) fetchOnce(ctx context.Context, router adapter.Router) e
 			hasSubInfo = true
 		}
 	}
+	if !hasSubInfo {
+		info, hasSubInfo = p.parseContentSubInfo(content)
+	}
 
 	_, err = p.updateProviderFromContent(ctx, router, content)
 	if err != nil {
 		return err
 	}
 
+	if eTagHeader := response.Header.Get("Etag"); eTagHeader != "" {
+		p.lastEtag = eTagHeader
+	}
 	p.subInfo = info
+	p.saveProvider(ctx, []byte(content))
 	p.logger.InfoContext(ctx, "update outbound provider ", p.tag, " success")
 
 	if hasSubInfo {
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/option"
+	E "github.com/sagernet/sing/common/exceptions"
+)
+
+// SIP008Config is the online configuration format defined in
+// https://shadowsocks.org/doc/sip008.html
+type SIP008Config struct {
+	Version        int            `json:"version"`
+	Servers        []SIP008Server `json:"servers"`
+	BytesUsed      *int64         `json:"bytes_used,omitempty"`
+	BytesRemaining *int64         `json:"bytes_remaining,omitempty"`
+}
+
+type SIP008Server struct {
+	ID         string `json:"id,omitempty"`
+	Remarks    string `json:"remarks,omitempty"`
+	Server     string `json:"server"`
+	ServerPort uint16 `json:"server_port"`
+	Password   string `json:"password"`
+	Method     string `json:"method"`
+	Plugin     string `json:"plugin,omitempty"`
+	PluginOpts string `json:"plugin_opts,omitempty"`
+}
+
+func detectSIP008Content(content string) bool {
+	if !strings.HasPrefix(strings.TrimSpace(content), "{") {
+		return false
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal([]byte(content), &object) != nil {
+		return false
+	}
+	_, hasVersion := object["version"]
+	_, hasServers := object["servers"]
+	return hasVersion && hasServers
+}
+
+func newSIP008Parser(content string) ([]option.Outbound, error) {
+	var config SIP008Config
+	err := json.Unmarshal([]byte(content), &config)
+	if err != nil {
+		return nil, err
+	}
+	if config.Version != 1 {
+		return nil, E.New("unsupported SIP008 version: ", config.Version)
+	}
+	var outbounds []option.Outbound
+	for _, server := range config.Servers {
+		if server.Server == "" || server.ServerPort == 0 || server.Method == "" {
+			continue
+		}
+		outbound := option.Outbound{
+			Type: C.TypeShadowsocks,
+			Tag:  server.Remarks,
+		}
+		if outbound.Tag == "" {
+			outbound.Tag = net.JoinHostPort(server.Server, strconv.Itoa(int(server.ServerPort)))
+		}
+		options := option.ShadowsocksOutboundOptions{
+			Method:   server.Method,
+			Password: server.Password,
+		}
+		options.Server = server.Server
+		options.ServerPort = server.ServerPort
+		switch server.Plugin {
+		case "":
+		case "simple-obfs", "obfs-local":
+			options.Plugin = "obfs-local"
+			options.PluginOptions = server.PluginOpts
+		default:
+			options.Plugin = server.Plugin
+			options.PluginOptions = server.PluginOpts
+		}
+		outbound.Options = options
+		outbounds = append(outbounds, outbound)
+	}
+	return outbounds, nil
+}
+
+func parseSIP008SubInfo(content string) (SubInfo, bool) {
+	var config SIP008Config
+	if json.Unmarshal([]byte(content), &config) != nil {
+		return SubInfo{}, false
+	}
+	if config.BytesUsed == nil && config.BytesRemaining == nil {
+		return SubInfo{}, false
+	}
+	var info SubInfo
+	if config.BytesUsed != nil {
+		info.download = *config.BytesUsed
+		info.total = *config.BytesUsed
+	}
+	if config.BytesRemaining != nil {
+		info.total += *config.BytesRemaining
+	}
+	return info, true
+}
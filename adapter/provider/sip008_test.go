@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"testing"
+
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/option"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSIP008Content = `{
+  "version": 1,
+  "servers": [
+    {
+      "id": "27b8a625-4f4b-4428-9f0f-8a2317db7c79",
+      "remarks": "Name of the server",
+      "server": "example.com",
+      "server_port": 8388,
+      "password": "example",
+      "method": "chacha20-ietf-poly1305",
+      "plugin": "xxx",
+      "plugin_opts": "xxxxx"
+    },
+    {
+      "id": "7842c068-c667-41f2-8f7d-04feece3cb67",
+      "remarks": "Name of the server",
+      "server": "example.com",
+      "server_port": 8388,
+      "password": "example",
+      "method": "chacha20-ietf-poly1305",
+      "plugin": "simple-obfs",
+      "plugin_opts": "obfs=http;obfs-host=example.org"
+    },
+    {
+      "server": "2001:db8::1",
+      "server_port": 8389,
+      "password": "example",
+      "method": "2022-blake3-aes-128-gcm"
+    },
+    {
+      "remarks": "Missing method",
+      "server": "example.com",
+      "server_port": 8390,
+      "password": "example"
+    }
+  ],
+  "bytes_used": 274877906944,
+  "bytes_remaining": 824633720832
+}`
+
+func TestSIP008(t *testing.T) {
+	t.Parallel()
+	provider := &myProviderAdapter{}
+	outbounds, err := provider.newParser(decodeBase64Safe(testSIP008Content))
+	require.NoError(t, err)
+	require.Len(t, outbounds, 3)
+	for _, outbound := range outbounds {
+		require.Equal(t, C.TypeShadowsocks, outbound.Type)
+	}
+
+	require.Equal(t, "Name of the server", outbounds[0].Tag)
+	options := outbounds[0].Options.(*option.ShadowsocksOutboundOptions)
+	require.Equal(t, "example.com", options.Server)
+	require.Equal(t, uint16(8388), options.ServerPort)
+	require.Equal(t, "example", options.Password)
+	require.Equal(t, "chacha20-ietf-poly1305", options.Method)
+	require.Equal(t, "xxx", options.Plugin)
+	require.Equal(t, "xxxxx", options.PluginOptions)
+
+	options = outbounds[1].Options.(*option.ShadowsocksOutboundOptions)
+	require.Equal(t, "obfs-local", options.Plugin)
+	require.Equal(t, "obfs=http;obfs-host=example.org", options.PluginOptions)
+
+	require.Equal(t, "[2001:db8::1]:8389", outbounds[2].Tag)
+	options = outbounds[2].Options.(*option.ShadowsocksOutboundOptions)
+	require.Equal(t, "2001:db8::1", options.Server)
+	require.Empty(t, options.Plugin)
+
+	info, loaded := provider.parseContentSubInfo(testSIP008Content)
+	require.True(t, loaded)
+	require.Equal(t, SubInfo{download: 274877906944, total: 274877906944 + 824633720832}, info)
+}
+
+func TestSIP008Detect(t *testing.T) {
+	t.Parallel()
+	// SIP008 content is also a JSON object, so it must not be taken for sing-box content
+	contentParser, loaded := detectParser(testSIP008Content)
+	require.True(t, loaded)
+	require.Equal(t, C.ProviderFormatSIP008, contentParser.name)
+	require.False(t, detectSIP008Content(testSingBoxContent))
+	require.False(t, detectSIP008Content(testClashContent))
+}
+
+func TestSIP008Invalid(t *testing.T) {
+	t.Parallel()
+	provider := &myProviderAdapter{format: C.ProviderFormatSIP008}
+	_, err := provider.newParser(`{"version": 2, "servers": []}`)
+	require.ErrorContains(t, err, "unsupported SIP008 version")
+	_, err = provider.newParser(`{"version": 1, "servers": {}}`)
+	require.Error(t, err)
+
+	_, loaded := provider.parseContentSubInfo(`{"version": 1, "servers": []}`)
+	require.False(t, loaded)
+}
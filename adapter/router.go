@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	C "github.com/sagernet/sing-box/constant"
 	M "github.com/sagernet/sing/common/metadata"
@@ -24,9 +25,10 @@ type Router interface {
 	PreMatch(metadata InboundContext) error
 	ConnectionRouterEx
 	RuleSet(tag string) (RuleSet, bool)
+	RuleSets() []RuleSet
 	NeedWIFIState() bool
 	Rules() []Rule
-	SetTracker(tracker ConnectionTracker)
+	AppendTracker(tracker ConnectionTracker)
 	ResetNetwork()
 }
 
@@ -49,6 +51,10 @@ type ConnectionRouterEx interface {
 
 type RuleSet interface {
 	Name() string
+	Type() string
+	Format() string
+	RuleCount() int
+	UpdateTime() time.Time
 	StartContext(ctx context.Context, startContext *HTTPStartContext) error
 	PostStart() error
 	Metadata() RuleSetMetadata
@@ -62,6 +68,11 @@ type RuleSet interface {
 	HeadlessRule
 }
 
+type RemoteRuleSet interface {
+	RuleSet
+	Update(ctx context.Context) error
+}
+
 type RuleSetUpdateCallback func(it RuleSet)
 
 type RuleSetMetadata struct {
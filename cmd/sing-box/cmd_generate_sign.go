@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+
+	"github.com/sagernet/sing-box/common/signature"
+	"github.com/sagernet/sing-box/log"
+
+	"github.com/spf13/cobra"
+)
+
+var commandGenerateSignKeyPair = &cobra.Command{
+	Use:   "sign-keypair",
+	Short: "Generate ed25519 key pair for signing rule-sets and provider content",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := generateSignKeyPair()
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	commandGenerate.AddCommand(commandGenerateSignKeyPair)
+}
+
+func generateSignKeyPair() error {
+	privateKey, err := signature.GenerateKey()
+	if err != nil {
+		return err
+	}
+	os.Stdout.WriteString("PrivateKey: " + privateKey.String() + "\n")
+	os.Stdout.WriteString("PublicKey: " + privateKey.Public().String() + "\n")
+	return nil
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var commandProvider = &cobra.Command{
+	Use:   "provider",
+	Short: "Manage outbound providers",
+}
+
+func init() {
+	mainCommand.AddCommand(commandProvider)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+
+	"github.com/sagernet/sing-box/adapter/provider"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/log"
+	E "github.com/sagernet/sing/common/exceptions"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	commandProviderExportFlagFormat string
+	commandProviderExportFlagOutput string
+)
+
+var commandProviderExport = &cobra.Command{
+	Use:   "export <tag>",
+	Short: "Export outbounds of a provider as uri, clash or singbox subscription",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := exportProvider(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	commandProviderExport.Flags().StringVar(&commandProviderExportFlagFormat, "format", C.ProviderFormatURI, "Export format: uri, clash or singbox")
+	commandProviderExport.Flags().StringVarP(&commandProviderExportFlagOutput, "output", "o", "stdout", "Output path")
+	commandProvider.AddCommand(commandProviderExport)
+}
+
+func exportProvider(tag string) error {
+	options, err := readConfigAndMerge()
+	if err != nil {
+		return err
+	}
+	for _, providerOptions := range options.OutboundProviders {
+		if providerOptions.Tag != tag {
+			continue
+		}
+		outbounds, err := provider.ReadOutbounds(globalCtx, providerOptions)
+		if err != nil {
+			return E.Cause(err, "read provider[", tag, "]")
+		}
+		content, err := provider.Export(globalCtx, outbounds, commandProviderExportFlagFormat)
+		if err != nil {
+			return err
+		}
+		if commandProviderExportFlagOutput == "stdout" {
+			_, err = os.Stdout.Write(content)
+			return err
+		}
+		return os.WriteFile(commandProviderExportFlagOutput, content, 0o644)
+	}
+	return E.New("provider not found: ", tag)
+}
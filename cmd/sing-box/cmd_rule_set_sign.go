@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/sagernet/sing-box/common/signature"
+	"github.com/sagernet/sing-box/log"
+	E "github.com/sagernet/sing/common/exceptions"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagRuleSetSignPrivateKey string
+	flagRuleSetSignOutput     string
+)
+
+const flagRuleSetSignDefaultOutput = "<file_name>.sig"
+
+var commandRuleSetSign = &cobra.Command{
+	Use:   "sign <file>",
+	Short: "Sign rule-set or provider content",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := signRuleSet(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	commandRuleSetSign.Flags().StringVarP(&flagRuleSetSignPrivateKey, "private-key", "k", "", "Private key, or path to the private key file")
+	commandRuleSetSign.Flags().StringVarP(&flagRuleSetSignOutput, "output", "o", flagRuleSetSignDefaultOutput, "Output file")
+	commandRuleSet.AddCommand(commandRuleSetSign)
+}
+
+func signRuleSet(sourcePath string) error {
+	if flagRuleSetSignPrivateKey == "" {
+		return E.New("missing private key")
+	}
+	keyContent := flagRuleSetSignPrivateKey
+	if keyFile, err := os.ReadFile(flagRuleSetSignPrivateKey); err == nil {
+		keyContent = string(keyFile)
+	}
+	privateKey, err := signature.ParsePrivateKey(keyContent)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+	comment := "timestamp:" + strconv.FormatInt(time.Now().Unix(), 10) + "\tfile:" + filepath.Base(sourcePath)
+	signatureContent := privateKey.Sign(content, comment)
+	var outputPath string
+	if flagRuleSetSignOutput == flagRuleSetSignDefaultOutput {
+		outputPath = sourcePath + ".sig"
+	} else {
+		outputPath = flagRuleSetSignOutput
+	}
+	if outputPath == "stdout" {
+		_, err = os.Stdout.Write(signatureContent)
+		return err
+	}
+	return os.WriteFile(outputPath, signatureContent, 0o644)
+}
@@ -0,0 +1,48 @@
+package inbounduser
+
+import (
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing/common"
+)
+
+func LimitOptions(user adapter.InboundUser) option.InboundUserLimitOptions {
+	return option.InboundUserLimitOptions{
+		TrafficLimit: option.MemoryBytes(user.TrafficLimit),
+		ExpireAt:     user.ExpireAt,
+		SpeedLimit:   option.MemoryBytes(user.SpeedLimit),
+	}
+}
+
+func SetLimitOptions(user *adapter.InboundUser, options option.InboundUserLimitOptions) {
+	user.TrafficLimit = uint64(options.TrafficLimit)
+	user.ExpireAt = options.ExpireAt
+	user.SpeedLimit = uint64(options.SpeedLimit)
+}
+
+// ContainsLimit reports whether any configured inbound user has a traffic limit, expiry or speed limit.
+func ContainsLimit(inbounds []option.Inbound) bool {
+	for _, inbound := range inbounds {
+		var limits []option.InboundUserLimitOptions
+		switch options := inbound.Options.(type) {
+		case *option.VLESSInboundOptions:
+			limits = common.Map(options.Users, func(it option.VLESSUser) option.InboundUserLimitOptions { return it.InboundUserLimitOptions })
+		case *option.VMessInboundOptions:
+			limits = common.Map(options.Users, func(it option.VMessUser) option.InboundUserLimitOptions { return it.InboundUserLimitOptions })
+		case *option.TrojanInboundOptions:
+			limits = common.Map(options.Users, func(it option.TrojanUser) option.InboundUserLimitOptions { return it.InboundUserLimitOptions })
+		case *option.ShadowsocksInboundOptions:
+			limits = common.Map(options.Users, func(it option.ShadowsocksUser) option.InboundUserLimitOptions { return it.InboundUserLimitOptions })
+		case *option.Hysteria2InboundOptions:
+			limits = common.Map(options.Users, func(it option.Hysteria2User) option.InboundUserLimitOptions { return it.InboundUserLimitOptions })
+		case *option.TUICInboundOptions:
+			limits = common.Map(options.Users, func(it option.TUICUser) option.InboundUserLimitOptions { return it.InboundUserLimitOptions })
+		}
+		if common.Any(limits, func(it option.InboundUserLimitOptions) bool {
+			return it != option.InboundUserLimitOptions{}
+		}) {
+			return true
+		}
+	}
+	return false
+}
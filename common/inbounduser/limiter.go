@@ -0,0 +1,382 @@
+package inbounduser
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing/common/atomic"
+	"github.com/sagernet/sing/common/bufio"
+	E "github.com/sagernet/sing/common/exceptions"
+	N "github.com/sagernet/sing/common/network"
+	"github.com/sagernet/sing/common/ntp"
+	"github.com/sagernet/sing/service"
+
+	"golang.org/x/time/rate"
+)
+
+const limiterCheckInterval = 30 * time.Second
+
+var _ adapter.InboundUserLimiter = (*Limiter)(nil)
+
+// Limiter enforces the limits of users of adapter.ManagedUserInbound inbounds.
+//
+// Traffic usage of every named user is counted, including users without
+// limits. It is the sum of both directions, and is persisted in the cache file
+// if enabled.
+type Limiter struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	logger    log.ContextLogger
+	inbound   adapter.InboundManager
+	cacheFile adapter.CacheFile
+	timeFunc  func() time.Time
+	access    sync.Mutex
+	users     map[limiterKey]*limiterUser
+	done      chan struct{}
+}
+
+type limiterKey struct {
+	inbound string
+	user    string
+}
+
+type limiterUser struct {
+	key          limiterKey
+	trafficLimit atomic.Uint64
+	expireAt     atomic.Int64
+	used         atomic.Uint64
+	saved        uint64
+	exceeded     atomic.Bool
+	speedLimit   uint64
+	readLimiter  *rate.Limiter
+	writeLimiter *rate.Limiter
+	conns        map[*limiterConn]struct{}
+}
+
+func NewLimiter(ctx context.Context, logger log.ContextLogger, inbound adapter.InboundManager) *Limiter {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Limiter{
+		ctx:     ctx,
+		cancel:  cancel,
+		logger:  logger,
+		inbound: inbound,
+		users:   make(map[limiterKey]*limiterUser),
+		done:    make(chan struct{}),
+	}
+}
+
+func (l *Limiter) Name() string {
+	return "inbound user limiter"
+}
+
+func (l *Limiter) Start(stage adapter.StartStage) error {
+	if stage != adapter.StartStateStart {
+		return nil
+	}
+	l.cacheFile = service.FromContext[adapter.CacheFile](l.ctx)
+	l.timeFunc = ntp.TimeFuncFromContext(l.ctx)
+	if l.timeFunc == nil {
+		l.timeFunc = time.Now
+	}
+	go l.loopCheck()
+	return nil
+}
+
+func (l *Limiter) Close() error {
+	l.cancel()
+	if l.timeFunc != nil {
+		<-l.done
+	}
+	l.access.Lock()
+	defer l.access.Unlock()
+	l.saveLocked()
+	return nil
+}
+
+func (l *Limiter) LimitConnection(ctx context.Context, conn net.Conn, metadata adapter.InboundContext) (net.Conn, error) {
+	user, limitConn, err := l.routed(metadata)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return conn, nil
+	}
+	if limitConn.readLimiter != nil {
+		conn = &rateConn{conn, l.ctx, limitConn.readLimiter, limitConn.writeLimiter}
+	}
+	conn = bufio.NewCounterConn(conn, []N.CountFunc{user.counter(l)}, []N.CountFunc{user.counter(l)})
+	limitConn.Closer = conn
+	err = limitConn.add()
+	if err != nil {
+		return nil, err
+	}
+	return &limiterConnWrapper{conn, limitConn}, nil
+}
+
+func (l *Limiter) LimitPacketConnection(ctx context.Context, conn N.PacketConn, metadata adapter.InboundContext) (N.PacketConn, error) {
+	user, limitConn, err := l.routed(metadata)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return conn, nil
+	}
+	if limitConn.readLimiter != nil {
+		conn = &ratePacketConn{conn, l.ctx, limitConn.readLimiter, limitConn.writeLimiter}
+	}
+	conn = bufio.NewCounterPacketConn(conn, []N.CountFunc{user.counter(l)}, []N.CountFunc{user.counter(l)})
+	limitConn.Closer = conn
+	err = limitConn.add()
+	if err != nil {
+		return nil, err
+	}
+	return &limiterPacketConnWrapper{conn, limitConn}, nil
+}
+
+func (l *Limiter) TrafficUsage(inbound string, user string) uint64 {
+	l.access.Lock()
+	defer l.access.Unlock()
+	limitUser, loaded := l.users[limiterKey{inbound, user}]
+	if loaded {
+		return limitUser.used.Load()
+	}
+	if l.cacheFile != nil {
+		return l.cacheFile.LoadUserTraffic(inbound, user)
+	}
+	return 0
+}
+
+func (l *Limiter) ResetTrafficUsage(inbound string, user string) error {
+	l.access.Lock()
+	defer l.access.Unlock()
+	limitUser, loaded := l.users[limiterKey{inbound, user}]
+	if loaded {
+		limitUser.used.Store(0)
+		limitUser.saved = 0
+		limitUser.exceeded.Store(false)
+	}
+	if l.cacheFile != nil {
+		return l.cacheFile.StoreUserTraffic(inbound, user, 0)
+	}
+	return nil
+}
+
+func (l *Limiter) routed(metadata adapter.InboundContext) (*limiterUser, *limiterConn, error) {
+	if metadata.User == "" || l.timeFunc == nil {
+		return nil, nil, nil
+	}
+	inbound, loaded := l.inbound.Get(metadata.Inbound)
+	if !loaded {
+		return nil, nil, nil
+	}
+	managedInbound, isManaged := inbound.(adapter.ManagedUserInbound)
+	if !isManaged {
+		return nil, nil, nil
+	}
+	inboundUser, loaded := managedInbound.User(metadata.User)
+	if !loaded {
+		return nil, nil, nil
+	}
+	l.access.Lock()
+	defer l.access.Unlock()
+	key := limiterKey{metadata.Inbound, metadata.User}
+	user, loaded := l.users[key]
+	if !loaded {
+		user = &limiterUser{
+			key:   key,
+			conns: make(map[*limiterConn]struct{}),
+		}
+		if l.cacheFile != nil {
+			user.saved = l.cacheFile.LoadUserTraffic(key.inbound, key.user)
+			user.used.Store(user.saved)
+		}
+		l.users[key] = user
+	}
+	user.update(inboundUser)
+	err := user.check(l.timeFunc())
+	if err != nil {
+		return nil, nil, err
+	}
+	limitConn := &limiterConn{
+		limiter:      l,
+		user:         user,
+		readLimiter:  user.readLimiter,
+		writeLimiter: user.writeLimiter,
+	}
+	return user, limitConn, nil
+}
+
+func (l *Limiter) loopCheck() {
+	defer close(l.done)
+	ticker := time.NewTicker(limiterCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		l.check()
+	}
+}
+
+func (l *Limiter) check() {
+	now := l.timeFunc()
+	l.access.Lock()
+	defer l.access.Unlock()
+	for _, user := range l.users {
+		if len(user.conns) == 0 {
+			continue
+		}
+		err := user.check(now)
+		if err != nil {
+			l.logger.Info("close connections of user ", user.key.user, ": ", err)
+			user.closeAllLocked()
+		}
+	}
+	l.saveLocked()
+}
+
+func (l *Limiter) saveLocked() {
+	if l.cacheFile == nil {
+		return
+	}
+	for _, user := range l.users {
+		used := user.used.Load()
+		if used == user.saved {
+			continue
+		}
+		err := l.cacheFile.StoreUserTraffic(user.key.inbound, user.key.user, used)
+		if err != nil {
+			l.logger.Warn("save traffic usage of user ", user.key.user, ": ", err)
+			continue
+		}
+		user.saved = used
+	}
+}
+
+func (l *Limiter) closeExceeded(user *limiterUser) {
+	l.access.Lock()
+	defer l.access.Unlock()
+	l.logger.Info("close connections of user ", user.key.user, ": traffic limit exceeded")
+	user.closeAllLocked()
+}
+
+func (u *limiterUser) update(inboundUser adapter.InboundUser) {
+	u.trafficLimit.Store(inboundUser.TrafficLimit)
+	u.expireAt.Store(inboundUser.ExpireAt)
+	if inboundUser.TrafficLimit == 0 || u.used.Load() < inboundUser.TrafficLimit {
+		u.exceeded.Store(false)
+	}
+	if inboundUser.SpeedLimit == u.speedLimit {
+		return
+	}
+	u.speedLimit = inboundUser.SpeedLimit
+	if u.speedLimit == 0 {
+		u.readLimiter = nil
+		u.writeLimiter = nil
+		return
+	}
+	u.readLimiter = rate.NewLimiter(rate.Limit(u.speedLimit), int(u.speedLimit))
+	u.writeLimiter = rate.NewLimiter(rate.Limit(u.speedLimit), int(u.speedLimit))
+}
+
+func (u *limiterUser) check(now time.Time) error {
+	if expireAt := u.expireAt.Load(); expireAt != 0 && now.Unix() >= expireAt {
+		return E.New("expired")
+	}
+	if trafficLimit := u.trafficLimit.Load(); trafficLimit != 0 && u.used.Load() >= trafficLimit {
+		return E.New("traffic limit exceeded")
+	}
+	return nil
+}
+
+func (u *limiterUser) counter(l *Limiter) N.CountFunc {
+	return func(n int64) {
+		used := u.used.Add(uint64(n))
+		if trafficLimit := u.trafficLimit.Load(); trafficLimit != 0 && used >= trafficLimit && u.exceeded.CompareAndSwap(false, true) {
+			go l.closeExceeded(u)
+		}
+	}
+}
+
+func (u *limiterUser) closeAllLocked() {
+	for conn := range u.conns {
+		delete(u.conns, conn)
+		go conn.Closer.Close()
+	}
+}
+
+type limiterConn struct {
+	io.Closer
+	limiter      *Limiter
+	user         *limiterUser
+	readLimiter  *rate.Limiter
+	writeLimiter *rate.Limiter
+}
+
+func (c *limiterConn) add() error {
+	c.limiter.access.Lock()
+	defer c.limiter.access.Unlock()
+	err := c.user.check(c.limiter.timeFunc())
+	if err != nil {
+		return err
+	}
+	c.user.conns[c] = struct{}{}
+	return nil
+}
+
+func (c *limiterConn) remove() {
+	c.limiter.access.Lock()
+	delete(c.user.conns, c)
+	c.limiter.access.Unlock()
+}
+
+type limiterConnWrapper struct {
+	net.Conn
+	limitConn *limiterConn
+}
+
+func (c *limiterConnWrapper) Close() error {
+	c.limitConn.remove()
+	return c.Conn.Close()
+}
+
+func (c *limiterConnWrapper) Upstream() any {
+	return c.Conn
+}
+
+func (c *limiterConnWrapper) ReaderReplaceable() bool {
+	return true
+}
+
+func (c *limiterConnWrapper) WriterReplaceable() bool {
+	return true
+}
+
+type limiterPacketConnWrapper struct {
+	N.PacketConn
+	limitConn *limiterConn
+}
+
+func (c *limiterPacketConnWrapper) Close() error {
+	c.limitConn.remove()
+	return c.PacketConn.Close()
+}
+
+func (c *limiterPacketConnWrapper) Upstream() any {
+	return c.PacketConn
+}
+
+func (c *limiterPacketConnWrapper) ReaderReplaceable() bool {
+	return true
+}
+
+func (c *limiterPacketConnWrapper) WriterReplaceable() bool {
+	return true
+}
@@ -0,0 +1,197 @@
+package inbounduser
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/experimental/cachefile"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing/common/atomic"
+	"github.com/sagernet/sing/common/ntp"
+	"github.com/sagernet/sing/service"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+type testInbound struct {
+	adapter.ManagedUserInbound
+	access sync.Mutex
+	users  map[string]adapter.InboundUser
+}
+
+func (i *testInbound) User(name string) (adapter.InboundUser, bool) {
+	i.access.Lock()
+	defer i.access.Unlock()
+	user, loaded := i.users[name]
+	return user, loaded
+}
+
+func (i *testInbound) setUser(user adapter.InboundUser) {
+	i.access.Lock()
+	defer i.access.Unlock()
+	i.users[user.Name] = user
+}
+
+type testInboundManager struct {
+	adapter.InboundManager
+	inbound *testInbound
+}
+
+func (m *testInboundManager) Get(tag string) (adapter.Inbound, bool) {
+	return m.inbound, tag == "test"
+}
+
+type testTimeService struct {
+	now atomic.Int64
+}
+
+func (s *testTimeService) TimeFunc() func() time.Time {
+	return func() time.Time {
+		return time.Unix(s.now.Load(), 0)
+	}
+}
+
+func newTestLimiter(t *testing.T, ctx context.Context, users ...adapter.InboundUser) (*Limiter, *testInbound, *testTimeService) {
+	inbound := &testInbound{users: make(map[string]adapter.InboundUser)}
+	for _, user := range users {
+		inbound.setUser(user)
+	}
+	timeService := &testTimeService{}
+	timeService.now.Store(time.Now().Unix())
+	ctx = service.ContextWith[ntp.TimeService](ctx, timeService)
+	limiter := NewLimiter(ctx, log.NewNOPFactory().Logger(), &testInboundManager{inbound: inbound})
+	require.NoError(t, limiter.Start(adapter.StartStateStart))
+	t.Cleanup(func() {
+		limiter.Close()
+	})
+	return limiter, inbound, timeService
+}
+
+func newTestConn(t *testing.T, limiter *Limiter, user string) (net.Conn, error) {
+	serverConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		clientConn.Close()
+	})
+	go io.Copy(io.Discard, clientConn)
+	conn, err := limiter.LimitConnection(context.Background(), serverConn, adapter.InboundContext{Inbound: "test", User: user})
+	if err != nil {
+		serverConn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func requireClosed(t *testing.T, conn net.Conn) {
+	require.Eventually(t, func() bool {
+		_, err := conn.Write([]byte{0})
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestLimiterTrafficLimit(t *testing.T) {
+	t.Parallel()
+	limiter, _, _ := newTestLimiter(t, context.Background(), adapter.InboundUser{Name: "alice", TrafficLimit: 100})
+	conn, err := newTestConn(t, limiter, "alice")
+	require.NoError(t, err)
+	idleConn, err := newTestConn(t, limiter, "alice")
+	require.NoError(t, err)
+	_, err = conn.Write(make([]byte, 100))
+	require.NoError(t, err)
+	requireClosed(t, conn)
+	requireClosed(t, idleConn)
+	require.Equal(t, uint64(100), limiter.TrafficUsage("test", "alice"))
+	_, err = newTestConn(t, limiter, "alice")
+	require.Error(t, err)
+
+	require.NoError(t, limiter.ResetTrafficUsage("test", "alice"))
+	require.Zero(t, limiter.TrafficUsage("test", "alice"))
+	conn, err = newTestConn(t, limiter, "alice")
+	require.NoError(t, err)
+	_, err = conn.Write(make([]byte, 10))
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), limiter.TrafficUsage("test", "alice"))
+}
+
+func TestLimiterUnlimitedUser(t *testing.T) {
+	t.Parallel()
+	limiter, _, _ := newTestLimiter(t, context.Background(), adapter.InboundUser{Name: "alice"})
+	conn, err := newTestConn(t, limiter, "alice")
+	require.NoError(t, err)
+	_, isRateConn := conn.(*limiterConnWrapper).Conn.(*rateConn)
+	require.False(t, isRateConn)
+	_, err = conn.Write(make([]byte, 10))
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), limiter.TrafficUsage("test", "alice"))
+}
+
+func TestLimiterExpire(t *testing.T) {
+	t.Parallel()
+	limiter, inbound, timeService := newTestLimiter(t, context.Background())
+	inbound.setUser(adapter.InboundUser{Name: "alice", ExpireAt: timeService.now.Load() + 60})
+	conn, err := newTestConn(t, limiter, "alice")
+	require.NoError(t, err)
+	limiter.check()
+	_, err = conn.Write([]byte{0})
+	require.NoError(t, err)
+
+	timeService.now.Add(60)
+	limiter.check()
+	requireClosed(t, conn)
+	_, err = newTestConn(t, limiter, "alice")
+	require.Error(t, err)
+
+	inbound.setUser(adapter.InboundUser{Name: "alice", ExpireAt: timeService.now.Load() + 60})
+	_, err = newTestConn(t, limiter, "alice")
+	require.NoError(t, err)
+}
+
+func TestLimiterPersistence(t *testing.T) {
+	t.Parallel()
+	cacheFile := cachefile.New(context.Background(), option.CacheFileOptions{
+		Enabled: true,
+		Path:    filepath.Join(t.TempDir(), "cache.db"),
+	})
+	require.NoError(t, cacheFile.Start(adapter.StartStateInitialize))
+	t.Cleanup(func() {
+		cacheFile.Close()
+	})
+	ctx := service.ContextWith[adapter.CacheFile](context.Background(), cacheFile)
+
+	limiter, _, _ := newTestLimiter(t, ctx, adapter.InboundUser{Name: "alice", TrafficLimit: 100})
+	conn, err := newTestConn(t, limiter, "alice")
+	require.NoError(t, err)
+	_, err = conn.Write(make([]byte, 60))
+	require.NoError(t, err)
+	require.NoError(t, limiter.Close())
+	require.Equal(t, uint64(60), cacheFile.LoadUserTraffic("test", "alice"))
+
+	limiter, _, _ = newTestLimiter(t, ctx, adapter.InboundUser{Name: "alice", TrafficLimit: 100})
+	require.Equal(t, uint64(60), limiter.TrafficUsage("test", "alice"))
+	conn, err = newTestConn(t, limiter, "alice")
+	require.NoError(t, err)
+	_, err = conn.Write(make([]byte, 40))
+	require.NoError(t, err)
+	requireClosed(t, conn)
+	_, err = newTestConn(t, limiter, "alice")
+	require.Error(t, err)
+}
+
+func TestWaitN(t *testing.T) {
+	t.Parallel()
+	limiter := rate.NewLimiter(10000, 1000)
+	start := time.Now()
+	require.NoError(t, waitN(context.Background(), limiter, 5000))
+	require.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, waitN(ctx, limiter, 5000))
+}
@@ -0,0 +1,84 @@
+package inbounduser
+
+import (
+	"context"
+	"net"
+
+	"github.com/sagernet/sing/common/buf"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+
+	"golang.org/x/time/rate"
+)
+
+type rateConn struct {
+	net.Conn
+	ctx          context.Context
+	readLimiter  *rate.Limiter
+	writeLimiter *rate.Limiter
+}
+
+func (c *rateConn) Read(p []byte) (n int, err error) {
+	n, err = c.Conn.Read(p)
+	if n > 0 {
+		waitErr := waitN(c.ctx, c.readLimiter, n)
+		if err == nil {
+			err = waitErr
+		}
+	}
+	return
+}
+
+func (c *rateConn) Write(p []byte) (n int, err error) {
+	err = waitN(c.ctx, c.writeLimiter, len(p))
+	if err != nil {
+		return
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *rateConn) Upstream() any {
+	return c.Conn
+}
+
+type ratePacketConn struct {
+	N.PacketConn
+	ctx          context.Context
+	readLimiter  *rate.Limiter
+	writeLimiter *rate.Limiter
+}
+
+func (c *ratePacketConn) ReadPacket(buffer *buf.Buffer) (destination M.Socksaddr, err error) {
+	destination, err = c.PacketConn.ReadPacket(buffer)
+	if err != nil {
+		return
+	}
+	err = waitN(c.ctx, c.readLimiter, buffer.Len())
+	return
+}
+
+func (c *ratePacketConn) WritePacket(buffer *buf.Buffer, destination M.Socksaddr) error {
+	err := waitN(c.ctx, c.writeLimiter, buffer.Len())
+	if err != nil {
+		buffer.Release()
+		return err
+	}
+	return c.PacketConn.WritePacket(buffer, destination)
+}
+
+func (c *ratePacketConn) Upstream() any {
+	return c.PacketConn
+}
+
+func waitN(ctx context.Context, limiter *rate.Limiter, n int) error {
+	burst := limiter.Burst()
+	for n > 0 {
+		chunk := min(n, burst)
+		err := limiter.WaitN(ctx, chunk)
+		if err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
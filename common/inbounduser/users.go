@@ -0,0 +1,134 @@
+package inbounduser
+
+import (
+	"sync"
+
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+)
+
+// duplicatedUserID indexes names shared by several configured users.
+const duplicatedUserID = -1
+
+// Users holds the users of an inbound that implements adapter.ManagedUserInbound.
+//
+// Users are keyed by IDs that are never reused, so a connection authenticated
+// before its user was removed can never resolve to another user.
+type Users[U any] struct {
+	access  sync.RWMutex
+	name    func(user U) string
+	update  func(userIDs []int, users []U) error
+	userIDs []int
+	users   map[int]U
+	names   map[string]int
+	nextID  int
+}
+
+// New creates users with IDs matching their index in userList, and passes them to update.
+//
+// Users without a name or with a duplicated name are accepted for compatibility,
+// but cannot be managed by name.
+func New[U any](userList []U, name func(user U) string, update func(userIDs []int, users []U) error) (*Users[U], error) {
+	users := &Users[U]{
+		name:   name,
+		update: update,
+		users:  make(map[int]U),
+		names:  make(map[string]int),
+	}
+	for _, user := range userList {
+		users.userIDs = append(users.userIDs, users.nextID)
+		users.users[users.nextID] = user
+		if userName := name(user); userName != "" {
+			if _, loaded := users.names[userName]; loaded {
+				users.names[userName] = duplicatedUserID
+			} else {
+				users.names[userName] = users.nextID
+			}
+		}
+		users.nextID++
+	}
+	err := update(users.userIDs, userList)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (u *Users[U]) User(userID int) (U, bool) {
+	u.access.RLock()
+	defer u.access.RUnlock()
+	user, loaded := u.users[userID]
+	return user, loaded
+}
+
+func (u *Users[U]) Get(name string) (U, bool) {
+	u.access.RLock()
+	defer u.access.RUnlock()
+	userID, loaded := u.names[name]
+	if !loaded || userID == duplicatedUserID {
+		var defaultUser U
+		return defaultUser, false
+	}
+	return u.users[userID], true
+}
+
+func (u *Users[U]) List() []U {
+	u.access.RLock()
+	defer u.access.RUnlock()
+	return u.listLocked()
+}
+
+func (u *Users[U]) Add(user U) error {
+	name := u.name(user)
+	if name == "" {
+		return E.New("missing user name")
+	}
+	u.access.Lock()
+	defer u.access.Unlock()
+	if _, loaded := u.names[name]; loaded {
+		return E.New("user already exists: ", name)
+	}
+	userIDs := append(append([]int(nil), u.userIDs...), u.nextID)
+	err := u.update(userIDs, append(u.listLocked(), user))
+	if err != nil {
+		return err
+	}
+	u.userIDs = userIDs
+	u.users[u.nextID] = user
+	u.names[name] = u.nextID
+	u.nextID++
+	return nil
+}
+
+func (u *Users[U]) Remove(name string) error {
+	u.access.Lock()
+	defer u.access.Unlock()
+	userID, loaded := u.names[name]
+	if !loaded {
+		return E.New("user not found: ", name)
+	} else if userID == duplicatedUserID {
+		return E.New("user name is duplicated in configuration: ", name)
+	}
+	index := common.Index(u.userIDs, func(it int) bool {
+		return it == userID
+	})
+	userIDs := append(append([]int(nil), u.userIDs[:index]...), u.userIDs[index+1:]...)
+	userList := u.listLocked()
+	userList = append(userList[:index], userList[index+1:]...)
+	err := u.update(userIDs, userList)
+	if err != nil {
+		return err
+	}
+	u.userIDs = userIDs
+	delete(u.users, userID)
+	delete(u.names, name)
+	return nil
+}
+
+func (u *Users[U]) listLocked() []U {
+	userList := make([]U, 0, len(u.userIDs)+1)
+	for _, userID := range u.userIDs {
+		userList = append(userList, u.users[userID])
+	}
+	return userList
+}
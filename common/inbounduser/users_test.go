@@ -0,0 +1,115 @@
+package inbounduser
+
+import (
+	"sync"
+	"testing"
+
+	E "github.com/sagernet/sing/common/exceptions"
+	F "github.com/sagernet/sing/common/format"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsers(t *testing.T) {
+	t.Parallel()
+	var serviceIDs []int
+	var serviceUsers []string
+	users, err := New([]string{"alice", "bob"}, func(user string) string {
+		return user
+	}, func(userIDs []int, userList []string) error {
+		if len(userList) > 0 && userList[len(userList)-1] == "invalid" {
+			return E.New("invalid user")
+		}
+		serviceIDs = userIDs
+		serviceUsers = userList
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1}, serviceIDs)
+
+	require.NoError(t, users.Remove("alice"))
+	require.Error(t, users.Remove("alice"))
+	require.NoError(t, users.Add("carol"))
+	require.Equal(t, []int{1, 2}, serviceIDs)
+	require.Equal(t, []string{"bob", "carol"}, serviceUsers)
+	_, loaded := users.User(0)
+	require.False(t, loaded, "removed user ID must not be reused")
+	user, loaded := users.User(2)
+	require.True(t, loaded)
+	require.Equal(t, "carol", user)
+	user, loaded = users.Get("carol")
+	require.True(t, loaded)
+	require.Equal(t, "carol", user)
+	_, loaded = users.Get("alice")
+	require.False(t, loaded)
+
+	require.Error(t, users.Add("bob"))
+	require.Error(t, users.Add(""))
+	require.Error(t, users.Add("invalid"))
+	require.Equal(t, []string{"bob", "carol"}, users.List())
+	require.Equal(t, []int{1, 2}, serviceIDs)
+}
+
+func TestUsersNameIndex(t *testing.T) {
+	t.Parallel()
+	update := func(userIDs []int, userList []string) error {
+		return nil
+	}
+	name := func(user string) string {
+		return user
+	}
+	users, err := New([]string{"", "", "alice", "bob", "bob"}, name, update)
+	require.NoError(t, err)
+	require.Error(t, users.Remove(""))
+	// duplicated names are served, but cannot be managed
+	_, loaded := users.Get("bob")
+	require.False(t, loaded)
+	require.Error(t, users.Remove("bob"))
+	require.Error(t, users.Add("bob"))
+	user, loaded := users.User(4)
+	require.True(t, loaded)
+	require.Equal(t, "bob", user)
+	require.NoError(t, users.Remove("alice"))
+	require.Equal(t, []string{"", "", "bob", "bob"}, users.List())
+}
+
+func TestUsersConcurrent(t *testing.T) {
+	t.Parallel()
+	var serviceUsers []string
+	users, err := New([]string{"alice"}, func(user string) string {
+		return user
+	}, func(userIDs []int, userList []string) error {
+		// updates are serialized, so the service may keep its users without locking
+		serviceUsers = userList
+		return nil
+	})
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				name := F.ToString("user", i, "-", j)
+				assert.NoError(t, users.Add(name))
+				assert.NoError(t, users.Remove(name))
+			}
+		}()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				user, loaded := users.User(0)
+				assert.True(t, loaded)
+				assert.Equal(t, "alice", user)
+				_, loaded = users.Get("alice")
+				assert.True(t, loaded)
+				assert.Contains(t, users.List(), "alice")
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, []string{"alice"}, users.List())
+	require.Equal(t, []string{"alice"}, serviceUsers)
+}
@@ -0,0 +1,184 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	E "github.com/sagernet/sing/common/exceptions"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Keys and signatures use the minisign format, so content can also be signed
+// with minisign and verified here. Bare base64 ed25519 keys and signatures are accepted too.
+
+const (
+	algorithmLegacy   = "Ed"
+	algorithmPrehash  = "ED"
+	keyIDLength       = 8
+	untrustedComment  = "untrusted comment: "
+	trustedComment    = "trusted comment: "
+	maxSignatureBytes = 4096
+)
+
+type PublicKey struct {
+	keyID []byte
+	key   ed25519.PublicKey
+}
+
+type PrivateKey struct {
+	keyID []byte
+	key   ed25519.PrivateKey
+}
+
+func GenerateKey() (*PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	keyID := make([]byte, keyIDLength)
+	_, err = rand.Read(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{keyID, key}, nil
+}
+
+func ParsePublicKey(content string) (*PublicKey, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(lastLine(content))
+	if err != nil {
+		return nil, E.Cause(err, "decode public key")
+	}
+	switch len(keyBytes) {
+	case ed25519.PublicKeySize:
+		return &PublicKey{key: keyBytes}, nil
+	case 2 + keyIDLength + ed25519.PublicKeySize:
+		if string(keyBytes[:2]) != algorithmLegacy {
+			return nil, E.New("unsupported public key algorithm: ", string(keyBytes[:2]))
+		}
+		return &PublicKey{keyBytes[2 : 2+keyIDLength], keyBytes[2+keyIDLength:]}, nil
+	default:
+		return nil, E.New("invalid public key length: ", len(keyBytes))
+	}
+}
+
+func ParsePrivateKey(content string) (*PrivateKey, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(lastLine(content))
+	if err != nil {
+		return nil, E.Cause(err, "decode private key")
+	}
+	switch len(keyBytes) {
+	case ed25519.SeedSize:
+		return &PrivateKey{key: ed25519.NewKeyFromSeed(keyBytes)}, nil
+	case ed25519.PrivateKeySize:
+		return &PrivateKey{key: keyBytes}, nil
+	case 2 + keyIDLength + ed25519.PrivateKeySize:
+		if string(keyBytes[:2]) != algorithmLegacy {
+			return nil, E.New("unsupported private key algorithm: ", string(keyBytes[:2]))
+		}
+		return &PrivateKey{keyBytes[2 : 2+keyIDLength], keyBytes[2+keyIDLength:]}, nil
+	default:
+		return nil, E.New("invalid private key length: ", len(keyBytes))
+	}
+}
+
+func (k *PublicKey) String() string {
+	if k.keyID == nil {
+		return base64.StdEncoding.EncodeToString(k.key)
+	}
+	return base64.StdEncoding.EncodeToString(append(append([]byte(algorithmLegacy), k.keyID...), k.key...))
+}
+
+func (k *PrivateKey) String() string {
+	if k.keyID == nil {
+		return base64.StdEncoding.EncodeToString(k.key)
+	}
+	return base64.StdEncoding.EncodeToString(append(append([]byte(algorithmLegacy), k.keyID...), k.key...))
+}
+
+func (k *PrivateKey) Public() *PublicKey {
+	return &PublicKey{k.keyID, k.key.Public().(ed25519.PublicKey)}
+}
+
+// Sign returns a minisign signature file of the BLAKE2b-512 prehashed content.
+func (k *PrivateKey) Sign(content []byte, comment string) []byte {
+	hash := blake2b.Sum512(content)
+	keyID := k.keyID
+	if keyID == nil {
+		keyID = make([]byte, keyIDLength)
+	}
+	signature := append(append([]byte(algorithmPrehash), keyID...), ed25519.Sign(k.key, hash[:])...)
+	globalSignature := ed25519.Sign(k.key, append(append([]byte{}, signature[2+keyIDLength:]...), comment...))
+	var buffer bytes.Buffer
+	buffer.WriteString(untrustedComment + "signature from sing-box secret key\n")
+	buffer.WriteString(base64.StdEncoding.EncodeToString(signature) + "\n")
+	buffer.WriteString(trustedComment + comment + "\n")
+	buffer.WriteString(base64.StdEncoding.EncodeToString(globalSignature) + "\n")
+	return buffer.Bytes()
+}
+
+func (k *PublicKey) Verify(content []byte, signatureContent []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(signatureContent)), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	if strings.HasPrefix(lines[0], untrustedComment) {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return E.New("missing signature")
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return E.Cause(err, "decode signature")
+	}
+	var (
+		algorithm string
+		signature []byte
+	)
+	switch len(signatureBytes) {
+	case ed25519.SignatureSize:
+		algorithm = algorithmLegacy
+		signature = signatureBytes
+	case 2 + keyIDLength + ed25519.SignatureSize:
+		algorithm = string(signatureBytes[:2])
+		keyID := signatureBytes[2 : 2+keyIDLength]
+		if k.keyID != nil && !bytes.Equal(k.keyID, keyID) {
+			return E.New("signature key id mismatch")
+		}
+		signature = signatureBytes[2+keyIDLength:]
+	default:
+		return E.New("invalid signature length: ", len(signatureBytes))
+	}
+	message := content
+	switch algorithm {
+	case algorithmLegacy:
+	case algorithmPrehash:
+		hash := blake2b.Sum512(content)
+		message = hash[:]
+	default:
+		return E.New("unsupported signature algorithm: ", algorithm)
+	}
+	if !ed25519.Verify(k.key, message, signature) {
+		return E.New("signature verification failed")
+	}
+	if len(lines) >= 3 && strings.HasPrefix(lines[1], trustedComment) {
+		globalSignature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[2]))
+		if err != nil {
+			return E.Cause(err, "decode global signature")
+		}
+		comment := strings.TrimPrefix(lines[1], trustedComment)
+		if !ed25519.Verify(k.key, append(append([]byte{}, signature...), comment...), globalSignature) {
+			return E.New("trusted comment verification failed")
+		}
+	}
+	return nil
+}
+
+func lastLine(content string) string {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,76 @@
+package signature_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sagernet/sing-box/common/signature"
+	"github.com/sagernet/sing-box/option"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	t.Parallel()
+	privateKey, err := signature.GenerateKey()
+	require.NoError(t, err)
+	publicKey, err := signature.ParsePublicKey(privateKey.Public().String())
+	require.NoError(t, err)
+	content := []byte(`{"version":1,"rules":[{"domain":["example.org"]}]}`)
+	signatureContent := privateKey.Sign(content, "file:example.json")
+	require.NoError(t, publicKey.Verify(content, signatureContent))
+	require.Error(t, publicKey.Verify(append(content, ' '), signatureContent))
+	otherKey, err := signature.GenerateKey()
+	require.NoError(t, err)
+	require.Error(t, otherKey.Public().Verify(content, signatureContent))
+}
+
+func TestVerifier(t *testing.T) {
+	t.Parallel()
+	privateKey, err := signature.GenerateKey()
+	require.NoError(t, err)
+	content := []byte("content")
+	signatureContent := privateKey.Sign(content, "")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/content":
+			w.Header().Set("X-Signature", strings.Split(string(signatureContent), "\n")[1])
+			w.Write(content)
+		case "/content.sig":
+			w.Write(signatureContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	for _, testCase := range []struct {
+		path   string
+		header string
+		valid  bool
+	}{
+		{"/content", "", true},
+		{"/content", "X-Signature", true},
+		{"/content", "X-Missing", false},
+		{"/unsigned", "", false},
+	} {
+		verifier, err := signature.NewVerifier(option.SignatureVerifyOptions{
+			PublicKey:       privateKey.Public().String(),
+			SignatureHeader: testCase.header,
+		})
+		require.NoError(t, err)
+		request, err := http.NewRequest(http.MethodGet, server.URL+testCase.path, nil)
+		require.NoError(t, err)
+		response, err := server.Client().Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+		err = verifier.Verify(context.Background(), server.Client(), request, response, content)
+		if testCase.valid {
+			require.NoError(t, err, testCase.path, testCase.header)
+		} else {
+			require.Error(t, err, testCase.path, testCase.header)
+		}
+	}
+}
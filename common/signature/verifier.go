@@ -0,0 +1,63 @@
+package signature
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/sagernet/sing-box/option"
+	E "github.com/sagernet/sing/common/exceptions"
+)
+
+type Verifier struct {
+	publicKey *PublicKey
+	header    string
+}
+
+func NewVerifier(options option.SignatureVerifyOptions) (*Verifier, error) {
+	if options.PublicKey == "" {
+		return nil, E.New("missing public key")
+	}
+	publicKey, err := ParsePublicKey(options.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{
+		publicKey: publicKey,
+		header:    options.SignatureHeader,
+	}, nil
+}
+
+// Verify checks content downloaded by request against the signature
+// carried in the configured response header, or fetched from <url>.sig.
+func (v *Verifier) Verify(ctx context.Context, client *http.Client, request *http.Request, response *http.Response, content []byte) error {
+	var signature []byte
+	if v.header != "" {
+		headerValue := response.Header.Get(v.header)
+		if headerValue == "" {
+			return E.New("missing signature header: ", v.header)
+		}
+		signature = []byte(headerValue)
+	} else {
+		signatureRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, request.URL.String()+".sig", nil)
+		if err != nil {
+			return err
+		}
+		if userAgent := request.Header.Get("User-Agent"); userAgent != "" {
+			signatureRequest.Header.Set("User-Agent", userAgent)
+		}
+		signatureResponse, err := client.Do(signatureRequest)
+		if err != nil {
+			return E.Cause(err, "fetch signature")
+		}
+		defer signatureResponse.Body.Close()
+		if signatureResponse.StatusCode != http.StatusOK {
+			return E.New("fetch signature: unexpected status: ", signatureResponse.Status)
+		}
+		signature, err = io.ReadAll(io.LimitReader(signatureResponse.Body, maxSignatureBytes))
+		if err != nil {
+			return E.Cause(err, "fetch signature")
+		}
+	}
+	return v.publicKey.Verify(content, signature)
+}
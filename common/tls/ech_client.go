@@ -6,7 +6,6 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/base64"
 	"encoding/pem"
 	"net"
 	"net/netip"
@@ -197,20 +196,23 @@ func NewECHClient(ctx context.Context, serverAddress string, options option.Outb
 		}
 		tlsConfig.ClientECHConfigs = echConfigs
 	} else {
-		tlsConfig.GetClientECHConfigs = fetchECHClientConfig(ctx)
+		tlsConfig.GetClientECHConfigs = fetchECHClientConfig(ctx, options.ECH.QueryServerName)
 	}
 	return &echClientConfig{&tlsConfig}, nil
 }
 
-func fetchECHClientConfig(ctx context.Context) func(_ context.Context, serverName string) ([]cftls.ECHConfig, error) {
+func fetchECHClientConfig(ctx context.Context, queryServerName string) func(_ context.Context, serverName string) ([]cftls.ECHConfig, error) {
 	return func(_ context.Context, serverName string) ([]cftls.ECHConfig, error) {
+		if queryServerName != "" {
+			serverName = queryServerName
+		}
 		message := &mDNS.Msg{
 			MsgHdr: mDNS.MsgHdr{
 				RecursionDesired: true,
 			},
 			Question: []mDNS.Question{
 				{
-					Name:   serverName + ".",
+					Name:   mDNS.Fqdn(serverName),
 					Qtype:  mDNS.TypeHTTPS,
 					Qclass: mDNS.ClassINET,
 				},
@@ -220,25 +222,10 @@ func fetchECHClientConfig(ctx context.Context) func(_ context.Context, serverNam
 		if err != nil {
 			return nil, err
 		}
-		if response.Rcode != mDNS.RcodeSuccess {
-			return nil, dns.RCodeError(response.Rcode)
-		}
-		for _, rr := range response.Answer {
-			switch resource := rr.(type) {
-			case *mDNS.HTTPS:
-				for _, value := range resource.Value {
-					if value.Key().String() == "ech" {
-						echConfig, err := base64.StdEncoding.DecodeString(value.String())
-						if err != nil {
-							return nil, E.Cause(err, "decode ECH config")
-						}
-						return cftls.UnmarshalECHConfigs(echConfig)
-					}
-				}
-			default:
-				return nil, E.New("unknown resource record type: ", resource.Header().Rrtype)
-			}
+		echConfig, err := dns.ECHConfigFromMessage(response)
+		if err != nil {
+			return nil, E.Cause(err, "query ECH config for ", serverName)
 		}
-		return nil, E.New("no ECH config found")
+		return cftls.UnmarshalECHConfigs(echConfig)
 	}
 }
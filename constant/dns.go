@@ -28,6 +28,12 @@ const (
 	DNSTypeFakeIP     = "fakeip"
 	DNSTypeDHCP       = "dhcp"
 	DNSTypeTailscale  = "tailscale"
+	DNSTypeParallel   = "parallel"
+)
+
+const (
+	DNSParallelModeRace     = "race"
+	DNSParallelModeFallback = "fallback"
 )
 
 const (
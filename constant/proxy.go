@@ -27,8 +27,16 @@ const (
 )
 
 const (
-	TypeSelector = "selector"
-	TypeURLTest  = "urltest"
+	TypeSelector    = "selector"
+	TypeURLTest     = "urltest"
+	TypeFallback    = "fallback"
+	TypeLoadBalance = "loadbalance"
+)
+
+const (
+	LoadBalanceStrategyRoundRobin        = "round-robin"
+	LoadBalanceStrategyConsistentHashing = "consistent-hashing"
+	LoadBalanceStrategyStickySessions    = "sticky-sessions"
 )
 
 func ProxyDisplayName(proxyType string) string {
@@ -81,6 +89,10 @@ func ProxyDisplayName(proxyType string) string {
 		return "Selector"
 	case TypeURLTest:
 		return "URLTest"
+	case TypeFallback:
+		return "Fallback"
+	case TypeLoadBalance:
+		return "LoadBalance"
 	default:
 		return "Unknown"
 	}
@@ -90,3 +102,10 @@ const (
 	ProviderTypeLocal  = "local"
 	ProviderTypeRemote = "remote"
 )
+
+const (
+	ProviderFormatSingBox = "singbox"
+	ProviderFormatSIP008  = "sip008"
+	ProviderFormatClash   = "clash"
+	ProviderFormatURI     = "uri"
+)
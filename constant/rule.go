@@ -33,6 +33,9 @@ const (
 	RuleActionTypeHijackDNS    = "hijack-dns"
 	RuleActionTypeSniff        = "sniff"
 	RuleActionTypeResolve      = "resolve"
+	RuleActionTypeScript       = "script"
+	RuleActionTypePredefined   = "predefined"
+	RuleActionTypeRewrite      = "rewrite"
 )
 
 const (
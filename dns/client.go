@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/netip"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sagernet/sing-box/adapter"
@@ -29,13 +30,35 @@ var (
 
 var _ adapter.DNSClient = (*Client)(nil)
 
+const (
+	// staleTTL is the TTL of expired responses served from cache, as recommended by RFC 8767.
+	staleTTL = 30
+	// staleMaxAge is how long expired responses are kept for serving stale.
+	staleMaxAge = 24 * time.Hour
+)
+
+type cacheState uint8
+
+const (
+	cacheStateFresh cacheState = iota
+	cacheStatePrefetch
+	cacheStateStale
+)
+
 type Client struct {
 	timeout          time.Duration
 	disableCache     bool
 	disableExpire    bool
 	independentCache bool
+	serveStale       bool
+	prefetch         bool
+	dnssec           bool
+	dnssecValidator  *dnssecValidator
+	refreshing       sync.Map
 	rdrc             adapter.RDRCStore
 	initRDRCFunc     func() adapter.RDRCStore
+	cacheStore       adapter.DNSCacheStore
+	initCacheStore   func() adapter.DNSCacheStore
 	logger           logger.ContextLogger
 	cache            freelru.Cache[dns.Question, *dns.Msg]
 	transportCache   freelru.Cache[transportCacheKey, *dns.Msg]
@@ -46,8 +69,13 @@ type ClientOptions struct {
 	DisableCache     bool
 	DisableExpire    bool
 	IndependentCache bool
+	ServeStale       bool
+	Prefetch         bool
+	DNSSEC           bool
+	TrustAnchors     []dns.RR
 	CacheCapacity    uint32
 	RDRC             func() adapter.RDRCStore
+	CacheStore       func() adapter.DNSCacheStore
 	Logger           logger.ContextLogger
 }
 
@@ -57,12 +85,17 @@ func NewClient(options ClientOptions) *Client {
 		disableCache:     options.DisableCache,
 		disableExpire:    options.DisableExpire,
 		independentCache: options.IndependentCache,
+		serveStale:       options.ServeStale && !options.DisableExpire,
+		prefetch:         options.Prefetch && !options.DisableExpire,
+		dnssec:           options.DNSSEC,
 		initRDRCFunc:     options.RDRC,
+		initCacheStore:   options.CacheStore,
 		logger:           options.Logger,
 	}
 	if client.timeout == 0 {
 		client.timeout = C.DNSTimeout
 	}
+	client.dnssecValidator = newDNSSECValidator(client.timeout, options.TrustAnchors)
 	cacheCapacity := options.CacheCapacity
 	if cacheCapacity < 1024 {
 		cacheCapacity = 1024
@@ -86,9 +119,40 @@ func (c *Client) Start() {
 	if c.initRDRCFunc != nil {
 		c.rdrc = c.initRDRCFunc()
 	}
+	if c.initCacheStore != nil && !c.disableCache {
+		c.cacheStore = c.initCacheStore()
+	}
 }
 
 func (c *Client) Exchange(ctx context.Context, transport adapter.DNSTransport, message *dns.Msg, options adapter.DNSQueryOptions, responseChecker func(responseAddrs []netip.Addr) bool) (*dns.Msg, error) {
+	if options.RewriteName != "" && len(message.Question) == 1 && !strings.EqualFold(message.Question[0].Name, options.RewriteName) {
+		question := message.Question[0]
+		message = message.Copy()
+		message.Question[0].Name = options.RewriteName
+		response, err := c.exchange(ctx, transport, message, options, responseChecker, false)
+		if response != nil {
+			response = restoreRewrittenResponse(response, question, options.RewriteName)
+		}
+		return response, err
+	}
+	return c.exchange(ctx, transport, message, options, responseChecker, false)
+}
+
+// restoreRewrittenResponse renames records of the rewritten query name back to the original one.
+func restoreRewrittenResponse(response *dns.Msg, question dns.Question, rewriteName string) *dns.Msg {
+	response = response.Copy()
+	response.Question = []dns.Question{question}
+	for _, recordList := range [][]dns.RR{response.Answer, response.Ns, response.Extra} {
+		for _, record := range recordList {
+			if strings.EqualFold(record.Header().Name, rewriteName) {
+				record.Header().Name = question.Name
+			}
+		}
+	}
+	return response
+}
+
+func (c *Client) exchange(ctx context.Context, transport adapter.DNSTransport, message *dns.Msg, options adapter.DNSQueryOptions, responseChecker func(responseAddrs []netip.Addr) bool, refresh bool) (*dns.Msg, error) {
 	if len(message.Question) == 0 {
 		if c.logger != nil {
 			c.logger.WarnContext(ctx, "bad question size: ", len(message.Question))
@@ -104,18 +168,32 @@ func (c *Client) Exchange(ctx context.Context, transport adapter.DNSTransport, m
 		return &responseMessage, nil
 	}
 	question := message.Question[0]
+	request := message
 	if options.ClientSubnet.IsValid() {
 		message = SetClientSubnet(message, options.ClientSubnet, true)
 	}
+	dnssec := (c.dnssec || options.DNSSEC) && isDNSSECSupported(transport)
 	isSimpleRequest := len(message.Question) == 1 &&
 		len(message.Ns) == 0 &&
 		len(message.Extra) == 0 &&
 		!options.ClientSubnet.IsValid()
 	disableCache := !isSimpleRequest || c.disableCache || options.DisableCache
-	if !disableCache {
-		response, ttl := c.loadResponse(question, transport)
+	if !disableCache && !refresh {
+		response, ttl, state := c.loadResponse(question, transport)
+		if response != nil && dnssec {
+			// responses cached by queries without DNSSEC may not be validated
+			secure, err := c.dnssecValidator.Validate(ctx, transport, response)
+			if err == nil {
+				response = dnssecResponse(request, response, secure)
+			} else {
+				response = nil
+			}
+		}
 		if response != nil {
-			logCachedResponse(c.logger, ctx, response, ttl)
+			logCachedResponse(c.logger, ctx, response, ttl, state)
+			if state != cacheStateFresh {
+				c.refreshCache(ctx, transport, question, options, responseChecker)
+			}
 			response.Id = message.Id
 			return response, nil
 		}
@@ -146,12 +224,30 @@ func (c *Client) Exchange(ctx context.Context, transport adapter.DNSTransport, m
 			return nil, ErrResponseRejectedCached
 		}
 	}
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	response, err := transport.Exchange(ctx, message)
+	if dnssec {
+		message = SetDNSSECOK(message)
+	}
+	exchangeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	response, err := transport.Exchange(exchangeCtx, message)
 	cancel()
 	if err != nil {
 		return nil, err
 	}
+	var secure bool
+	if dnssec {
+		secure, err = c.dnssecValidator.Validate(ctx, transport, response)
+		if err != nil {
+			logBogusResponse(c.logger, ctx, question, err)
+			return &dns.Msg{
+				MsgHdr: dns.MsgHdr{
+					Id:       messageId,
+					Response: true,
+					Rcode:    dns.RcodeServerFailure,
+				},
+				Question: []dns.Question{question},
+			}, nil
+		}
+	}
 	/*if question.Qtype == dns.TypeA || question.Qtype == dns.TypeAAAA {
 		validResponse := response
 	loop:
@@ -197,24 +293,8 @@ func (c *Client) Exchange(ctx context.Context, transport adapter.DNSTransport, m
 			return response, ErrResponseRejected
 		}
 	}
-	if question.Qtype == dns.TypeHTTPS {
-		if options.Strategy == C.DomainStrategyIPv4Only || options.Strategy == C.DomainStrategyIPv6Only {
-			for _, rr := range response.Answer {
-				https, isHTTPS := rr.(*dns.HTTPS)
-				if !isHTTPS {
-					continue
-				}
-				content := https.SVCB
-				content.Value = common.Filter(content.Value, func(it dns.SVCBKeyValue) bool {
-					if options.Strategy == C.DomainStrategyIPv4Only {
-						return it.Key() != dns.SVCB_IPV6HINT
-					} else {
-						return it.Key() != dns.SVCB_IPV4HINT
-					}
-				})
-				https.SVCB = content
-			}
-		}
+	if isServiceQuery(question.Qtype) {
+		FilterServiceHints(response, options.Strategy)
 	}
 	var timeToLive uint32
 	for _, recordList := range [][]dns.RR{response.Answer, response.Ns, response.Extra} {
@@ -236,11 +316,21 @@ func (c *Client) Exchange(ctx context.Context, transport adapter.DNSTransport, m
 	if !disableCache {
 		c.storeCache(transport, question, response, timeToLive)
 	}
-	logExchangedResponse(c.logger, ctx, response, timeToLive)
-	return response, err
+	if refresh {
+		logRefreshedResponse(c.logger, ctx, response, timeToLive)
+	} else {
+		logExchangedResponse(c.logger, ctx, response, timeToLive)
+	}
+	if dnssec {
+		response = dnssecResponse(request, response, secure)
+	}
+	return response, nil
 }
 
 func (c *Client) Lookup(ctx context.Context, transport adapter.DNSTransport, domain string, options adapter.DNSQueryOptions, responseChecker func(responseAddrs []netip.Addr) bool) ([]netip.Addr, error) {
+	if options.RewriteName != "" {
+		domain = options.RewriteName
+	}
 	domain = FqdnToDomain(domain)
 	dnsName := dns.Fqdn(domain)
 	if options.Strategy == C.DomainStrategyIPv4Only {
@@ -275,12 +365,25 @@ func (c *Client) Lookup(ctx context.Context, transport adapter.DNSTransport, dom
 }
 
 func (c *Client) ClearCache() {
+	c.ClearMemoryCache()
+	if c.cacheStore != nil {
+		err := c.cacheStore.ClearDNSCache()
+		if err != nil && c.logger != nil {
+			c.logger.Warn("clear DNS cache: ", err)
+		}
+	}
+}
+
+// ClearMemoryCache purges the in-memory cache only,
+// responses in the persistent cache will be loaded again on demand.
+func (c *Client) ClearMemoryCache() {
 	if c.cache != nil {
 		c.cache.Purge()
 	}
 	if c.transportCache != nil {
 		c.transportCache.Purge()
 	}
+	c.dnssecValidator.Purge()
 }
 
 func (c *Client) LookupCache(domain string, strategy C.DomainStrategy) ([]netip.Addr, bool) {
@@ -332,11 +435,11 @@ func (c *Client) ExchangeCache(ctx context.Context, message *dns.Msg) (*dns.Msg,
 		return nil, false
 	}
 	question := message.Question[0]
-	response, ttl := c.loadResponse(question, nil)
-	if response == nil {
+	response, ttl, state := c.loadResponse(question, nil)
+	if response == nil || state != cacheStateFresh {
 		return nil, false
 	}
-	logCachedResponse(c.logger, ctx, response, ttl)
+	logCachedResponse(c.logger, ctx, response, ttl, state)
 	response.Id = message.Id
 	return response, true
 }
@@ -353,6 +456,9 @@ func (c *Client) storeCache(transport adapter.DNSTransport, question dns.Questio
 	if timeToLive == 0 {
 		return
 	}
+	if c.cacheStore != nil {
+		c.cacheStore.SaveDNSCacheAsync(c.cacheStoreName(transport), question, message, time.Now().Add(time.Second*time.Duration(timeToLive)), c.logger)
+	}
 	if c.disableExpire {
 		if !c.independentCache {
 			c.cache.Add(question, message)
@@ -364,13 +470,17 @@ func (c *Client) storeCache(transport adapter.DNSTransport, question dns.Questio
 		}
 		return
 	}
+	lifetime := time.Second * time.Duration(timeToLive)
+	if c.serveStale {
+		lifetime += staleMaxAge
+	}
 	if !c.independentCache {
-		c.cache.AddWithLifetime(question, message, time.Second*time.Duration(timeToLive))
+		c.cache.AddWithLifetime(question, message, lifetime)
 	} else {
 		c.transportCache.AddWithLifetime(transportCacheKey{
 			Question:     question,
 			transportTag: transport.Tag(),
-		}, message, time.Second*time.Duration(timeToLive))
+		}, message, lifetime)
 	}
 }
 
@@ -380,11 +490,16 @@ func (c *Client) lookupToExchange(ctx context.Context, transport adapter.DNSTran
 		Qtype:  qType,
 		Qclass: dns.ClassINET,
 	}
-	disableCache := c.disableCache || options.DisableCache
+	// cached responses are validated by Exchange if DNSSEC is enabled
+	disableCache := c.disableCache || options.DisableCache || (c.dnssec || options.DNSSEC) && isDNSSECSupported(transport)
 	if !disableCache {
-		cachedAddresses, err := c.questionCache(question, transport)
-		if err != ErrNotCached {
-			return cachedAddresses, err
+		response, ttl, state := c.loadResponse(question, transport)
+		if response != nil {
+			if state != cacheStateFresh {
+				logCachedResponse(c.logger, ctx, response, ttl, state)
+				c.refreshCache(ctx, transport, question, options, responseChecker)
+			}
+			return MessageToAddresses(response)
 		}
 	}
 	message := dns.Msg{
@@ -401,14 +516,39 @@ func (c *Client) lookupToExchange(ctx context.Context, transport adapter.DNSTran
 }
 
 func (c *Client) questionCache(question dns.Question, transport adapter.DNSTransport) ([]netip.Addr, error) {
-	response, _ := c.loadResponse(question, transport)
-	if response == nil {
+	response, _, state := c.loadResponse(question, transport)
+	if response == nil || state != cacheStateFresh {
 		return nil, ErrNotCached
 	}
 	return MessageToAddresses(response)
 }
 
-func (c *Client) loadResponse(question dns.Question, transport adapter.DNSTransport) (*dns.Msg, int) {
+// refreshCache exchanges the question in the background to replace a stale or expiring cached response.
+func (c *Client) refreshCache(ctx context.Context, transport adapter.DNSTransport, question dns.Question, options adapter.DNSQueryOptions, responseChecker func(responseAddrs []netip.Addr) bool) {
+	refreshKey := transportCacheKey{
+		Question:     question,
+		transportTag: transport.Tag(),
+	}
+	if _, loaded := c.refreshing.LoadOrStore(refreshKey, struct{}{}); loaded {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer c.refreshing.Delete(refreshKey)
+		message := dns.Msg{
+			MsgHdr: dns.MsgHdr{
+				RecursionDesired: true,
+			},
+			Question: []dns.Question{question},
+		}
+		_, err := c.exchange(ctx, transport, &message, options, responseChecker, true)
+		if err != nil && c.logger != nil {
+			c.logger.DebugContext(ctx, "refresh cache for ", FqdnToDomain(question.Name), ": ", err)
+		}
+	}()
+}
+
+func (c *Client) loadResponse(question dns.Question, transport adapter.DNSTransport) (*dns.Msg, int, cacheState) {
 	var (
 		response *dns.Msg
 		loaded   bool
@@ -423,9 +563,12 @@ func (c *Client) loadResponse(question dns.Question, transport adapter.DNSTransp
 			})
 		}
 		if !loaded {
-			return nil, 0
+			response, _, loaded = c.loadStoredResponse(question, transport)
+			if !loaded {
+				return nil, 0, cacheStateFresh
+			}
 		}
-		return response.Copy(), 0
+		return response.Copy(), 0, cacheStateFresh
 	} else {
 		var expireAt time.Time
 		if !c.independentCache {
@@ -437,10 +580,25 @@ func (c *Client) loadResponse(question dns.Question, transport adapter.DNSTransp
 			})
 		}
 		if !loaded {
-			return nil, 0
+			response, expireAt, loaded = c.loadStoredResponse(question, transport)
+			if !loaded {
+				return nil, 0, cacheStateFresh
+			}
 		}
 		timeNow := time.Now()
+		if c.serveStale {
+			expireAt = expireAt.Add(-staleMaxAge)
+		}
 		if timeNow.After(expireAt) {
+			if c.serveStale {
+				response = response.Copy()
+				for _, recordList := range [][]dns.RR{response.Answer, response.Ns, response.Extra} {
+					for _, record := range recordList {
+						record.Header().Ttl = staleTTL
+					}
+				}
+				return response, staleTTL, cacheStateStale
+			}
 			if !c.independentCache {
 				c.cache.Remove(question)
 			} else {
@@ -449,7 +607,7 @@ func (c *Client) loadResponse(question dns.Question, transport adapter.DNSTransp
 					transportTag: transport.Tag(),
 				})
 			}
-			return nil, 0
+			return nil, 0, cacheStateFresh
 		}
 		var originTTL int
 		for _, recordList := range [][]dns.RR{response.Answer, response.Ns, response.Extra} {
@@ -478,8 +636,57 @@ func (c *Client) loadResponse(question dns.Question, transport adapter.DNSTransp
 				}
 			}
 		}
-		return response, nowTTL
+		state := cacheStateFresh
+		if c.prefetch && originTTL > 0 && nowTTL*10 <= originTTL {
+			state = cacheStatePrefetch
+		}
+		return response, nowTTL, state
+	}
+}
+
+// loadStoredResponse loads a response from the persistent cache and adds it to the in-memory cache.
+func (c *Client) loadStoredResponse(question dns.Question, transport adapter.DNSTransport) (*dns.Msg, time.Time, bool) {
+	if c.cacheStore == nil {
+		return nil, time.Time{}, false
+	}
+	response, expireAt, loaded := c.cacheStore.LoadDNSCache(c.cacheStoreName(transport), question)
+	if !loaded {
+		return nil, time.Time{}, false
+	}
+	if c.disableExpire {
+		if !c.independentCache {
+			c.cache.Add(question, response)
+		} else {
+			c.transportCache.Add(transportCacheKey{
+				Question:     question,
+				transportTag: transport.Tag(),
+			}, response)
+		}
+		return response, expireAt, true
+	}
+	if c.serveStale {
+		expireAt = expireAt.Add(staleMaxAge)
+	}
+	lifetime := time.Until(expireAt)
+	if lifetime <= 0 {
+		return nil, time.Time{}, false
+	}
+	if !c.independentCache {
+		c.cache.AddWithLifetime(question, response, lifetime)
+	} else {
+		c.transportCache.AddWithLifetime(transportCacheKey{
+			Question:     question,
+			transportTag: transport.Tag(),
+		}, response, lifetime)
 	}
+	return response, expireAt, true
+}
+
+func (c *Client) cacheStoreName(transport adapter.DNSTransport) string {
+	if !c.independentCache {
+		return ""
+	}
+	return transport.Tag()
 }
 
 func MessageToAddresses(response *dns.Msg) ([]netip.Addr, error) {
@@ -494,11 +701,9 @@ func MessageToAddresses(response *dns.Msg) ([]netip.Addr, error) {
 		case *dns.AAAA:
 			addresses = append(addresses, M.AddrFromIP(answer.AAAA))
 		case *dns.HTTPS:
-			for _, value := range answer.SVCB.Value {
-				if value.Key() == dns.SVCB_IPV4HINT || value.Key() == dns.SVCB_IPV6HINT {
-					addresses = append(addresses, common.Map(strings.Split(value.String(), ","), M.ParseAddr)...)
-				}
-			}
+			addresses = append(addresses, serviceHintAddresses(&answer.SVCB)...)
+		case *dns.SVCB:
+			addresses = append(addresses, serviceHintAddresses(answer)...)
 		}
 	}
 	return addresses, nil
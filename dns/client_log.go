@@ -4,20 +4,31 @@ import (
 	"context"
 	"strings"
 
+	E "github.com/sagernet/sing/common/exceptions"
 	"github.com/sagernet/sing/common/logger"
 
 	"github.com/miekg/dns"
 )
 
-func logCachedResponse(logger logger.ContextLogger, ctx context.Context, response *dns.Msg, ttl int) {
+func logCachedResponse(logger logger.ContextLogger, ctx context.Context, response *dns.Msg, ttl int, state cacheState) {
 	if logger == nil || len(response.Question) == 0 {
 		return
 	}
+	var prefix, suffix string
+	switch state {
+	case cacheStateStale:
+		prefix = "stale "
+	case cacheStatePrefetch:
+		prefix = "cached "
+		suffix = " (prefetching)"
+	default:
+		prefix = "cached "
+	}
 	domain := FqdnToDomain(response.Question[0].Name)
-	logger.DebugContext(ctx, "cached ", domain, " ", dns.RcodeToString[response.Rcode], " ", ttl)
+	logger.DebugContext(ctx, prefix, domain, " ", dns.RcodeToString[response.Rcode], " ", ttl, suffix)
 	for _, recordList := range [][]dns.RR{response.Answer, response.Ns, response.Extra} {
 		for _, record := range recordList {
-			logger.InfoContext(ctx, "cached ", dns.Type(record.Header().Rrtype).String(), " ", FormatQuestion(record.String()))
+			logger.InfoContext(ctx, prefix, dns.Type(record.Header().Rrtype).String(), " ", FormatQuestion(record.String()))
 		}
 	}
 }
@@ -35,6 +46,19 @@ func logExchangedResponse(logger logger.ContextLogger, ctx context.Context, resp
 	}
 }
 
+func logRefreshedResponse(logger logger.ContextLogger, ctx context.Context, response *dns.Msg, ttl uint32) {
+	if logger == nil || len(response.Question) == 0 {
+		return
+	}
+	domain := FqdnToDomain(response.Question[0].Name)
+	logger.DebugContext(ctx, "refreshed ", domain, " ", dns.RcodeToString[response.Rcode], " ", ttl)
+	for _, recordList := range [][]dns.RR{response.Answer, response.Ns, response.Extra} {
+		for _, record := range recordList {
+			logger.InfoContext(ctx, "refreshed ", dns.Type(record.Header().Rrtype).String(), " ", FormatQuestion(record.String()))
+		}
+	}
+}
+
 func logRejectedResponse(logger logger.ContextLogger, ctx context.Context, response *dns.Msg) {
 	if logger == nil || len(response.Question) == 0 {
 		return
@@ -46,6 +70,13 @@ func logRejectedResponse(logger logger.ContextLogger, ctx context.Context, respo
 	}
 }
 
+func logBogusResponse(logger logger.ContextLogger, ctx context.Context, question dns.Question, err error) {
+	if logger == nil {
+		return
+	}
+	logger.WarnContext(ctx, E.Cause(err, "DNSSEC validation failed for ", FormatQuestion(question.String())))
+}
+
 func FqdnToDomain(fqdn string) string {
 	if dns.IsFqdn(fqdn) {
 		return fqdn[:len(fqdn)-1]
@@ -0,0 +1,631 @@
+package dns
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+	"github.com/sagernet/sing/contrab/freelru"
+	"github.com/sagernet/sing/contrab/maphash"
+
+	"github.com/miekg/dns"
+)
+
+// defaultTrustAnchors are the DS records of the root zone KSKs published by IANA.
+var defaultTrustAnchors = []string{
+	". IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	". IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+}
+
+const dnssecZoneCacheTTL = 10 * time.Minute
+
+// dnssecValidator validates responses by building the chain of trust from the trust anchors,
+// using the same transport the response is exchanged with.
+type dnssecValidator struct {
+	timeout      time.Duration
+	trustAnchors map[string][]*dns.DS
+	zoneCache    freelru.Cache[dnssecZoneKey, *dnssecZone]
+}
+
+type dnssecZoneKey struct {
+	name         string
+	transportTag string
+}
+
+// dnssecZone is the zone enclosing a name, with no keys if the zone is proven insecure.
+type dnssecZone struct {
+	name string
+	keys []*dns.DNSKEY
+}
+
+func (z *dnssecZone) insecure() bool {
+	return len(z.keys) == 0
+}
+
+type dnssecRRSet struct {
+	records    []dns.RR
+	signatures []*dns.RRSIG
+}
+
+// dnssecWildcard is an answer expanded from the wildcard at the closest encloser.
+type dnssecWildcard struct {
+	name            string
+	closestEncloser string
+}
+
+func newDNSSECValidator(timeout time.Duration, trustAnchors []dns.RR) *dnssecValidator {
+	if len(trustAnchors) == 0 {
+		for _, anchor := range defaultTrustAnchors {
+			trustAnchors = append(trustAnchors, common.Must1(dns.NewRR(anchor)))
+		}
+	}
+	validator := &dnssecValidator{
+		timeout:      timeout,
+		trustAnchors: make(map[string][]*dns.DS),
+		zoneCache:    common.Must1(freelru.NewSharded[dnssecZoneKey, *dnssecZone](1024, maphash.NewHasher[dnssecZoneKey]().Hash32)),
+	}
+	for _, anchor := range trustAnchors {
+		var record *dns.DS
+		switch anchor := anchor.(type) {
+		case *dns.DS:
+			record = anchor
+		case *dns.DNSKEY:
+			record = anchor.ToDS(dns.SHA256)
+		}
+		if record == nil {
+			continue
+		}
+		name := dns.CanonicalName(record.Hdr.Name)
+		validator.trustAnchors[name] = append(validator.trustAnchors[name], record)
+	}
+	return validator
+}
+
+func (v *dnssecValidator) Purge() {
+	v.zoneCache.Purge()
+}
+
+// Validate returns whether all records of the response are secure,
+// or an error if any of them is bogus.
+func (v *dnssecValidator) Validate(ctx context.Context, transport adapter.DNSTransport, response *dns.Msg) (bool, error) {
+	if response.Rcode != dns.RcodeSuccess && response.Rcode != dns.RcodeNameError {
+		return false, nil
+	}
+	question := response.Question[0]
+	answerSets := dnssecRRSets(response.Answer)
+	secure := true
+	var wildcards []dnssecWildcard
+	for _, recordSet := range answerSets {
+		if isSynthesizedCNAME(answerSets, recordSet) {
+			continue
+		}
+		recordSecure, signature, err := v.validateRRSet(ctx, transport, recordSet)
+		if err != nil {
+			return false, err
+		}
+		if !recordSecure {
+			secure = false
+			continue
+		}
+		owner := dns.CanonicalName(recordSet.records[0].Header().Name)
+		ownerLabels := dns.CountLabel(owner)
+		if strings.HasPrefix(owner, "*.") {
+			ownerLabels--
+		}
+		if int(signature.Labels) < ownerLabels {
+			labels := dns.SplitDomainName(owner)
+			wildcards = append(wildcards, dnssecWildcard{
+				name:            owner,
+				closestEncloser: dns.Fqdn(strings.Join(labels[len(labels)-int(signature.Labels):], ".")),
+			})
+		}
+	}
+	name := dnssecAnswerName(answerSets, dns.CanonicalName(question.Name), question.Qtype)
+	answered := common.Any(answerSets, func(it *dnssecRRSet) bool {
+		header := it.records[0].Header()
+		return dns.CanonicalName(header.Name) == name && (header.Rrtype == question.Qtype || question.Qtype == dns.TypeANY)
+	})
+	if !secure || answered && len(wildcards) == 0 {
+		return secure, nil
+	}
+	denial, secure, err := v.denial(ctx, transport, response.Ns)
+	if err != nil {
+		return false, err
+	}
+	if !secure {
+		return false, nil
+	}
+	for _, wildcard := range wildcards {
+		wildcardSecure, err := denial.proveWildcard(wildcard.name, wildcard.closestEncloser)
+		if err != nil {
+			return false, E.Cause(err, "validate wildcard answer for ", wildcard.name)
+		}
+		secure = secure && wildcardSecure
+	}
+	if answered {
+		return secure, nil
+	}
+	if denial.empty() {
+		zone, err := v.zone(ctx, transport, name)
+		if err != nil {
+			return false, err
+		}
+		if !zone.insecure() {
+			return false, E.New("missing denial of existence for ", name)
+		}
+		return false, nil
+	}
+	var denialSecure bool
+	if response.Rcode == dns.RcodeNameError {
+		denialSecure, err = denial.proveNameError(name)
+	} else {
+		denialSecure, err = denial.proveNoData(name, question.Qtype)
+	}
+	if err != nil {
+		return false, E.Cause(err, "validate denial of existence for ", name)
+	}
+	return secure && denialSecure, nil
+}
+
+// denial authenticates the NSEC and NSEC3 records in the authority section.
+func (v *dnssecValidator) denial(ctx context.Context, transport adapter.DNSTransport, records []dns.RR) (*dnssecDenial, bool, error) {
+	denial := new(dnssecDenial)
+	secure := true
+	for _, recordSet := range dnssecRRSets(records) {
+		switch recordSet.records[0].Header().Rrtype {
+		case dns.TypeSOA, dns.TypeNSEC, dns.TypeNSEC3:
+		default:
+			continue
+		}
+		recordSecure, _, err := v.validateRRSet(ctx, transport, recordSet)
+		if err != nil {
+			return nil, false, err
+		}
+		if !recordSecure {
+			secure = false
+			continue
+		}
+		for _, rawRecord := range recordSet.records {
+			switch record := rawRecord.(type) {
+			case *dns.NSEC:
+				denial.nsec = append(denial.nsec, record)
+			case *dns.NSEC3:
+				denial.nsec3 = append(denial.nsec3, record)
+			}
+		}
+	}
+	return denial, secure, nil
+}
+
+// validateRRSet returns whether the RRset is secure, with the signature it is validated by.
+func (v *dnssecValidator) validateRRSet(ctx context.Context, transport adapter.DNSTransport, recordSet *dnssecRRSet) (bool, *dns.RRSIG, error) {
+	header := recordSet.records[0].Header()
+	if len(recordSet.signatures) == 0 {
+		zone, err := v.zone(ctx, transport, header.Name)
+		if err != nil {
+			return false, nil, err
+		}
+		if zone.insecure() {
+			return false, nil, nil
+		}
+		return false, nil, E.New("missing signature for ", header.Name, " ", dns.TypeToString[header.Rrtype])
+	}
+	var lastErr error
+	for _, signature := range recordSet.signatures {
+		signerName := dns.CanonicalName(signature.SignerName)
+		if !dns.IsSubDomain(signerName, dns.CanonicalName(header.Name)) {
+			lastErr = E.New("signer ", signerName, " is not a parent of ", header.Name)
+			continue
+		}
+		zone, err := v.zone(ctx, transport, signerName)
+		if err != nil {
+			return false, nil, err
+		}
+		if zone.insecure() {
+			return false, nil, nil
+		}
+		if zone.name != signerName {
+			lastErr = E.New("signer ", signerName, " is not a zone")
+			continue
+		}
+		lastErr = verifyRRSet(zone.keys, signature, recordSet.records)
+		if lastErr == nil {
+			return true, signature, nil
+		}
+	}
+	return false, nil, E.Cause(lastErr, "validate ", header.Name, " ", dns.TypeToString[header.Rrtype])
+}
+
+// zone walks down from the root to find the zone enclosing the name.
+func (v *dnssecValidator) zone(ctx context.Context, transport adapter.DNSTransport, name string) (*dnssecZone, error) {
+	name = dns.CanonicalName(name)
+	cacheKey := dnssecZoneKey{name, transport.Tag()}
+	if zone, loaded := v.zoneCache.Get(cacheKey); loaded {
+		return zone, nil
+	}
+	zone, loaded := v.zoneCache.Get(dnssecZoneKey{".", transport.Tag()})
+	if !loaded {
+		rootAnchors, anchored := v.trustAnchors["."]
+		if anchored {
+			var err error
+			zone, err = v.zoneKeys(ctx, transport, ".", rootAnchors)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			zone = &dnssecZone{name: "."}
+		}
+		v.zoneCache.AddWithLifetime(dnssecZoneKey{".", transport.Tag()}, zone, dnssecZoneCacheTTL)
+	}
+	labels := dns.SplitDomainName(name)
+	for i := len(labels) - 1; i >= 0; i-- {
+		child := dns.Fqdn(strings.Join(labels[i:], "."))
+		childKey := dnssecZoneKey{child, transport.Tag()}
+		if childZone, childLoaded := v.zoneCache.Get(childKey); childLoaded {
+			zone = childZone
+			continue
+		}
+		var (
+			nonExistent bool
+			err         error
+		)
+		zone, nonExistent, err = v.delegation(ctx, transport, zone, child)
+		if err != nil {
+			return nil, err
+		}
+		v.zoneCache.AddWithLifetime(childKey, zone, dnssecZoneCacheTTL)
+		if nonExistent {
+			break
+		}
+	}
+	v.zoneCache.AddWithLifetime(cacheKey, zone, dnssecZoneCacheTTL)
+	return zone, nil
+}
+
+// delegation returns the zone enclosing the child name, and whether the child name does not exist.
+func (v *dnssecValidator) delegation(ctx context.Context, transport adapter.DNSTransport, parent *dnssecZone, child string) (*dnssecZone, bool, error) {
+	if trustAnchors, anchored := v.trustAnchors[child]; anchored {
+		zone, err := v.zoneKeys(ctx, transport, child, trustAnchors)
+		return zone, false, err
+	}
+	if parent.insecure() {
+		return parent, false, nil
+	}
+	response, err := v.exchange(ctx, transport, child, dns.TypeDS)
+	if err != nil {
+		return nil, false, err
+	}
+	var dsRecords []*dns.DS
+	for _, rawRecord := range response.Answer {
+		if record, isDS := rawRecord.(*dns.DS); isDS && dns.CanonicalName(record.Hdr.Name) == child {
+			dsRecords = append(dsRecords, record)
+		}
+	}
+	if len(dsRecords) > 0 {
+		for _, recordSet := range dnssecRRSets(response.Answer) {
+			err = verifyRRSetWithZone(parent, recordSet)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		zone, err := v.zoneKeys(ctx, transport, child, dsRecords)
+		return zone, false, err
+	}
+	recordSets := dnssecRRSets(response.Ns)
+	if len(recordSets) == 0 {
+		return nil, false, E.New("missing denial of existence for DS ", child)
+	}
+	for _, recordSet := range recordSets {
+		err = verifyRRSetWithZone(parent, recordSet)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	if response.Rcode == dns.RcodeNameError {
+		denial := new(dnssecDenial)
+		for _, rawRecord := range response.Ns {
+			switch record := rawRecord.(type) {
+			case *dns.NSEC:
+				denial.nsec = append(denial.nsec, record)
+			case *dns.NSEC3:
+				denial.nsec3 = append(denial.nsec3, record)
+			}
+		}
+		_, err = denial.proveNameError(child)
+		if err != nil {
+			return nil, false, E.Cause(err, "validate denial of existence for DS ", child)
+		}
+		return parent, true, nil
+	}
+	for _, rawRecord := range response.Ns {
+		switch record := rawRecord.(type) {
+		case *dns.NSEC:
+			if dns.CanonicalName(record.Hdr.Name) == child && isInsecureDelegation(record.TypeBitMap) {
+				return &dnssecZone{name: child}, false, nil
+			}
+		case *dns.NSEC3:
+			if record.Match(child) {
+				if isInsecureDelegation(record.TypeBitMap) {
+					return &dnssecZone{name: child}, false, nil
+				}
+			} else if record.Cover(child) && record.Flags&1 != 0 {
+				// opt-out covers unsigned delegations
+				return &dnssecZone{name: child}, false, nil
+			}
+		}
+	}
+	return parent, false, nil
+}
+
+// zoneKeys loads the DNSKEY records of the zone and authenticates them with the DS records.
+func (v *dnssecValidator) zoneKeys(ctx context.Context, transport adapter.DNSTransport, name string, dsRecords []*dns.DS) (*dnssecZone, error) {
+	dsRecords = common.Filter(dsRecords, func(it *dns.DS) bool {
+		return isSupportedAlgorithm(it.Algorithm) && isSupportedDigest(it.DigestType)
+	})
+	if len(dsRecords) == 0 {
+		return &dnssecZone{name: name}, nil
+	}
+	response, err := v.exchange(ctx, transport, name, dns.TypeDNSKEY)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		keyRecords []dns.RR
+		keys       []*dns.DNSKEY
+		signatures []*dns.RRSIG
+	)
+	for _, rawRecord := range response.Answer {
+		if dns.CanonicalName(rawRecord.Header().Name) != name {
+			continue
+		}
+		switch record := rawRecord.(type) {
+		case *dns.DNSKEY:
+			keyRecords = append(keyRecords, record)
+			if record.Flags&dns.ZONE != 0 {
+				keys = append(keys, record)
+			}
+		case *dns.RRSIG:
+			if record.TypeCovered == dns.TypeDNSKEY {
+				signatures = append(signatures, record)
+			}
+		}
+	}
+	for _, dsRecord := range dsRecords {
+		for _, key := range keys {
+			if key.KeyTag() != dsRecord.KeyTag || key.Algorithm != dsRecord.Algorithm {
+				continue
+			}
+			keyDS := key.ToDS(dsRecord.DigestType)
+			if keyDS == nil || !strings.EqualFold(keyDS.Digest, dsRecord.Digest) {
+				continue
+			}
+			for _, signature := range signatures {
+				if verifyRRSet([]*dns.DNSKEY{key}, signature, keyRecords) == nil {
+					return &dnssecZone{name: name, keys: keys}, nil
+				}
+			}
+		}
+	}
+	return nil, E.New("no valid DNSKEY for ", name)
+}
+
+func (v *dnssecValidator) exchange(ctx context.Context, transport adapter.DNSTransport, name string, qType uint16) (*dns.Msg, error) {
+	message := &dns.Msg{
+		MsgHdr: dns.MsgHdr{
+			Id:               dns.Id(),
+			RecursionDesired: true,
+		},
+		Question: []dns.Question{{
+			Name:   name,
+			Qtype:  qType,
+			Qclass: dns.ClassINET,
+		}},
+	}
+	message.SetEdns0(dns.DefaultMsgSize, true)
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	response, err := transport.Exchange(ctx, message)
+	if err != nil {
+		return nil, E.Cause(err, "query ", dns.TypeToString[qType], " for ", name)
+	}
+	if response.Rcode != dns.RcodeSuccess && response.Rcode != dns.RcodeNameError {
+		return nil, E.Cause(RCodeError(response.Rcode), "query ", dns.TypeToString[qType], " for ", name)
+	}
+	return response, nil
+}
+
+func verifyRRSetWithZone(zone *dnssecZone, recordSet *dnssecRRSet) error {
+	header := recordSet.records[0].Header()
+	var lastErr error
+	for _, signature := range recordSet.signatures {
+		if dns.CanonicalName(signature.SignerName) != zone.name {
+			continue
+		}
+		lastErr = verifyRRSet(zone.keys, signature, recordSet.records)
+		if lastErr == nil {
+			return nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = E.New("missing signature")
+	}
+	return E.Cause(lastErr, "validate ", header.Name, " ", dns.TypeToString[header.Rrtype])
+}
+
+func verifyRRSet(keys []*dns.DNSKEY, signature *dns.RRSIG, records []dns.RR) error {
+	if !signature.ValidityPeriod(time.Now()) {
+		return E.New("signature expired or not yet valid")
+	}
+	for _, key := range keys {
+		if key.KeyTag() != signature.KeyTag || key.Algorithm != signature.Algorithm {
+			continue
+		}
+		if signature.Verify(key, records) == nil {
+			return nil
+		}
+	}
+	return E.New("no valid signature")
+}
+
+// dnssecRRSets groups records into RRsets with their signatures.
+func dnssecRRSets(records []dns.RR) []*dnssecRRSet {
+	type recordSetKey struct {
+		name       string
+		recordType uint16
+		class      uint16
+	}
+	var recordSets []*dnssecRRSet
+	recordSetMap := make(map[recordSetKey]*dnssecRRSet)
+	for _, record := range records {
+		header := record.Header()
+		if header.Rrtype == dns.TypeRRSIG || header.Rrtype == dns.TypeOPT {
+			continue
+		}
+		key := recordSetKey{dns.CanonicalName(header.Name), header.Rrtype, header.Class}
+		recordSet, loaded := recordSetMap[key]
+		if !loaded {
+			recordSet = new(dnssecRRSet)
+			recordSetMap[key] = recordSet
+			recordSets = append(recordSets, recordSet)
+		}
+		recordSet.records = append(recordSet.records, record)
+	}
+	for _, record := range records {
+		signature, isSignature := record.(*dns.RRSIG)
+		if !isSignature {
+			continue
+		}
+		recordSet, loaded := recordSetMap[recordSetKey{dns.CanonicalName(signature.Hdr.Name), signature.TypeCovered, signature.Hdr.Class}]
+		if loaded {
+			recordSet.signatures = append(recordSet.signatures, signature)
+		}
+	}
+	return recordSets
+}
+
+// dnssecAnswerName follows the CNAME chain of the answer from the name.
+func dnssecAnswerName(recordSets []*dnssecRRSet, name string, qType uint16) string {
+	if qType == dns.TypeCNAME {
+		return name
+	}
+	for range recordSets {
+		var target string
+		for _, recordSet := range recordSets {
+			if record, isCNAME := recordSet.records[0].(*dns.CNAME); isCNAME && dns.CanonicalName(record.Hdr.Name) == name {
+				target = dns.CanonicalName(record.Target)
+				break
+			}
+		}
+		if target == "" {
+			break
+		}
+		name = target
+	}
+	return name
+}
+
+// isSynthesizedCNAME returns whether the CNAME RRset is unsigned and synthesized from a DNAME in the answer,
+// which is validated instead.
+func isSynthesizedCNAME(recordSets []*dnssecRRSet, recordSet *dnssecRRSet) bool {
+	cname, isCNAME := recordSet.records[0].(*dns.CNAME)
+	if !isCNAME || len(recordSet.signatures) > 0 || len(recordSet.records) > 1 {
+		return false
+	}
+	owner := dns.CanonicalName(cname.Hdr.Name)
+	return common.Any(recordSets, func(it *dnssecRRSet) bool {
+		dname, isDNAME := it.records[0].(*dns.DNAME)
+		if !isDNAME {
+			return false
+		}
+		dnameOwner := dns.CanonicalName(dname.Hdr.Name)
+		if owner == dnameOwner || !dns.IsSubDomain(dnameOwner, owner) {
+			return false
+		}
+		labels := dns.SplitDomainName(owner)
+		prefix := strings.Join(labels[:len(labels)-dns.CountLabel(dnameOwner)], ".")
+		return dns.CanonicalName(cname.Target) == dns.CanonicalName(prefix+"."+strings.TrimSuffix(dns.CanonicalName(dname.Target), "."))
+	})
+}
+
+func isInsecureDelegation(typeBitMap []uint16) bool {
+	return common.Contains(typeBitMap, dns.TypeNS) &&
+		!common.Contains(typeBitMap, dns.TypeSOA) &&
+		!common.Contains(typeBitMap, dns.TypeDS)
+}
+
+func isSupportedAlgorithm(algorithm uint8) bool {
+	switch algorithm {
+	case dns.RSASHA1, dns.RSASHA1NSEC3SHA1, dns.RSASHA256, dns.RSASHA512, dns.ECDSAP256SHA256, dns.ECDSAP384SHA384, dns.ED25519:
+		return true
+	default:
+		return false
+	}
+}
+
+func isSupportedDigest(digestType uint8) bool {
+	switch digestType {
+	case dns.SHA1, dns.SHA256, dns.SHA384:
+		return true
+	default:
+		return false
+	}
+}
+
+func isDNSSECSupported(transport adapter.DNSTransport) bool {
+	switch transport.Type() {
+	case C.DNSTypeHosts, C.DNSTypePreDefined, C.DNSTypeFakeIP:
+		return false
+	default:
+		return true
+	}
+}
+
+func isDNSSECOK(message *dns.Msg) bool {
+	optRecord := message.IsEdns0()
+	return optRecord != nil && optRecord.Do()
+}
+
+// SetDNSSECOK sets the DO bit of the message to request DNSSEC records.
+func SetDNSSECOK(message *dns.Msg) *dns.Msg {
+	message = message.Copy()
+	optRecord := message.IsEdns0()
+	if optRecord == nil {
+		message.SetEdns0(dns.DefaultMsgSize, true)
+		return message
+	}
+	if optRecord.UDPSize() < dns.DefaultMsgSize {
+		optRecord.SetUDPSize(dns.DefaultMsgSize)
+	}
+	optRecord.SetDo()
+	return message
+}
+
+// dnssecResponse sets the AD bit for clients that ask for it,
+// and removes DNSSEC records for clients that did not set the DO bit.
+func dnssecResponse(request *dns.Msg, response *dns.Msg, secure bool) *dns.Msg {
+	response = response.Copy()
+	dnssecOK := isDNSSECOK(request)
+	response.AuthenticatedData = secure && (dnssecOK || request.AuthenticatedData)
+	if !dnssecOK {
+		qType := request.Question[0].Qtype
+		isDNSSECRecord := func(it dns.RR) bool {
+			switch recordType := it.Header().Rrtype; recordType {
+			case dns.TypeRRSIG, dns.TypeNSEC, dns.TypeNSEC3:
+				return recordType != qType
+			default:
+				return false
+			}
+		}
+		response.Answer = common.Filter(response.Answer, func(it dns.RR) bool {
+			return !isDNSSECRecord(it)
+		})
+		response.Ns = common.Filter(response.Ns, func(it dns.RR) bool {
+			return !isDNSSECRecord(it)
+		})
+	}
+	return response
+}
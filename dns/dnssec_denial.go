@@ -0,0 +1,245 @@
+package dns
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+
+	"github.com/miekg/dns"
+)
+
+// dnssecMaxNSEC3Iterations is the NSEC3 iteration count above which proofs are treated as insecure, see RFC 9276.
+const dnssecMaxNSEC3Iterations = 150
+
+const dnssecNSEC3OptOut = 1
+
+// dnssecDenial proves the nonexistence of names and types with the authenticated NSEC or NSEC3 records of a response,
+// as in RFC 4035 section 5.4 and RFC 5155 section 8.
+//
+// Proofs return false without an error if they are insecure because of NSEC3 opt-out or parameters.
+type dnssecDenial struct {
+	nsec  []*dns.NSEC
+	nsec3 []*dns.NSEC3
+}
+
+func (d *dnssecDenial) empty() bool {
+	return len(d.nsec) == 0 && len(d.nsec3) == 0
+}
+
+// proveNameError proves that the name does not exist, and that no wildcard could have matched it.
+func (d *dnssecDenial) proveNameError(name string) (bool, error) {
+	if len(d.nsec) > 0 {
+		closestEncloser, covered := d.nsecClosestEncloser(name)
+		if !covered {
+			return false, E.New("missing NSEC covering ", name)
+		}
+		if !d.nsecCovered("*." + closestEncloser) {
+			return false, E.New("missing NSEC covering wildcard at ", closestEncloser)
+		}
+		return true, nil
+	}
+	if !d.nsec3Usable() {
+		return false, nil
+	}
+	if d.nsec3Match(name) != nil {
+		return false, E.New("NSEC3 proves ", name, " exists")
+	}
+	closestEncloser, nextCloser, err := d.nsec3ClosestEncloser(name)
+	if err != nil {
+		return false, err
+	}
+	if d.nsec3Cover("*."+closestEncloser) == nil {
+		return false, E.New("missing NSEC3 covering wildcard at ", closestEncloser)
+	}
+	// with opt-out, the name may be an unsigned delegation
+	return nextCloser.Flags&dnssecNSEC3OptOut == 0, nil
+}
+
+// proveNoData proves that the name exists, or is matched by a wildcard, but has no records of the type.
+func (d *dnssecDenial) proveNoData(name string, qType uint16) (bool, error) {
+	if len(d.nsec) > 0 {
+		if record := d.nsecAt(name); record != nil {
+			return true, dnssecTypeAbsent(record.TypeBitMap, name, qType)
+		}
+		for _, record := range d.nsec {
+			// the next name is below the name, which is an empty non-terminal
+			if nsecCovers(record, name) && dnssecCompareName(name, record.NextDomain) < 0 && dns.IsSubDomain(name, dns.CanonicalName(record.NextDomain)) {
+				return true, nil
+			}
+		}
+		closestEncloser, covered := d.nsecClosestEncloser(name)
+		if covered {
+			if record := d.nsecAt("*." + closestEncloser); record != nil {
+				return true, dnssecTypeAbsent(record.TypeBitMap, "*."+closestEncloser, qType)
+			}
+		}
+		return false, E.New("missing NSEC proving no ", dns.TypeToString[qType], " for ", name)
+	}
+	if !d.nsec3Usable() {
+		return false, nil
+	}
+	if record := d.nsec3Match(name); record != nil {
+		return true, dnssecTypeAbsent(record.TypeBitMap, name, qType)
+	}
+	closestEncloser, nextCloser, err := d.nsec3ClosestEncloser(name)
+	if err != nil {
+		return false, err
+	}
+	if qType == dns.TypeDS && nextCloser.Flags&dnssecNSEC3OptOut != 0 {
+		// an unsigned delegation in an opt-out span
+		return false, nil
+	}
+	if record := d.nsec3Match("*." + closestEncloser); record != nil {
+		return true, dnssecTypeAbsent(record.TypeBitMap, "*."+closestEncloser, qType)
+	}
+	return false, E.New("missing NSEC3 proving no ", dns.TypeToString[qType], " for ", name)
+}
+
+// proveWildcard proves that the name, answered by the wildcard at the closest encloser, does not exist.
+func (d *dnssecDenial) proveWildcard(name string, closestEncloser string) (bool, error) {
+	if len(d.nsec) > 0 {
+		if !d.nsecCovered(name) {
+			return false, E.New("missing NSEC covering ", name)
+		}
+		return true, nil
+	}
+	if !d.nsec3Usable() {
+		if d.empty() {
+			return false, E.New("missing denial of existence for ", name)
+		}
+		return false, nil
+	}
+	labels := dns.SplitDomainName(name)
+	nextCloser := dns.Fqdn(strings.Join(labels[len(labels)-dns.CountLabel(closestEncloser)-1:], "."))
+	record := d.nsec3Cover(nextCloser)
+	if record == nil {
+		return false, E.New("missing NSEC3 covering ", nextCloser)
+	}
+	return record.Flags&dnssecNSEC3OptOut == 0, nil
+}
+
+func (d *dnssecDenial) nsecAt(name string) *dns.NSEC {
+	return common.Find(d.nsec, func(it *dns.NSEC) bool {
+		return dns.CanonicalName(it.Hdr.Name) == name
+	})
+}
+
+func (d *dnssecDenial) nsecCovered(name string) bool {
+	return common.Any(d.nsec, func(it *dns.NSEC) bool {
+		return nsecCovers(it, name)
+	})
+}
+
+// nsecClosestEncloser returns the closest encloser of the name from the NSEC covering it.
+func (d *dnssecDenial) nsecClosestEncloser(name string) (string, bool) {
+	for _, record := range d.nsec {
+		if !nsecCovers(record, name) {
+			continue
+		}
+		labels := dns.SplitDomainName(name)
+		commonLabels := max(dns.CompareDomainName(name, record.Hdr.Name), dns.CompareDomainName(name, record.NextDomain))
+		return dns.Fqdn(strings.Join(labels[len(labels)-commonLabels:], ".")), true
+	}
+	return "", false
+}
+
+// nsec3Usable returns false if any NSEC3 record has an unknown hash algorithm or too many iterations.
+func (d *dnssecDenial) nsec3Usable() bool {
+	return len(d.nsec3) > 0 && common.All(d.nsec3, func(it *dns.NSEC3) bool {
+		return it.Hash == dns.SHA1 && it.Iterations <= dnssecMaxNSEC3Iterations
+	})
+}
+
+func (d *dnssecDenial) nsec3Match(name string) *dns.NSEC3 {
+	return common.Find(d.nsec3, func(it *dns.NSEC3) bool {
+		return it.Match(name)
+	})
+}
+
+func (d *dnssecDenial) nsec3Cover(name string) *dns.NSEC3 {
+	return common.Find(d.nsec3, func(it *dns.NSEC3) bool {
+		// Cover also reports names with the owner hash
+		return it.Cover(name) && !it.Match(name)
+	})
+}
+
+// nsec3ClosestEncloser returns the closest provable encloser of the name,
+// and the NSEC3 covering the next closer name, see RFC 5155 section 8.3.
+func (d *dnssecDenial) nsec3ClosestEncloser(name string) (string, *dns.NSEC3, error) {
+	labels := dns.SplitDomainName(name)
+	for i := 1; i <= len(labels); i++ {
+		closestEncloser := dns.Fqdn(strings.Join(labels[i:], "."))
+		record := d.nsec3Match(closestEncloser)
+		if record == nil {
+			continue
+		}
+		if common.Contains(record.TypeBitMap, dns.TypeDNAME) {
+			return "", nil, E.New("closest encloser ", closestEncloser, " is a DNAME")
+		}
+		if isDelegation(record.TypeBitMap) {
+			return "", nil, E.New("closest encloser ", closestEncloser, " is a delegation")
+		}
+		nextCloser := dns.Fqdn(strings.Join(labels[i-1:], "."))
+		nextCloserRecord := d.nsec3Cover(nextCloser)
+		if nextCloserRecord == nil {
+			return "", nil, E.New("missing NSEC3 covering ", nextCloser)
+		}
+		return closestEncloser, nextCloserRecord, nil
+	}
+	return "", nil, E.New("missing closest encloser proof for ", name)
+}
+
+// nsecCovers returns whether the name is between the owner and the next name of the NSEC.
+func nsecCovers(record *dns.NSEC, name string) bool {
+	owner := dns.CanonicalName(record.Hdr.Name)
+	next := dns.CanonicalName(record.NextDomain)
+	if owner == name {
+		return false
+	}
+	// names below a delegation or a DNAME are not in the zone of the NSEC
+	if dns.IsSubDomain(owner, name) && (isDelegation(record.TypeBitMap) || common.Contains(record.TypeBitMap, dns.TypeDNAME)) {
+		return false
+	}
+	if dnssecCompareName(owner, next) < 0 {
+		return dnssecCompareName(owner, name) < 0 && dnssecCompareName(name, next) < 0
+	}
+	// the last NSEC of the zone, with the apex as the next name
+	return dnssecCompareName(owner, name) < 0 && dns.IsSubDomain(next, name)
+}
+
+// dnssecTypeAbsent checks the type bitmap of the NSEC or NSEC3 matching the name.
+func dnssecTypeAbsent(typeBitMap []uint16, name string, qType uint16) error {
+	if common.Contains(typeBitMap, qType) {
+		return E.New("NSEC proves ", dns.TypeToString[qType], " exists for ", name)
+	}
+	if qType != dns.TypeCNAME && common.Contains(typeBitMap, dns.TypeCNAME) {
+		return E.New("NSEC proves ", name, " is an alias")
+	}
+	if qType == dns.TypeDS {
+		if common.Contains(typeBitMap, dns.TypeSOA) && name != "." {
+			return E.New("NSEC from the child zone can not prove no DS for ", name)
+		}
+	} else if isDelegation(typeBitMap) {
+		return E.New("NSEC from the parent zone can not prove no ", dns.TypeToString[qType], " for ", name)
+	}
+	return nil
+}
+
+func isDelegation(typeBitMap []uint16) bool {
+	return common.Contains(typeBitMap, dns.TypeNS) && !common.Contains(typeBitMap, dns.TypeSOA)
+}
+
+// dnssecCompareName compares names in the canonical order of RFC 4034 section 6.1.
+func dnssecCompareName(a string, b string) int {
+	aLabels := dns.SplitDomainName(strings.ToLower(a))
+	bLabels := dns.SplitDomainName(strings.ToLower(b))
+	for i := 1; i <= len(aLabels) && i <= len(bLabels); i++ {
+		result := strings.Compare(aLabels[len(aLabels)-i], bLabels[len(bLabels)-i])
+		if result != 0 {
+			return result
+		}
+	}
+	return cmp.Compare(len(aLabels), len(bLabels))
+}
@@ -0,0 +1,422 @@
+package dns
+
+import (
+	"context"
+	"crypto"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing/common"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+type dnssecTestZone struct {
+	origin     string
+	key        *dns.DNSKEY
+	privateKey crypto.Signer
+	records    map[string]map[uint16][]dns.RR
+	names      []string
+	nsec       []*dns.NSEC
+	nsec3      []*dns.NSEC3
+}
+
+func newDNSSECTestZone(t *testing.T, nsec3 bool, optOut bool, iterations uint16) *dnssecTestZone {
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: "example.", Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     dns.ZONE | dns.SEP,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	privateKey, err := key.Generate(256)
+	require.NoError(t, err)
+	zone := &dnssecTestZone{
+		origin:     "example.",
+		key:        key,
+		privateKey: privateKey.(crypto.Signer),
+		records:    make(map[string]map[uint16][]dns.RR),
+	}
+	for _, record := range []string{
+		"example. 3600 IN SOA ns.example. hostmaster.example. 1 3600 600 86400 300",
+		"example. 3600 IN NS ns.example.",
+		"ns.example. 3600 IN A 192.0.2.1",
+		"www.example. 3600 IN A 192.0.2.2",
+		"alias.example. 3600 IN CNAME www.example.",
+		"a.b.example. 3600 IN TXT \"empty non-terminal\"",
+		"wild.example. 3600 IN TXT \"wildcard parent\"",
+		"*.wild.example. 3600 IN A 192.0.2.3",
+		"insecure.example. 3600 IN NS ns.insecure.example.",
+	} {
+		zone.add(common.Must1(dns.NewRR(record)))
+	}
+	zone.add(key)
+	for name := range zone.records {
+		for ; name != zone.origin; name = parentName(name) {
+			if !slices.Contains(zone.names, name) {
+				zone.names = append(zone.names, name)
+			}
+		}
+	}
+	zone.names = append(zone.names, zone.origin)
+	slices.SortFunc(zone.names, dnssecCompareName)
+	if nsec3 {
+		zone.buildNSEC3(optOut, iterations)
+	} else {
+		zone.buildNSEC()
+	}
+	return zone
+}
+
+func (z *dnssecTestZone) add(record dns.RR) {
+	header := record.Header()
+	name := dns.CanonicalName(header.Name)
+	if z.records[name] == nil {
+		z.records[name] = make(map[uint16][]dns.RR)
+	}
+	z.records[name][header.Rrtype] = append(z.records[name][header.Rrtype], record)
+}
+
+func (z *dnssecTestZone) types(name string, signed bool) []uint16 {
+	var types []uint16
+	for recordType := range z.records[name] {
+		types = append(types, recordType)
+	}
+	if signed && len(types) > 0 && !z.isDelegation(name) {
+		types = append(types, dns.TypeRRSIG)
+	}
+	return types
+}
+
+func (z *dnssecTestZone) isDelegation(name string) bool {
+	return name != z.origin && len(z.records[name][dns.TypeNS]) > 0
+}
+
+func (z *dnssecTestZone) buildNSEC() {
+	owners := common.Filter(z.names, func(it string) bool {
+		return len(z.records[it]) > 0
+	})
+	for i, owner := range owners {
+		typeBitMap := append(z.types(owner, true), dns.TypeNSEC)
+		if z.isDelegation(owner) {
+			typeBitMap = append(typeBitMap, dns.TypeRRSIG)
+		}
+		slices.Sort(typeBitMap)
+		z.nsec = append(z.nsec, &dns.NSEC{
+			Hdr:        dns.RR_Header{Name: owner, Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: 300},
+			NextDomain: owners[(i+1)%len(owners)],
+			TypeBitMap: typeBitMap,
+		})
+	}
+}
+
+func (z *dnssecTestZone) buildNSEC3(optOut bool, iterations uint16) {
+	type hashedName struct {
+		hash string
+		name string
+	}
+	var hashedNames []hashedName
+	for _, name := range z.names {
+		if optOut && z.isDelegation(name) {
+			continue
+		}
+		hashedNames = append(hashedNames, hashedName{dns.HashName(name, dns.SHA1, iterations, ""), name})
+	}
+	slices.SortFunc(hashedNames, func(a, b hashedName) int {
+		return strings.Compare(a.hash, b.hash)
+	})
+	var flags uint8
+	if optOut {
+		flags = dnssecNSEC3OptOut
+	}
+	for i, hashed := range hashedNames {
+		typeBitMap := z.types(hashed.name, true)
+		slices.Sort(typeBitMap)
+		z.nsec3 = append(z.nsec3, &dns.NSEC3{
+			Hdr:        dns.RR_Header{Name: strings.ToLower(hashed.hash) + "." + z.origin, Rrtype: dns.TypeNSEC3, Class: dns.ClassINET, Ttl: 300},
+			Hash:       dns.SHA1,
+			Flags:      flags,
+			Iterations: iterations,
+			HashLength: 20,
+			NextDomain: hashedNames[(i+1)%len(hashedNames)].hash,
+			TypeBitMap: typeBitMap,
+		})
+	}
+}
+
+func (z *dnssecTestZone) sign(records []dns.RR) []dns.RR {
+	signature := &dns.RRSIG{
+		Hdr:        dns.RR_Header{Ttl: records[0].Header().Ttl},
+		KeyTag:     z.key.KeyTag(),
+		SignerName: z.origin,
+		Algorithm:  z.key.Algorithm,
+		Inception:  uint32(time.Now().Add(-time.Hour).Unix()),
+		Expiration: uint32(time.Now().Add(time.Hour).Unix()),
+	}
+	common.Must(signature.Sign(z.privateKey, records))
+	return append(slices.Clone(records), signature)
+}
+
+func (z *dnssecTestZone) trustAnchor() dns.RR {
+	return z.key.ToDS(dns.SHA256)
+}
+
+func (z *dnssecTestZone) exists(name string) bool {
+	return slices.Contains(z.names, name)
+}
+
+func (z *dnssecTestZone) closestEncloser(name string) string {
+	for name = parentName(name); !z.exists(name); name = parentName(name) {
+	}
+	return name
+}
+
+func (z *dnssecTestZone) Exchange(ctx context.Context, message *dns.Msg) (*dns.Msg, error) {
+	question := message.Question[0]
+	name := dns.CanonicalName(question.Name)
+	response := &dns.Msg{
+		MsgHdr:   dns.MsgHdr{Id: message.Id, Response: true, Authoritative: true},
+		Question: []dns.Question{question},
+	}
+	soa := z.sign(z.records[z.origin][dns.TypeSOA])
+	if z.exists(name) {
+		if records := z.records[name][question.Qtype]; len(records) > 0 {
+			response.Answer = z.sign(records)
+			return response, nil
+		}
+		if records := z.records[name][dns.TypeCNAME]; len(records) > 0 {
+			response.Answer = z.sign(records)
+			target := dns.CanonicalName(records[0].(*dns.CNAME).Target)
+			if records = z.records[target][question.Qtype]; len(records) > 0 {
+				response.Answer = append(response.Answer, z.sign(records)...)
+			}
+			return response, nil
+		}
+		response.Ns = append(soa, z.proveNoData(name)...)
+		return response, nil
+	}
+	closestEncloser := z.closestEncloser(name)
+	wildcard := "*." + closestEncloser
+	if z.exists(wildcard) {
+		if records := z.records[wildcard][question.Qtype]; len(records) > 0 {
+			for _, record := range z.sign(records) {
+				record = dns.Copy(record)
+				record.Header().Name = name
+				response.Answer = append(response.Answer, record)
+			}
+			response.Ns = z.proveWildcard(name, closestEncloser)
+			return response, nil
+		}
+		response.Ns = append(soa, z.proveWildcard(name, closestEncloser)...)
+		response.Ns = append(response.Ns, z.proveNoData(wildcard)...)
+		return response, nil
+	}
+	response.Rcode = dns.RcodeNameError
+	response.Ns = append(soa, z.proveWildcard(name, closestEncloser)...)
+	if z.nsec3 != nil {
+		response.Ns = append(response.Ns, z.signProof(z.nsec3Cover(wildcard))...)
+	} else {
+		response.Ns = append(response.Ns, z.signProof(z.nsecCover(wildcard))...)
+	}
+	return response, nil
+}
+
+func (z *dnssecTestZone) proveNoData(name string) []dns.RR {
+	if z.nsec3 != nil {
+		if record := z.nsec3Match(name); record != nil {
+			return z.signProof(record)
+		}
+		// an unsigned delegation in an opt-out span
+		return z.proveWildcard(name, parentName(name))
+	}
+	if record := common.Find(z.nsec, func(it *dns.NSEC) bool { return it.Hdr.Name == name }); record != nil {
+		return z.signProof(record)
+	}
+	return z.signProof(z.nsecCover(name))
+}
+
+// proveWildcard proves that the name does not exist below the closest encloser.
+func (z *dnssecTestZone) proveWildcard(name string, closestEncloser string) []dns.RR {
+	if z.nsec3 == nil {
+		return z.signProof(z.nsecCover(name))
+	}
+	labels := dns.SplitDomainName(name)
+	nextCloser := dns.Fqdn(strings.Join(labels[len(labels)-dns.CountLabel(closestEncloser)-1:], "."))
+	return z.signProof(z.nsec3Match(closestEncloser), z.nsec3Cover(nextCloser))
+}
+
+func (z *dnssecTestZone) nsecCover(name string) *dns.NSEC {
+	return common.Find(z.nsec, func(it *dns.NSEC) bool { return nsecCovers(it, name) })
+}
+
+func (z *dnssecTestZone) nsec3Match(name string) *dns.NSEC3 {
+	return common.Find(z.nsec3, func(it *dns.NSEC3) bool { return it.Match(name) })
+}
+
+func (z *dnssecTestZone) nsec3Cover(name string) *dns.NSEC3 {
+	return common.Find(z.nsec3, func(it *dns.NSEC3) bool { return it.Cover(name) && !it.Match(name) })
+}
+
+func (z *dnssecTestZone) signProof(records ...dns.RR) []dns.RR {
+	var proof []dns.RR
+	for _, record := range records {
+		if record == nil || common.Any(proof, func(it dns.RR) bool { return it == record }) {
+			continue
+		}
+		proof = append(proof, z.sign([]dns.RR{record})...)
+	}
+	return proof
+}
+
+func parentName(name string) string {
+	labels := dns.SplitDomainName(name)
+	return dns.Fqdn(strings.Join(labels[1:], "."))
+}
+
+type dnssecTestTransport struct {
+	adapter.DNSTransport
+	zone *dnssecTestZone
+}
+
+func (t *dnssecTestTransport) Type() string {
+	return "test"
+}
+
+func (t *dnssecTestTransport) Tag() string {
+	return "test"
+}
+
+func (t *dnssecTestTransport) Exchange(ctx context.Context, message *dns.Msg) (*dns.Msg, error) {
+	return t.zone.Exchange(ctx, message)
+}
+
+func TestDNSSEC(t *testing.T) {
+	t.Parallel()
+	for _, testCase := range []struct {
+		name       string
+		nsec3      bool
+		optOut     bool
+		iterations uint16
+	}{
+		{name: "NSEC"},
+		{name: "NSEC3", nsec3: true},
+		{name: "NSEC3 opt-out", nsec3: true, optOut: true},
+		{name: "NSEC3 iterations", nsec3: true, iterations: dnssecMaxNSEC3Iterations + 1},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			zone := newDNSSECTestZone(t, testCase.nsec3, testCase.optOut, testCase.iterations)
+			transport := &dnssecTestTransport{zone: zone}
+			validator := newDNSSECValidator(time.Second, []dns.RR{zone.trustAnchor()})
+			exchange := func(name string, qType uint16) *dns.Msg {
+				response, err := zone.Exchange(context.Background(), new(dns.Msg).SetQuestion(name, qType))
+				require.NoError(t, err)
+				return response
+			}
+			validate := func(response *dns.Msg) (bool, error) {
+				validator.Purge()
+				return validator.Validate(context.Background(), transport, response)
+			}
+			requireSecure := func(response *dns.Msg, expected bool) {
+				secure, err := validate(response)
+				require.NoError(t, err)
+				require.Equal(t, expected, secure)
+			}
+			requireBogus := func(response *dns.Msg) {
+				_, err := validate(response)
+				require.Error(t, err)
+			}
+			denialSecure := !testCase.optOut && testCase.iterations <= dnssecMaxNSEC3Iterations
+			noDataSecure := testCase.iterations <= dnssecMaxNSEC3Iterations
+
+			t.Run("signed", func(t *testing.T) {
+				requireSecure(exchange("www.example.", dns.TypeA), true)
+				requireSecure(exchange("alias.example.", dns.TypeA), true)
+			})
+			t.Run("bogus", func(t *testing.T) {
+				response := exchange("www.example.", dns.TypeA)
+				response.Answer[0].(*dns.A).A[3]++
+				requireBogus(response)
+
+				response = exchange("www.example.", dns.TypeA)
+				response.Answer = common.Filter(response.Answer, func(it dns.RR) bool {
+					return it.Header().Rrtype != dns.TypeRRSIG
+				})
+				requireBogus(response)
+			})
+			t.Run("NXDOMAIN", func(t *testing.T) {
+				response := exchange("nx.example.", dns.TypeA)
+				require.Equal(t, dns.RcodeNameError, response.Rcode)
+				requireSecure(response, denialSecure)
+				requireSecure(exchange("nx.a.b.example.", dns.TypeA), denialSecure)
+			})
+			t.Run("NXDOMAIN without proof", func(t *testing.T) {
+				response := exchange("nx.example.", dns.TypeA)
+				response.Ns = response.Ns[:2]
+				requireBogus(response)
+			})
+			t.Run("NXDOMAIN without wildcard proof", func(t *testing.T) {
+				if !denialSecure {
+					t.Skip("insecure")
+				}
+				response := exchange("nx.example.", dns.TypeA)
+				wildcardProof := common.Find(response.Ns, func(it dns.RR) bool {
+					switch record := it.(type) {
+					case *dns.NSEC:
+						return nsecCovers(record, "*.example.")
+					case *dns.NSEC3:
+						return record.Cover("*.example.") && !record.Match("*.example.")
+					}
+					return false
+				})
+				require.NotNil(t, wildcardProof)
+				response.Ns = common.Filter(response.Ns, func(it dns.RR) bool {
+					return it.Header().Name != wildcardProof.Header().Name
+				})
+				requireBogus(response)
+			})
+			t.Run("NXDOMAIN for existing name", func(t *testing.T) {
+				if !noDataSecure {
+					t.Skip("insecure")
+				}
+				response := exchange("nx.example.", dns.TypeA)
+				response.Question[0].Name = "www.example."
+				requireBogus(response)
+			})
+			t.Run("NODATA", func(t *testing.T) {
+				requireSecure(exchange("www.example.", dns.TypeTXT), noDataSecure)
+				requireSecure(exchange("b.example.", dns.TypeA), noDataSecure)
+			})
+			t.Run("NODATA for existing type", func(t *testing.T) {
+				if !noDataSecure {
+					t.Skip("insecure")
+				}
+				response := exchange("www.example.", dns.TypeTXT)
+				response.Question[0].Qtype = dns.TypeA
+				requireBogus(response)
+			})
+			t.Run("wildcard", func(t *testing.T) {
+				response := exchange("host.wild.example.", dns.TypeA)
+				require.Equal(t, "host.wild.example.", response.Answer[0].Header().Name)
+				requireSecure(response, denialSecure)
+			})
+			t.Run("wildcard without proof", func(t *testing.T) {
+				response := exchange("host.wild.example.", dns.TypeA)
+				response.Ns = nil
+				requireBogus(response)
+			})
+			t.Run("wildcard NODATA", func(t *testing.T) {
+				requireSecure(exchange("host.wild.example.", dns.TypeTXT), noDataSecure)
+			})
+			t.Run("insecure delegation", func(t *testing.T) {
+				response := new(dns.Msg).SetQuestion("www.insecure.example.", dns.TypeA)
+				response.Response = true
+				response.Answer = []dns.RR{common.Must1(dns.NewRR("www.insecure.example. 300 IN A 192.0.2.4"))}
+				requireSecure(response, false)
+			})
+		})
+	}
+}
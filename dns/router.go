@@ -39,6 +39,10 @@ type Router struct {
 	defaultDomainStrategy C.DomainStrategy
 	dnsReverseMapping     freelru.Cache[netip.Addr, string]
 	platformInterface     platform.Interface
+	tracer                adapter.Tracer
+	queryRecorder         adapter.DNSQueryRecorder
+	fakeIPEnabled         bool
+	dnssecEnabled         bool
 }
 
 func NewRouter(ctx context.Context, logFactory log.Factory, options option.DNSOptions) *Router {
@@ -49,11 +53,16 @@ func NewRouter(ctx context.Context, logFactory log.Factory, options option.DNSOp
 		outbound:              service.FromContext[adapter.OutboundManager](ctx),
 		rules:                 make([]adapter.DNSRule, 0, len(options.Rules)),
 		defaultDomainStrategy: C.DomainStrategy(options.Strategy),
+		dnssecEnabled:         options.DNSSEC,
 	}
 	router.client = NewClient(ClientOptions{
 		DisableCache:     options.DNSClientOptions.DisableCache,
 		DisableExpire:    options.DNSClientOptions.DisableExpire,
 		IndependentCache: options.DNSClientOptions.IndependentCache,
+		ServeStale:       options.DNSClientOptions.ServeStale,
+		Prefetch:         options.DNSClientOptions.Prefetch,
+		DNSSEC:           options.DNSClientOptions.DNSSEC,
+		TrustAnchors:     common.Map(options.DNSClientOptions.DNSSECTrustAnchors, dnsTrustAnchorRecord),
 		CacheCapacity:    options.DNSClientOptions.CacheCapacity,
 		RDRC: func() adapter.RDRCStore {
 			cacheFile := service.FromContext[adapter.CacheFile](ctx)
@@ -65,6 +74,16 @@ func NewRouter(ctx context.Context, logFactory log.Factory, options option.DNSOp
 			}
 			return cacheFile
 		},
+		CacheStore: func() adapter.DNSCacheStore {
+			cacheFile := service.FromContext[adapter.CacheFile](ctx)
+			if cacheFile == nil {
+				return nil
+			}
+			if !cacheFile.StoreDNSCache() {
+				return nil
+			}
+			return cacheFile
+		},
 		Logger: router.logger,
 	})
 	if options.ReverseMapping {
@@ -73,6 +92,10 @@ func NewRouter(ctx context.Context, logFactory log.Factory, options option.DNSOp
 	return router
 }
 
+func dnsTrustAnchorRecord(anchor option.DNSTrustAnchorOptions) mDNS.RR {
+	return anchor.RR
+}
+
 func (r *Router) Initialize(rules []option.DNSRule) error {
 	for i, ruleOptions := range rules {
 		dnsRule, err := R.NewDNSRule(r.ctx, r.logger, ruleOptions, true)
@@ -80,6 +103,12 @@ func (r *Router) Initialize(rules []option.DNSRule) error {
 			return E.Cause(err, "parse dns rule[", i, "]")
 		}
 		r.rules = append(r.rules, dnsRule)
+		switch action := dnsRule.Action().(type) {
+		case *R.RuleActionDNSRoute:
+			r.dnssecEnabled = r.dnssecEnabled || action.DNSSEC
+		case *R.RuleActionDNSRouteOptions:
+			r.dnssecEnabled = r.dnssecEnabled || action.DNSSEC
+		}
 	}
 	return nil
 }
@@ -88,6 +117,11 @@ func (r *Router) Start(stage adapter.StartStage) error {
 	monitor := taskmonitor.New(r.logger, C.StartTimeout)
 	switch stage {
 	case adapter.StartStateStart:
+		r.tracer = service.FromContext[adapter.Tracer](r.ctx)
+		r.queryRecorder = service.FromContext[adapter.DNSQueryRecorder](r.ctx)
+		r.fakeIPEnabled = common.Any(r.transport.Transports(), func(it adapter.DNSTransport) bool {
+			return it.Type() == C.DNSTypeFakeIP
+		})
 		monitor.Start("initialize DNS client")
 		r.client.Start()
 		monitor.Finish()
@@ -154,6 +188,10 @@ func (r *Router) matchDNS(ctx context.Context, allowFakeIP bool, ruleIndex int,
 				if isFakeIP && !allowFakeIP {
 					continue
 				}
+				if isFakeIP && transport.(adapter.FakeIPTransport).Excluded(metadata.Domain) {
+					r.logger.DebugContext(ctx, "domain excluded by fake_ip_filter of ", transport.Tag())
+					continue
+				}
 				if action.Strategy != C.DomainStrategyAsIS {
 					options.Strategy = action.Strategy
 				}
@@ -166,6 +204,9 @@ func (r *Router) matchDNS(ctx context.Context, allowFakeIP bool, ruleIndex int,
 				if action.ClientSubnet.IsValid() {
 					options.ClientSubnet = action.ClientSubnet
 				}
+				if action.DNSSEC {
+					options.DNSSEC = true
+				}
 				if legacyTransport, isLegacy := transport.(adapter.LegacyDNSTransport); isLegacy {
 					if options.Strategy == C.DomainStrategyAsIS {
 						options.Strategy = legacyTransport.LegacyStrategy()
@@ -188,8 +229,15 @@ func (r *Router) matchDNS(ctx context.Context, allowFakeIP bool, ruleIndex int,
 				if action.ClientSubnet.IsValid() {
 					options.ClientSubnet = action.ClientSubnet
 				}
+				if action.DNSSEC {
+					options.DNSSEC = true
+				}
+			case *R.RuleActionDNSRewrite:
+				options.RewriteName = action.QueryName
 			case *R.RuleActionReject:
 				return nil, currentRule, currentRuleIndex
+			case *R.RuleActionPredefined:
+				return nil, currentRule, currentRuleIndex
 			}
 		}
 	}
@@ -214,7 +262,16 @@ func (r *Router) Exchange(ctx context.Context, message *mDNS.Msg, options adapte
 		transport adapter.DNSTransport
 		err       error
 	)
-	response, cached := r.client.ExchangeCache(ctx, message)
+	startedAt := time.Now()
+	var (
+		response *mDNS.Msg
+		cached   bool
+		rule     adapter.DNSRule
+	)
+	if !r.dnssecEnabled {
+		// cached responses are validated by the client if DNSSEC is enabled
+		response, cached = r.client.ExchangeCache(ctx, message)
+	}
 	if !cached {
 		var metadata *adapter.InboundContext
 		ctx, metadata = adapter.ExtendContext(ctx)
@@ -242,11 +299,7 @@ func (r *Router) Exchange(ctx context.Context, message *mDNS.Msg, options adapte
 			}
 			response, err = r.client.Exchange(ctx, transport, message, options, nil)
 		} else {
-			var (
-				rule      adapter.DNSRule
-				ruleIndex int
-			)
-			ruleIndex = -1
+			ruleIndex := -1
 			for {
 				dnsCtx := adapter.OverrideContext(ctx)
 				dnsOptions := options
@@ -256,10 +309,17 @@ func (r *Router) Exchange(ctx context.Context, message *mDNS.Msg, options adapte
 					case *R.RuleActionReject:
 						switch action.Method {
 						case C.RuleActionRejectMethodDefault:
-							return FixedResponse(message.Id, message.Question[0], nil, 0), nil
+							response = FixedResponse(message.Id, message.Question[0], nil, 0)
+							r.recordExchange(ctx, message.Question[0], rule, nil, response, false, time.Since(startedAt), nil)
+							return response, nil
 						case C.RuleActionRejectMethodDrop:
+							r.recordExchange(ctx, message.Question[0], rule, nil, nil, false, time.Since(startedAt), tun.ErrDrop)
 							return nil, tun.ErrDrop
 						}
+					case *R.RuleActionPredefined:
+						response = action.Response(message)
+						r.recordExchange(ctx, message.Question[0], rule, nil, response, false, time.Since(startedAt), nil)
+						return response, nil
 					}
 				}
 				var responseCheck func(responseAddrs []netip.Addr) bool
@@ -294,6 +354,14 @@ func (r *Router) Exchange(ctx context.Context, message *mDNS.Msg, options adapte
 			}
 		}
 	}
+	if err == nil && response != nil && r.fakeIPEnabled && isServiceQuery(message.Question[0].Qtype) && (transport == nil || transport.Type() != C.DNSTypeFakeIP) {
+		if r.isFakeIPRouted(ctx, message.Question[0].Name) {
+			response = response.Copy()
+			StripServiceHints(response)
+		}
+	}
+	r.traceExchange(ctx, message.Question[0], transport, response, cached, time.Since(startedAt), err)
+	r.recordExchange(ctx, message.Question[0], rule, transport, response, cached, time.Since(startedAt), err)
 	if err != nil {
 		return nil, err
 	}
@@ -332,8 +400,12 @@ func (r *Router) Lookup(ctx context.Context, domain string, options adapter.DNSQ
 			err = RCodeNameError
 		}
 	}
-	responseAddrs, cached = r.client.LookupCache(domain, options.Strategy)
+	startedAt := time.Now()
+	if !r.dnssecEnabled {
+		responseAddrs, cached = r.client.LookupCache(domain, options.Strategy)
+	}
 	if cached {
+		r.traceLookup(ctx, domain, options.Strategy, nil, responseAddrs, true, time.Since(startedAt), nil)
 		if len(responseAddrs) == 0 {
 			return nil, RCodeNameError
 		}
@@ -343,8 +415,9 @@ func (r *Router) Lookup(ctx context.Context, domain string, options adapter.DNSQ
 	ctx, metadata := adapter.ExtendContext(ctx)
 	metadata.Destination = M.Socksaddr{}
 	metadata.Domain = FqdnToDomain(domain)
+	var transport adapter.DNSTransport
 	if options.Transport != nil {
-		transport := options.Transport
+		transport = options.Transport
 		if legacyTransport, isLegacy := transport.(adapter.LegacyDNSTransport); isLegacy {
 			if options.Strategy == C.DomainStrategyAsIS {
 				options.Strategy = r.defaultDomainStrategy
@@ -359,7 +432,6 @@ func (r *Router) Lookup(ctx context.Context, domain string, options adapter.DNSQ
 		responseAddrs, err = r.client.Lookup(ctx, transport, domain, options, nil)
 	} else {
 		var (
-			transport adapter.DNSTransport
 			rule      adapter.DNSRule
 			ruleIndex int
 		)
@@ -376,6 +448,10 @@ func (r *Router) Lookup(ctx context.Context, domain string, options adapter.DNSQ
 					case C.RuleActionRejectMethodDrop:
 						return nil, tun.ErrDrop
 					}
+				case *R.RuleActionPredefined:
+					responseAddrs, err = lookupPredefined(action, domain, options.Strategy)
+					printResult()
+					return responseAddrs, err
 				}
 			}
 			var responseCheck func(responseAddrs []netip.Addr) bool
@@ -396,12 +472,131 @@ func (r *Router) Lookup(ctx context.Context, domain string, options adapter.DNSQ
 		}
 	}
 	printResult()
+	r.traceLookup(ctx, domain, options.Strategy, transport, responseAddrs, false, time.Since(startedAt), err)
 	if len(responseAddrs) > 0 {
 		r.logger.InfoContext(ctx, "lookup succeed for ", domain, ": ", strings.Join(F.MapToString(responseAddrs), " "))
 	}
 	return responseAddrs, err
 }
 
+func (r *Router) traceExchange(ctx context.Context, question mDNS.Question, transport adapter.DNSTransport, response *mDNS.Msg, cached bool, duration time.Duration, err error) {
+	if r.tracer == nil || !r.tracer.TracingEnabled() {
+		return
+	}
+	var transportTag string
+	if transport != nil {
+		transportTag = transport.Tag()
+	}
+	r.tracer.TraceDNS(ctx, FqdnToDomain(question.Name), mDNS.TypeToString[question.Qtype], transportTag, formatAnswers(response), cached, duration, err)
+}
+
+func (r *Router) recordExchange(ctx context.Context, question mDNS.Question, rule adapter.DNSRule, transport adapter.DNSTransport, response *mDNS.Msg, cached bool, duration time.Duration, err error) {
+	if r.queryRecorder == nil {
+		return
+	}
+	record := adapter.DNSQueryRecord{
+		Domain:    FqdnToDomain(question.Name),
+		QueryType: mDNS.TypeToString[question.Qtype],
+		Answers:   formatAnswers(response),
+		Cached:    cached,
+		Duration:  duration,
+		Error:     err,
+	}
+	if metadata := adapter.ContextFrom(ctx); metadata != nil {
+		record.Source = metadata.Source
+		record.Inbound = metadata.Inbound
+	}
+	if rule != nil {
+		record.Rule = F.ToString(rule, " => ", rule.Action())
+	}
+	if transport != nil {
+		record.Transport = transport.Tag()
+	}
+	if response != nil {
+		record.RCode = mDNS.RcodeToString[response.Rcode]
+	}
+	r.queryRecorder.RecordDNSQuery(record)
+}
+
+func formatAnswers(response *mDNS.Msg) []string {
+	if response == nil {
+		return nil
+	}
+	var answers []string
+	for _, answer := range response.Answer {
+		switch record := answer.(type) {
+		case *mDNS.A:
+			answers = append(answers, M.AddrFromIP(record.A).String())
+		case *mDNS.AAAA:
+			answers = append(answers, M.AddrFromIP(record.AAAA).String())
+		case *mDNS.CNAME:
+			answers = append(answers, record.Target)
+		default:
+			answers = append(answers, strings.TrimPrefix(answer.String(), answer.Header().String()))
+		}
+	}
+	return answers
+}
+
+func (r *Router) traceLookup(ctx context.Context, domain string, strategy C.DomainStrategy, transport adapter.DNSTransport, responseAddrs []netip.Addr, cached bool, duration time.Duration, err error) {
+	if r.tracer == nil || !r.tracer.TracingEnabled() {
+		return
+	}
+	var queryType string
+	switch strategy {
+	case C.DomainStrategyIPv4Only:
+		queryType = "A"
+	case C.DomainStrategyIPv6Only:
+		queryType = "AAAA"
+	default:
+		queryType = "A,AAAA"
+	}
+	var transportTag string
+	if transport != nil {
+		transportTag = transport.Tag()
+	}
+	r.tracer.TraceDNS(ctx, domain, queryType, transportTag, F.MapToString(responseAddrs), cached, duration, err)
+}
+
+// isFakeIPRouted checks if address queries for the domain are routed to a fake-ip server,
+// in which case real addresses in IP hints of HTTPS records would bypass fake-ip.
+func (r *Router) isFakeIPRouted(ctx context.Context, name string) bool {
+	ctx, metadata := adapter.ExtendContext(ctx)
+	metadata.Destination = M.Socksaddr{}
+	metadata.Domain = FqdnToDomain(name)
+	metadata.QueryType = mDNS.TypeA
+	metadata.IPVersion = 4
+	var options adapter.DNSQueryOptions
+	transport, _, _ := r.matchDNS(ctx, true, -1, true, &options)
+	return transport != nil && transport.Type() == C.DNSTypeFakeIP
+}
+
+func lookupPredefined(action *R.RuleActionPredefined, domain string, strategy C.DomainStrategy) ([]netip.Addr, error) {
+	var response4, response6 []netip.Addr
+	for _, queryType := range []uint16{mDNS.TypeA, mDNS.TypeAAAA} {
+		if queryType == mDNS.TypeA && strategy == C.DomainStrategyIPv6Only || queryType == mDNS.TypeAAAA && strategy == C.DomainStrategyIPv4Only {
+			continue
+		}
+		response := action.Response(&mDNS.Msg{
+			Question: []mDNS.Question{{
+				Name:   mDNS.Fqdn(domain),
+				Qtype:  queryType,
+				Qclass: mDNS.ClassINET,
+			}},
+		})
+		addresses, err := MessageToAddresses(response)
+		if err != nil {
+			return nil, err
+		}
+		if queryType == mDNS.TypeA {
+			response4 = addresses
+		} else {
+			response6 = addresses
+		}
+	}
+	return sortAddresses(response4, response6, strategy), nil
+}
+
 func isAddressQuery(message *mDNS.Msg) bool {
 	for _, question := range message.Question {
 		if question.Qtype == mDNS.TypeA || question.Qtype == mDNS.TypeAAAA || question.Qtype == mDNS.TypeHTTPS {
@@ -427,7 +622,10 @@ func (r *Router) LookupReverseMapping(ip netip.Addr) (string, bool) {
 }
 
 func (r *Router) ResetNetwork() {
-	r.ClearCache()
+	r.client.ClearMemoryCache()
+	if r.platformInterface != nil {
+		r.platformInterface.ClearDNSCache()
+	}
 	for _, transport := range r.transport.Transports() {
 		transport.Reset()
 	}
@@ -0,0 +1,163 @@
+package dns
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+	M "github.com/sagernet/sing/common/metadata"
+
+	"github.com/miekg/dns"
+)
+
+func isServiceQuery(qType uint16) bool {
+	return qType == dns.TypeHTTPS || qType == dns.TypeSVCB
+}
+
+func serviceRecord(record dns.RR) (*dns.SVCB, bool) {
+	switch record := record.(type) {
+	case *dns.HTTPS:
+		return &record.SVCB, true
+	case *dns.SVCB:
+		return record, true
+	default:
+		return nil, false
+	}
+}
+
+func serviceHintAddresses(record *dns.SVCB) []netip.Addr {
+	var addresses []netip.Addr
+	for _, value := range record.Value {
+		switch hint := value.(type) {
+		case *dns.SVCBIPv4Hint:
+			for _, ip := range hint.Hint {
+				addresses = append(addresses, M.AddrFromIP(ip).Unmap())
+			}
+		case *dns.SVCBIPv6Hint:
+			addresses = append(addresses, common.Map(hint.Hint, M.AddrFromIP)...)
+		}
+	}
+	return addresses
+}
+
+// FilterServiceHints removes IP hints of SVCB and HTTPS records that are not allowed by the strategy.
+func FilterServiceHints(response *dns.Msg, strategy C.DomainStrategy) {
+	if strategy != C.DomainStrategyIPv4Only && strategy != C.DomainStrategyIPv6Only {
+		return
+	}
+	filterServiceHints(response, func(key dns.SVCBKey) bool {
+		if strategy == C.DomainStrategyIPv4Only {
+			return key != dns.SVCB_IPV6HINT
+		} else {
+			return key != dns.SVCB_IPV4HINT
+		}
+	})
+}
+
+// StripServiceHints removes all IP hints of SVCB and HTTPS records.
+func StripServiceHints(response *dns.Msg) {
+	filterServiceHints(response, func(key dns.SVCBKey) bool {
+		return key != dns.SVCB_IPV4HINT && key != dns.SVCB_IPV6HINT
+	})
+}
+
+func filterServiceHints(response *dns.Msg, keep func(key dns.SVCBKey) bool) {
+	for _, recordList := range [][]dns.RR{response.Answer, response.Extra} {
+		for _, rawRecord := range recordList {
+			record, isService := serviceRecord(rawRecord)
+			if !isService {
+				continue
+			}
+			record.Value = common.Filter(record.Value, func(it dns.SVCBKeyValue) bool {
+				return keep(it.Key())
+			})
+		}
+	}
+}
+
+// ServiceHintResponse builds an HTTPS or SVCB response with only IP hints of the addresses.
+func ServiceHintResponse(id uint16, question dns.Question, addresses []netip.Addr, timeToLive uint32) *dns.Msg {
+	response := FixedResponse(id, question, nil, timeToLive)
+	var ipv4Hint, ipv6Hint []net.IP
+	for _, address := range addresses {
+		if address.Is4() {
+			ipv4Hint = append(ipv4Hint, address.AsSlice())
+		} else {
+			ipv6Hint = append(ipv6Hint, address.AsSlice())
+		}
+	}
+	record := dns.SVCB{
+		Hdr: dns.RR_Header{
+			Name:   question.Name,
+			Rrtype: question.Qtype,
+			Class:  dns.ClassINET,
+			Ttl:    timeToLive,
+		},
+		Priority: 1,
+		Target:   ".",
+	}
+	if len(ipv4Hint) > 0 {
+		record.Value = append(record.Value, &dns.SVCBIPv4Hint{Hint: ipv4Hint})
+	}
+	if len(ipv6Hint) > 0 {
+		record.Value = append(record.Value, &dns.SVCBIPv6Hint{Hint: ipv6Hint})
+	}
+	if question.Qtype == dns.TypeHTTPS {
+		response.Answer = append(response.Answer, &dns.HTTPS{SVCB: record})
+	} else {
+		response.Answer = append(response.Answer, &record)
+	}
+	return response
+}
+
+// ECHConfigFromMessage returns the ECHConfigList of the HTTPS record with the highest priority
+// for the queried name, following CNAME records.
+func ECHConfigFromMessage(response *dns.Msg) ([]byte, error) {
+	if response.Rcode != dns.RcodeSuccess {
+		return nil, RCodeError(response.Rcode)
+	}
+	if len(response.Question) == 0 {
+		return nil, E.New("missing question")
+	}
+	name := response.Question[0].Name
+	for range response.Answer {
+		var target string
+		for _, rawRecord := range response.Answer {
+			if record, isCNAME := rawRecord.(*dns.CNAME); isCNAME && strings.EqualFold(record.Hdr.Name, name) {
+				target = record.Target
+				break
+			}
+		}
+		if target == "" {
+			break
+		}
+		name = target
+	}
+	var (
+		echConfig []byte
+		priority  uint16
+	)
+	for _, rawRecord := range response.Answer {
+		record, isHTTPS := rawRecord.(*dns.HTTPS)
+		if !isHTTPS || !strings.EqualFold(record.Hdr.Name, name) || record.Priority == 0 {
+			continue
+		}
+		if echConfig != nil && record.Priority >= priority {
+			continue
+		}
+		for _, value := range record.Value {
+			if echValue, isECH := value.(*dns.SVCBECHConfig); isECH {
+				echConfig = echValue.ECH
+				priority = record.Priority
+				break
+			}
+		}
+	}
+	if echConfig == nil {
+		return nil, E.New("no ECH config found")
+	}
+	return echConfig, nil
+}
@@ -9,8 +9,10 @@ import (
 	"github.com/sagernet/sing-box/dns"
 	"github.com/sagernet/sing-box/log"
 	"github.com/sagernet/sing-box/option"
+	R "github.com/sagernet/sing-box/route/rule"
 	E "github.com/sagernet/sing/common/exceptions"
 	"github.com/sagernet/sing/common/logger"
+	"github.com/sagernet/sing/service"
 
 	mDNS "github.com/miekg/dns"
 )
@@ -23,27 +25,65 @@ var _ adapter.FakeIPTransport = (*Transport)(nil)
 
 type Transport struct {
 	dns.TransportAdapter
-	logger logger.ContextLogger
-	store  adapter.FakeIPStore
+	ctx            context.Context
+	logger         logger.ContextLogger
+	store          adapter.FakeIPStore
+	filterItems    []R.RuleItem
+	filterRuleSets []adapter.RuleSet
+	filterRules    []string
 }
 
 func NewTransport(ctx context.Context, logger log.ContextLogger, tag string, options option.FakeIPDNSServerOptions) (adapter.DNSTransport, error) {
 	store := NewStore(ctx, logger, options.Inet4Range.Build(netip.Prefix{}), options.Inet6Range.Build(netip.Prefix{}))
-	return &Transport{
+	transport := &Transport{
 		TransportAdapter: dns.NewTransportAdapter(C.DNSTypeFakeIP, tag, nil),
+		ctx:              ctx,
 		logger:           logger,
 		store:            store,
-	}, nil
+	}
+	if options.Filter != nil {
+		filter := options.Filter
+		if len(filter.Domain) > 0 || len(filter.DomainSuffix) > 0 {
+			transport.filterItems = append(transport.filterItems, R.NewDomainItem(filter.Domain, filter.DomainSuffix))
+		}
+		if len(filter.DomainKeyword) > 0 {
+			transport.filterItems = append(transport.filterItems, R.NewDomainKeywordItem(filter.DomainKeyword))
+		}
+		if len(filter.DomainRegex) > 0 {
+			item, err := R.NewDomainRegexItem(filter.DomainRegex)
+			if err != nil {
+				return nil, E.Cause(err, "fake_ip_filter.domain_regex")
+			}
+			transport.filterItems = append(transport.filterItems, item)
+		}
+		transport.filterRules = filter.RuleSet
+	}
+	return transport, nil
 }
 
 func (t *Transport) Start(stage adapter.StartStage) error {
 	if stage != adapter.StartStateStart {
 		return nil
 	}
+	if len(t.filterRules) > 0 {
+		router := service.FromContext[adapter.Router](t.ctx)
+		for _, ruleSetTag := range t.filterRules {
+			ruleSet, loaded := router.RuleSet(ruleSetTag)
+			if !loaded {
+				return E.New("rule-set not found: ", ruleSetTag)
+			}
+			ruleSet.IncRef()
+			t.filterRuleSets = append(t.filterRuleSets, ruleSet)
+		}
+	}
 	return t.store.Start()
 }
 
 func (t *Transport) Close() error {
+	for _, ruleSet := range t.filterRuleSets {
+		ruleSet.DecRef()
+	}
+	t.filterRuleSets = nil
 	return t.store.Close()
 }
 
@@ -52,6 +92,9 @@ func (t *Transport) Reset() {
 
 func (t *Transport) Exchange(ctx context.Context, message *mDNS.Msg) (*mDNS.Msg, error) {
 	question := message.Question[0]
+	if question.Qtype == mDNS.TypeHTTPS || question.Qtype == mDNS.TypeSVCB {
+		return t.exchangeService(message.Id, question)
+	}
 	if question.Qtype != mDNS.TypeA && question.Qtype != mDNS.TypeAAAA {
 		return nil, E.New("only IP queries are supported by fakeip")
 	}
@@ -62,6 +105,50 @@ func (t *Transport) Exchange(ctx context.Context, message *mDNS.Msg) (*mDNS.Msg,
 	return dns.FixedResponse(message.Id, question, []netip.Addr{address}, C.DefaultDNSTTL), nil
 }
 
+// exchangeService responds HTTPS and SVCB queries with fake addresses as IP hints,
+// so that clients will not connect to real addresses from hints.
+func (t *Transport) exchangeService(id uint16, question mDNS.Question) (*mDNS.Msg, error) {
+	var (
+		addresses []netip.Addr
+		lastErr   error
+	)
+	for _, isIPv6 := range []bool{false, true} {
+		address, err := t.store.Create(question.Name, isIPv6)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		addresses = append(addresses, address)
+	}
+	if len(addresses) == 0 {
+		return nil, lastErr
+	}
+	return dns.ServiceHintResponse(id, question, addresses, C.DefaultDNSTTL), nil
+}
+
 func (t *Transport) Store() adapter.FakeIPStore {
 	return t.store
 }
+
+// Excluded checks if the domain matches fake_ip_filter,
+// so that it should be resolved by a real server.
+func (t *Transport) Excluded(domain string) bool {
+	if len(t.filterItems) == 0 && len(t.filterRuleSets) == 0 {
+		return false
+	}
+	metadata := adapter.InboundContext{
+		Domain: dns.FqdnToDomain(domain),
+	}
+	for _, item := range t.filterItems {
+		if item.Match(&metadata) {
+			return true
+		}
+	}
+	for _, ruleSet := range t.filterRuleSets {
+		metadata.ResetRuleCache()
+		if ruleSet.Match(&metadata) {
+			return true
+		}
+	}
+	return false
+}
@@ -81,6 +81,17 @@ func (s *MemoryStorage) FakeIPLoadDomain(domain string, isIPv6 bool) (netip.Addr
 	}
 }
 
+func (s *MemoryStorage) FakeIPRange(f func(address netip.Addr, domain string) bool) error {
+	s.addressAccess.RLock()
+	defer s.addressAccess.RUnlock()
+	for address, domain := range s.addressCache {
+		if !f(address, domain) {
+			break
+		}
+	}
+	return nil
+}
+
 func (s *MemoryStorage) FakeIPReset() error {
 	s.addressCache = make(map[netip.Addr]string)
 	s.domainCache4 = make(map[string]netip.Addr)
@@ -3,23 +3,26 @@ package fakeip
 import (
 	"context"
 	"net/netip"
+	"sync"
 
 	"github.com/sagernet/sing-box/adapter"
 	E "github.com/sagernet/sing/common/exceptions"
 	"github.com/sagernet/sing/common/logger"
+	"github.com/sagernet/sing/common/x/list"
 	"github.com/sagernet/sing/service"
 )
 
 var _ adapter.FakeIPStore = (*Store)(nil)
 
 type Store struct {
-	ctx          context.Context
-	logger       logger.Logger
-	inet4Range   netip.Prefix
-	inet6Range   netip.Prefix
-	storage      adapter.FakeIPStorage
-	inet4Current netip.Addr
-	inet6Current netip.Addr
+	ctx        context.Context
+	logger     logger.Logger
+	inet4Range netip.Prefix
+	inet6Range netip.Prefix
+	storage    adapter.FakeIPStorage
+	access     sync.Mutex
+	inet4Pool  *addressPool
+	inet6Pool  *addressPool
 }
 
 func NewStore(ctx context.Context, logger logger.Logger, inet4Range netip.Prefix, inet6Range netip.Prefix) *Store {
@@ -28,6 +31,8 @@ func NewStore(ctx context.Context, logger logger.Logger, inet4Range netip.Prefix
 		logger:     logger,
 		inet4Range: inet4Range,
 		inet6Range: inet6Range,
+		inet4Pool:  newAddressPool(inet4Range),
+		inet6Pool:  newAddressPool(inet6Range),
 	}
 }
 
@@ -42,15 +47,20 @@ func (s *Store) Start() error {
 	}
 	metadata := storage.FakeIPMetadata()
 	if metadata != nil && metadata.Inet4Range == s.inet4Range && metadata.Inet6Range == s.inet6Range {
-		s.inet4Current = metadata.Inet4Current
-		s.inet6Current = metadata.Inet6Current
-	} else {
-		if s.inet4Range.IsValid() {
-			s.inet4Current = s.inet4Range.Addr().Next().Next()
-		}
-		if s.inet6Range.IsValid() {
-			s.inet6Current = s.inet6Range.Addr().Next().Next()
+		s.inet4Pool.current = metadata.Inet4Current
+		s.inet6Pool.current = metadata.Inet6Current
+		err := storage.FakeIPRange(func(address netip.Addr, domain string) bool {
+			if s.inet4Range.Contains(address) {
+				s.inet4Pool.add(address)
+			} else if s.inet6Range.Contains(address) {
+				s.inet6Pool.add(address)
+			}
+			return true
+		})
+		if err != nil {
+			return E.Cause(err, "restore fakeip addresses")
 		}
+	} else {
 		_ = storage.FakeIPReset()
 	}
 	s.storage = storage
@@ -65,54 +75,129 @@ func (s *Store) Close() error {
 	if s.storage == nil {
 		return nil
 	}
-	return s.storage.FakeIPSaveMetadata(&adapter.FakeIPMetadata{
-		Inet4Range:   s.inet4Range,
-		Inet6Range:   s.inet6Range,
-		Inet4Current: s.inet4Current,
-		Inet6Current: s.inet6Current,
-	})
+	return s.storage.FakeIPSaveMetadata(s.metadata())
 }
 
 func (s *Store) Create(domain string, isIPv6 bool) (netip.Addr, error) {
+	var pool *addressPool
+	if !isIPv6 {
+		pool = s.inet4Pool
+	} else {
+		pool = s.inet6Pool
+	}
+	s.access.Lock()
+	defer s.access.Unlock()
 	if address, loaded := s.storage.FakeIPLoadDomain(domain, isIPv6); loaded {
+		pool.touch(address)
 		return address, nil
 	}
-	var address netip.Addr
-	if !isIPv6 {
-		if !s.inet4Current.IsValid() {
+	if !pool.current.IsValid() {
+		if !isIPv6 {
 			return netip.Addr{}, E.New("missing IPv4 fakeip address range")
-		}
-		nextAddress := s.inet4Current.Next()
-		if !s.inet4Range.Contains(nextAddress) {
-			nextAddress = s.inet4Range.Addr().Next().Next()
-		}
-		s.inet4Current = nextAddress
-		address = nextAddress
-	} else {
-		if !s.inet6Current.IsValid() {
+		} else {
 			return netip.Addr{}, E.New("missing IPv6 fakeip address range")
 		}
-		nextAddress := s.inet6Current.Next()
-		if !s.inet6Range.Contains(nextAddress) {
-			nextAddress = s.inet6Range.Addr().Next().Next()
-		}
-		s.inet6Current = nextAddress
-		address = nextAddress
+	}
+	address, recycled := pool.next()
+	if recycled {
+		s.logger.Debug("fakeip range exhausted, recycle least recently used address ", address, " for ", domain)
 	}
 	s.storage.FakeIPStoreAsync(address, domain, s.logger)
-	s.storage.FakeIPSaveMetadataAsync(&adapter.FakeIPMetadata{
-		Inet4Range:   s.inet4Range,
-		Inet6Range:   s.inet6Range,
-		Inet4Current: s.inet4Current,
-		Inet6Current: s.inet6Current,
-	})
+	s.storage.FakeIPSaveMetadataAsync(s.metadata())
 	return address, nil
 }
 
 func (s *Store) Lookup(address netip.Addr) (string, bool) {
-	return s.storage.FakeIPLoad(address)
+	domain, loaded := s.storage.FakeIPLoad(address)
+	if loaded {
+		s.access.Lock()
+		if address.Is4() {
+			s.inet4Pool.touch(address)
+		} else {
+			s.inet6Pool.touch(address)
+		}
+		s.access.Unlock()
+	}
+	return domain, loaded
+}
+
+func (s *Store) LookupDomain(domain string, isIPv6 bool) (netip.Addr, bool) {
+	return s.storage.FakeIPLoadDomain(domain, isIPv6)
+}
+
+func (s *Store) Range(f func(address netip.Addr, domain string) bool) error {
+	return s.storage.FakeIPRange(f)
 }
 
 func (s *Store) Reset() error {
+	s.access.Lock()
+	defer s.access.Unlock()
+	s.inet4Pool.reset()
+	s.inet6Pool.reset()
 	return s.storage.FakeIPReset()
 }
+
+func (s *Store) metadata() *adapter.FakeIPMetadata {
+	return &adapter.FakeIPMetadata{
+		Inet4Range:   s.inet4Range,
+		Inet6Range:   s.inet6Range,
+		Inet4Current: s.inet4Pool.current,
+		Inet6Current: s.inet6Pool.current,
+	}
+}
+
+// addressPool allocates addresses from a range sequentially, and recycles
+// the least recently used address once the range is exhausted.
+type addressPool struct {
+	prefix   netip.Prefix
+	current  netip.Addr
+	recent   list.List[netip.Addr]
+	elements map[netip.Addr]*list.Element[netip.Addr]
+}
+
+func newAddressPool(prefix netip.Prefix) *addressPool {
+	pool := &addressPool{
+		prefix:   prefix,
+		elements: make(map[netip.Addr]*list.Element[netip.Addr]),
+	}
+	pool.reset()
+	return pool
+}
+
+func (p *addressPool) reset() {
+	if p.prefix.IsValid() {
+		p.current = p.prefix.Addr().Next().Next()
+	}
+	p.recent.Init()
+	clear(p.elements)
+}
+
+func (p *addressPool) add(address netip.Addr) {
+	if element, loaded := p.elements[address]; loaded {
+		p.recent.MoveToFront(element)
+	} else {
+		p.elements[address] = p.recent.PushFront(address)
+	}
+}
+
+func (p *addressPool) touch(address netip.Addr) {
+	if element, loaded := p.elements[address]; loaded {
+		p.recent.MoveToFront(element)
+	}
+}
+
+func (p *addressPool) next() (address netip.Addr, recycled bool) {
+	nextAddress := p.current.Next()
+	if p.prefix.Contains(nextAddress) {
+		p.current = nextAddress
+		p.add(nextAddress)
+		return nextAddress, false
+	}
+	element := p.recent.Back()
+	if element == nil {
+		p.reset()
+		return p.next()
+	}
+	p.recent.MoveToFront(element)
+	return element.Value, true
+}
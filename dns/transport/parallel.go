@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/dns"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	E "github.com/sagernet/sing/common/exceptions"
+	M "github.com/sagernet/sing/common/metadata"
+	"github.com/sagernet/sing/service"
+
+	mDNS "github.com/miekg/dns"
+	"go4.org/netipx"
+)
+
+const parallelDefaultTimeout = 5 * time.Second
+
+var (
+	_ adapter.DNSTransport = (*ParallelTransport)(nil)
+	_ adapter.Lifecycle    = (*ParallelTransport)(nil)
+)
+
+func RegisterParallel(registry *dns.TransportRegistry) {
+	dns.RegisterTransport[option.ParallelDNSServerOptions](registry, C.DNSTypeParallel, NewParallel)
+}
+
+// ParallelTransport sends queries to a group of DNS servers, either racing them
+// or falling back to the next one when a server fails.
+type ParallelTransport struct {
+	dns.TransportAdapter
+	ctx          context.Context
+	logger       log.ContextLogger
+	serverTags   []string
+	servers      []adapter.DNSTransport
+	mode         string
+	timeout      time.Duration
+	checker      *parallelResponseChecker
+	checkerRules []string
+}
+
+type parallelResponseChecker struct {
+	ipSet    *netipx.IPSet
+	ruleSets []adapter.RuleSet
+	invert   bool
+}
+
+func NewParallel(ctx context.Context, logger log.ContextLogger, tag string, options option.ParallelDNSServerOptions) (adapter.DNSTransport, error) {
+	if len(options.Servers) == 0 {
+		return nil, E.New("missing servers")
+	}
+	for _, server := range options.Servers {
+		if server == tag {
+			return nil, E.New("server cannot refer to itself: ", server)
+		}
+	}
+	switch options.Mode {
+	case "":
+		options.Mode = C.DNSParallelModeRace
+	case C.DNSParallelModeRace, C.DNSParallelModeFallback:
+	default:
+		return nil, E.New("unknown parallel mode: ", options.Mode)
+	}
+	transport := &ParallelTransport{
+		TransportAdapter: dns.NewTransportAdapter(C.DNSTypeParallel, tag, options.Servers),
+		ctx:              ctx,
+		logger:           logger,
+		serverTags:       options.Servers,
+		mode:             options.Mode,
+		timeout:          time.Duration(options.Timeout),
+	}
+	if transport.timeout == 0 {
+		transport.timeout = parallelDefaultTimeout
+	}
+	if options.ResponseChecker != nil {
+		checker := &parallelResponseChecker{
+			invert: options.ResponseChecker.Invert,
+		}
+		if len(options.ResponseChecker.IPCIDR) > 0 {
+			var builder netipx.IPSetBuilder
+			for i, prefixString := range options.ResponseChecker.IPCIDR {
+				prefix, err := netip.ParsePrefix(prefixString)
+				if err == nil {
+					builder.AddPrefix(prefix)
+					continue
+				}
+				addr, addrErr := netip.ParseAddr(prefixString)
+				if addrErr == nil {
+					builder.Add(addr)
+					continue
+				}
+				return nil, E.Cause(err, "parse response_checker.ip_cidr[", i, "]")
+			}
+			ipSet, err := builder.IPSet()
+			if err != nil {
+				return nil, err
+			}
+			checker.ipSet = ipSet
+		}
+		if checker.ipSet == nil && len(options.ResponseChecker.RuleSet) == 0 {
+			return nil, E.New("missing response_checker.ip_cidr or response_checker.rule_set")
+		}
+		transport.checker = checker
+		transport.checkerRules = options.ResponseChecker.RuleSet
+	}
+	return transport, nil
+}
+
+func (t *ParallelTransport) Start(stage adapter.StartStage) error {
+	if stage != adapter.StartStateStart {
+		return nil
+	}
+	transportManager := service.FromContext[adapter.DNSTransportManager](t.ctx)
+	for _, serverTag := range t.serverTags {
+		server, loaded := transportManager.Transport(serverTag)
+		if !loaded {
+			return E.New("DNS server not found: ", serverTag)
+		}
+		t.servers = append(t.servers, server)
+	}
+	if len(t.checkerRules) > 0 {
+		router := service.FromContext[adapter.Router](t.ctx)
+		for _, ruleSetTag := range t.checkerRules {
+			ruleSet, loaded := router.RuleSet(ruleSetTag)
+			if !loaded {
+				return E.New("rule-set not found: ", ruleSetTag)
+			}
+			ruleSet.IncRef()
+			t.checker.ruleSets = append(t.checker.ruleSets, ruleSet)
+		}
+	}
+	return nil
+}
+
+func (t *ParallelTransport) Close() error {
+	if t.checker != nil {
+		for _, ruleSet := range t.checker.ruleSets {
+			ruleSet.DecRef()
+		}
+		t.checker.ruleSets = nil
+	}
+	return nil
+}
+
+func (t *ParallelTransport) Reset() {
+}
+
+func (t *ParallelTransport) Exchange(ctx context.Context, message *mDNS.Msg) (*mDNS.Msg, error) {
+	if t.mode == C.DNSParallelModeFallback {
+		return t.exchangeFallback(ctx, message)
+	}
+	return t.exchangeRace(ctx, message)
+}
+
+type parallelResult struct {
+	response *mDNS.Msg
+	err      error
+}
+
+func (t *ParallelTransport) exchangeRace(ctx context.Context, message *mDNS.Msg) (*mDNS.Msg, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan parallelResult, len(t.servers))
+	for _, server := range t.servers {
+		go func() {
+			response, err := t.exchangeServer(ctx, server, message)
+			results <- parallelResult{response, err}
+		}()
+	}
+	var errors []error
+	for range t.servers {
+		result := <-results
+		if result.err == nil {
+			return result.response, nil
+		}
+		errors = append(errors, result.err)
+	}
+	return nil, E.Errors(errors...)
+}
+
+func (t *ParallelTransport) exchangeFallback(ctx context.Context, message *mDNS.Msg) (*mDNS.Msg, error) {
+	var errors []error
+	for _, server := range t.servers {
+		if ctx.Err() != nil {
+			break
+		}
+		exchangeCtx, cancel := context.WithTimeout(ctx, t.timeout)
+		response, err := t.exchangeServer(exchangeCtx, server, message)
+		cancel()
+		if err == nil {
+			return response, nil
+		}
+		t.logger.DebugContext(ctx, "fallback to next server: ", err)
+		errors = append(errors, err)
+	}
+	if len(errors) == 0 {
+		return nil, ctx.Err()
+	}
+	return nil, E.Errors(errors...)
+}
+
+func (t *ParallelTransport) exchangeServer(ctx context.Context, server adapter.DNSTransport, message *mDNS.Msg) (*mDNS.Msg, error) {
+	response, err := server.Exchange(ctx, message.Copy())
+	if err != nil {
+		return nil, E.Cause(err, "exchange ", server.Tag())
+	}
+	if response.Rcode != mDNS.RcodeSuccess && response.Rcode != mDNS.RcodeNameError {
+		return nil, E.Cause(dns.RCodeError(response.Rcode), "exchange ", server.Tag())
+	}
+	if t.checker != nil && !t.checker.check(response) {
+		return nil, E.Cause(dns.ErrResponseRejected, "exchange ", server.Tag())
+	}
+	return response, nil
+}
+
+// check returns false if any address in the response matches the checker,
+// or if none matches when inverted.
+func (c *parallelResponseChecker) check(response *mDNS.Msg) bool {
+	addresses, _ := dns.MessageToAddresses(response)
+	if len(addresses) == 0 {
+		return true
+	}
+	for _, address := range addresses {
+		if c.match(address.Unmap()) {
+			return c.invert
+		}
+	}
+	return !c.invert
+}
+
+func (c *parallelResponseChecker) match(address netip.Addr) bool {
+	if c.ipSet != nil && c.ipSet.Contains(address) {
+		return true
+	}
+	if len(c.ruleSets) > 0 {
+		metadata := adapter.InboundContext{
+			Destination: M.SocksaddrFrom(address, 0),
+		}
+		for _, ruleSet := range c.ruleSets {
+			metadata.ResetRuleCache()
+			if ruleSet.Match(&metadata) {
+				return true
+			}
+		}
+	}
+	return false
+}
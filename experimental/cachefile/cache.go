@@ -30,6 +30,11 @@ var (
 		string(bucketMode),
 		string(bucketRuleSet),
 		string(bucketRDRC),
+		string(bucketProvider),
+		string(bucketProviderHistory),
+		string(bucketDNSCache),
+		string(bucketDNSTransportCache),
+		string(bucketUserTraffic),
 	}
 
 	cacheIDDefault = []byte("default")
@@ -44,6 +49,8 @@ type CacheFile struct {
 	storeFakeIP       bool
 	storeRDRC         bool
 	rdrcTimeout       time.Duration
+	storeDNSCache     bool
+	dnsCacheCapacity  uint32
 	DB                *bbolt.DB
 	saveMetadataTimer *time.Timer
 	saveFakeIPAccess  sync.RWMutex
@@ -52,6 +59,13 @@ type CacheFile struct {
 	saveAddress6      map[string]netip.Addr
 	saveRDRCAccess    sync.RWMutex
 	saveRDRC          map[saveRDRCCacheKey]bool
+
+	saveDNSCacheAccess    sync.Mutex
+	saveDNSCache          map[saveDNSCacheKey]*saveDNSCacheEntry
+	saveDNSCacheTimer     *time.Timer
+	saveDNSCacheScheduled bool
+	flushDNSCacheAccess   sync.Mutex
+	dnsCacheKeyCount      map[string]int
 }
 
 type saveRDRCCacheKey struct {
@@ -79,17 +93,25 @@ func New(ctx context.Context, options option.CacheFileOptions) *CacheFile {
 			rdrcTimeout = 7 * 24 * time.Hour
 		}
 	}
+	dnsCacheCapacity := options.DNSCacheCapacity
+	if dnsCacheCapacity == 0 {
+		dnsCacheCapacity = defaultDNSCacheCapacity
+	}
 	return &CacheFile{
-		ctx:          ctx,
-		path:         filemanager.BasePath(ctx, path),
-		cacheID:      cacheIDBytes,
-		storeFakeIP:  options.StoreFakeIP,
-		storeRDRC:    options.StoreRDRC,
-		rdrcTimeout:  rdrcTimeout,
-		saveDomain:   make(map[netip.Addr]string),
-		saveAddress4: make(map[string]netip.Addr),
-		saveAddress6: make(map[string]netip.Addr),
-		saveRDRC:     make(map[saveRDRCCacheKey]bool),
+		ctx:              ctx,
+		path:             filemanager.BasePath(ctx, path),
+		cacheID:          cacheIDBytes,
+		storeFakeIP:      options.StoreFakeIP,
+		storeRDRC:        options.StoreRDRC,
+		rdrcTimeout:      rdrcTimeout,
+		storeDNSCache:    options.StoreDNSCache,
+		dnsCacheCapacity: dnsCacheCapacity,
+		saveDomain:       make(map[netip.Addr]string),
+		saveAddress4:     make(map[string]netip.Addr),
+		saveAddress6:     make(map[string]netip.Addr),
+		saveRDRC:         make(map[saveRDRCCacheKey]bool),
+		saveDNSCache:     make(map[saveDNSCacheKey]*saveDNSCacheEntry),
+		dnsCacheKeyCount: make(map[string]int),
 	}
 }
 
@@ -166,7 +188,12 @@ func (c *CacheFile) Close() error {
 	if c.DB == nil {
 		return nil
 	}
-	return c.DB.Close()
+	c.saveDNSCacheAccess.Lock()
+	if c.saveDNSCacheTimer != nil {
+		c.saveDNSCacheTimer.Stop()
+	}
+	c.saveDNSCacheAccess.Unlock()
+	return E.Errors(c.flushDNSCache(), c.DB.Close())
 }
 
 func (c *CacheFile) StoreFakeIP() bool {
@@ -0,0 +1,265 @@
+package cachefile
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/sagernet/bbolt"
+	"github.com/sagernet/sing/common/buf"
+	"github.com/sagernet/sing/common/logger"
+
+	"github.com/miekg/dns"
+)
+
+var (
+	bucketDNSCache          = []byte("dns_cache")
+	bucketDNSTransportCache = []byte("dns_cache_transport")
+)
+
+const (
+	defaultDNSCacheCapacity = 4096
+	dnsCacheSaveInterval    = time.Second
+	// dnsCacheLowWaterDivisor prunes full buckets to 7/8 of the capacity
+	dnsCacheLowWaterDivisor = 8
+)
+
+type saveDNSCacheKey struct {
+	TransportName string
+	Question      dns.Question
+}
+
+type saveDNSCacheEntry struct {
+	rawMessage []byte
+	expireAt   time.Time
+}
+
+func (c *CacheFile) StoreDNSCache() bool {
+	return c.storeDNSCache
+}
+
+func (c *CacheFile) LoadDNSCache(transportName string, question dns.Question) (response *dns.Msg, expireAt time.Time, loaded bool) {
+	c.saveDNSCacheAccess.Lock()
+	entry, cached := c.saveDNSCache[saveDNSCacheKey{transportName, question}]
+	c.saveDNSCacheAccess.Unlock()
+	if cached {
+		var message dns.Msg
+		if message.Unpack(entry.rawMessage) != nil {
+			return
+		}
+		return &message, entry.expireAt, true
+	}
+	key := buf.Get(4 + len(question.Name))
+	defer buf.Put(key)
+	putDNSCacheKey(key, question)
+	c.DB.View(func(tx *bbolt.Tx) error {
+		bucket := c.dnsCacheBucket(tx, transportName)
+		if bucket == nil {
+			return nil
+		}
+		content := bucket.Get(key)
+		if len(content) < 8 {
+			return nil
+		}
+		var message dns.Msg
+		err := message.Unpack(content[8:])
+		if err != nil {
+			return nil
+		}
+		response = &message
+		expireAt = time.Unix(int64(binary.BigEndian.Uint64(content)), 0)
+		loaded = true
+		return nil
+	})
+	return
+}
+
+// SaveDNSCacheAsync queues the response in memory, queued responses are written together after dnsCacheSaveInterval.
+func (c *CacheFile) SaveDNSCacheAsync(transportName string, question dns.Question, response *dns.Msg, expireAt time.Time, logger logger.Logger) {
+	rawMessage, err := response.Pack()
+	if err != nil {
+		logger.Warn("save DNS cache: ", err)
+		return
+	}
+	c.saveDNSCacheAccess.Lock()
+	defer c.saveDNSCacheAccess.Unlock()
+	c.saveDNSCache[saveDNSCacheKey{transportName, question}] = &saveDNSCacheEntry{rawMessage, expireAt}
+	if c.saveDNSCacheTimer == nil {
+		c.saveDNSCacheTimer = time.AfterFunc(dnsCacheSaveInterval, func() {
+			err := c.flushDNSCache()
+			if err != nil {
+				logger.Warn("save DNS cache: ", err)
+			}
+		})
+	} else if !c.saveDNSCacheScheduled {
+		c.saveDNSCacheTimer.Reset(dnsCacheSaveInterval)
+	}
+	c.saveDNSCacheScheduled = true
+}
+
+// flushDNSCache writes queued responses in one transaction,
+// and prunes buckets that grow beyond the capacity.
+func (c *CacheFile) flushDNSCache() error {
+	c.flushDNSCacheAccess.Lock()
+	defer c.flushDNSCacheAccess.Unlock()
+	c.saveDNSCacheAccess.Lock()
+	c.saveDNSCacheScheduled = false
+	entries := maps.Clone(c.saveDNSCache)
+	c.saveDNSCacheAccess.Unlock()
+	if len(entries) == 0 {
+		return nil
+	}
+	keyCount := maps.Clone(c.dnsCacheKeyCount)
+	err := c.DB.Update(func(tx *bbolt.Tx) error {
+		buckets := make(map[string]*bbolt.Bucket)
+		for cacheKey, entry := range entries {
+			bucket, loaded := buckets[cacheKey.TransportName]
+			if !loaded {
+				var err error
+				bucket, err = c.createDNSCacheBucket(tx, cacheKey.TransportName)
+				if err != nil {
+					return err
+				}
+				buckets[cacheKey.TransportName] = bucket
+				if _, counted := keyCount[cacheKey.TransportName]; !counted {
+					keyCount[cacheKey.TransportName] = bucket.Stats().KeyN
+				}
+			}
+			key := make([]byte, 4+len(cacheKey.Question.Name))
+			putDNSCacheKey(key, cacheKey.Question)
+			if bucket.Get(key) == nil {
+				keyCount[cacheKey.TransportName]++
+			}
+			content := make([]byte, 8+len(entry.rawMessage))
+			binary.BigEndian.PutUint64(content, uint64(entry.expireAt.Unix()))
+			copy(content[8:], entry.rawMessage)
+			err := bucket.Put(key, content)
+			if err != nil {
+				return err
+			}
+		}
+		for transportName, bucket := range buckets {
+			if keyCount[transportName] <= int(c.dnsCacheCapacity) {
+				continue
+			}
+			count, err := c.pruneDNSCache(bucket)
+			if err != nil {
+				return err
+			}
+			keyCount[transportName] = count
+		}
+		return nil
+	})
+	if err != nil {
+		// count keys again on the next write
+		c.dnsCacheKeyCount = make(map[string]int)
+	} else {
+		c.dnsCacheKeyCount = keyCount
+	}
+	c.saveDNSCacheAccess.Lock()
+	for cacheKey, entry := range entries {
+		if c.saveDNSCache[cacheKey] == entry {
+			delete(c.saveDNSCache, cacheKey)
+		}
+	}
+	c.saveDNSCacheAccess.Unlock()
+	return err
+}
+
+// pruneDNSCache removes expired entries, then the entries expiring first,
+// until the bucket is reduced to the low-water mark, and returns the remaining key count.
+//
+// Pruning down to the low-water mark instead of the capacity keeps
+// the full scan away from most writes.
+func (c *CacheFile) pruneDNSCache(bucket *bbolt.Bucket) (int, error) {
+	type cacheEntry struct {
+		key      []byte
+		expireAt uint64
+	}
+	var entries []cacheEntry
+	err := bucket.ForEach(func(key, content []byte) error {
+		var expireAt uint64
+		if len(content) >= 8 {
+			expireAt = binary.BigEndian.Uint64(content)
+		}
+		entries = append(entries, cacheEntry{bytes.Clone(key), expireAt})
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	slices.SortFunc(entries, func(a, b cacheEntry) int {
+		return cmp.Compare(a.expireAt, b.expireAt)
+	})
+	now := uint64(time.Now().Unix())
+	removeCount := len(entries) - int(c.dnsCacheCapacity-c.dnsCacheCapacity/dnsCacheLowWaterDivisor)
+	var removed int
+	for _, entry := range entries {
+		if removed >= removeCount && entry.expireAt > now {
+			break
+		}
+		err = bucket.Delete(entry.key)
+		if err != nil {
+			return 0, err
+		}
+		removed++
+	}
+	return len(entries) - removed, nil
+}
+
+func (c *CacheFile) ClearDNSCache() error {
+	c.flushDNSCacheAccess.Lock()
+	defer c.flushDNSCacheAccess.Unlock()
+	c.saveDNSCacheAccess.Lock()
+	clear(c.saveDNSCache)
+	c.saveDNSCacheAccess.Unlock()
+	c.dnsCacheKeyCount = make(map[string]int)
+	return c.DB.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{bucketDNSCache, bucketDNSTransportCache} {
+			if c.bucket(tx, name) == nil {
+				continue
+			}
+			var err error
+			if c.cacheID == nil {
+				err = tx.DeleteBucket(name)
+			} else {
+				err = tx.Bucket(c.cacheID).DeleteBucket(name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *CacheFile) createDNSCacheBucket(tx *bbolt.Tx, transportName string) (*bbolt.Bucket, error) {
+	if transportName == "" {
+		return c.createBucket(tx, bucketDNSCache)
+	}
+	bucket, err := c.createBucket(tx, bucketDNSTransportCache)
+	if err != nil {
+		return nil, err
+	}
+	return bucket.CreateBucketIfNotExists([]byte(transportName))
+}
+
+func (c *CacheFile) dnsCacheBucket(tx *bbolt.Tx, transportName string) *bbolt.Bucket {
+	if transportName == "" {
+		return c.bucket(tx, bucketDNSCache)
+	}
+	bucket := c.bucket(tx, bucketDNSTransportCache)
+	if bucket == nil {
+		return nil
+	}
+	return bucket.Bucket([]byte(transportName))
+}
+
+func putDNSCacheKey(key []byte, question dns.Question) {
+	binary.BigEndian.PutUint16(key, question.Qtype)
+	binary.BigEndian.PutUint16(key[2:], question.Qclass)
+	copy(key[4:], question.Name)
+}
@@ -0,0 +1,69 @@
+package cachefile
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sagernet/bbolt"
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	F "github.com/sagernet/sing/common/format"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSCachePrune(t *testing.T) {
+	t.Parallel()
+	cacheFile := New(context.Background(), option.CacheFileOptions{
+		Enabled:          true,
+		Path:             filepath.Join(t.TempDir(), "cache.db"),
+		StoreDNSCache:    true,
+		DNSCacheCapacity: 16,
+	})
+	require.NoError(t, cacheFile.Start(adapter.StartStateInitialize))
+	defer cacheFile.Close()
+	logger := log.NewNOPFactory().Logger()
+	now := time.Now()
+	question := func(i int) dns.Question {
+		return dns.Question{Name: F.ToString("domain", i, ".example."), Qtype: dns.TypeA, Qclass: dns.ClassINET}
+	}
+	save := func(i int, expireAt time.Time) {
+		response := new(dns.Msg).SetQuestion(question(i).Name, dns.TypeA)
+		cacheFile.SaveDNSCacheAsync("test", question(i), response, expireAt, logger)
+	}
+	for i := 0; i < 8; i++ {
+		save(i, now.Add(-time.Minute))
+	}
+	for i := 8; i < 32; i++ {
+		save(i, now.Add(time.Duration(i)*time.Minute))
+	}
+	// queued responses are loaded before they are written
+	_, _, loaded := cacheFile.LoadDNSCache("test", question(31))
+	require.True(t, loaded)
+	require.NoError(t, cacheFile.flushDNSCache())
+
+	var keyCount int
+	require.NoError(t, cacheFile.DB.View(func(tx *bbolt.Tx) error {
+		keyCount = cacheFile.dnsCacheBucket(tx, "test").Stats().KeyN
+		return nil
+	}))
+	require.Equal(t, 14, keyCount)
+	require.Equal(t, 14, cacheFile.dnsCacheKeyCount["test"])
+	for i := 0; i < 18; i++ {
+		_, _, loaded = cacheFile.LoadDNSCache("test", question(i))
+		require.False(t, loaded, i)
+	}
+	for i := 18; i < 32; i++ {
+		_, expireAt, loaded := cacheFile.LoadDNSCache("test", question(i))
+		require.True(t, loaded, i)
+		require.Equal(t, now.Add(time.Duration(i)*time.Minute).Unix(), expireAt.Unix())
+	}
+
+	require.NoError(t, cacheFile.ClearDNSCache())
+	_, _, loaded = cacheFile.LoadDNSCache("test", question(31))
+	require.False(t, loaded)
+}
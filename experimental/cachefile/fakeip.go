@@ -179,6 +179,39 @@ func (c *CacheFile) FakeIPLoadDomain(domain string, isIPv6 bool) (netip.Addr, bo
 	return address, address.IsValid()
 }
 
+func (c *CacheFile) FakeIPRange(f func(address netip.Addr, domain string) bool) error {
+	entries := make(map[netip.Addr]string)
+	err := c.DB.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(bucketFakeIP)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, value []byte) error {
+			address, ok := netip.AddrFromSlice(key)
+			if !ok {
+				// skip metadata
+				return nil
+			}
+			entries[address] = string(value)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	c.saveFakeIPAccess.RLock()
+	for address, domain := range c.saveDomain {
+		entries[address] = domain
+	}
+	c.saveFakeIPAccess.RUnlock()
+	for address, domain := range entries {
+		if !f(address, domain) {
+			break
+		}
+	}
+	return nil
+}
+
 func (c *CacheFile) FakeIPReset() error {
 	return c.DB.Batch(func(tx *bbolt.Tx) error {
 		err := tx.DeleteBucket(bucketFakeIP)
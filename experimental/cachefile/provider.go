@@ -0,0 +1,127 @@
+package cachefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"time"
+
+	"github.com/sagernet/bbolt"
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing/common/varbin"
+)
+
+var (
+	bucketProvider        = []byte("provider")
+	bucketProviderHistory = []byte("provider_history")
+)
+
+func (c *CacheFile) LoadProvider(tag string) *adapter.SavedProvider {
+	var savedProvider adapter.SavedProvider
+	err := c.DB.View(func(t *bbolt.Tx) error {
+		bucket := c.bucket(t, bucketProvider)
+		if bucket == nil {
+			return os.ErrNotExist
+		}
+		providerBinary := bucket.Get([]byte(tag))
+		if len(providerBinary) == 0 {
+			return os.ErrInvalid
+		}
+		return savedProvider.UnmarshalBinary(providerBinary)
+	})
+	if err != nil {
+		return nil
+	}
+	return &savedProvider
+}
+
+func (c *CacheFile) SaveProvider(tag string, provider *adapter.SavedProvider) error {
+	return c.DB.Batch(func(t *bbolt.Tx) error {
+		bucket, err := c.createBucket(t, bucketProvider)
+		if err != nil {
+			return err
+		}
+		providerBinary, err := provider.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(tag), providerBinary)
+	})
+}
+
+func (c *CacheFile) LoadProviderHistory(tag string) map[string]*adapter.URLTestHistory {
+	history := make(map[string]*adapter.URLTestHistory)
+	err := c.DB.View(func(t *bbolt.Tx) error {
+		bucket := c.bucket(t, bucketProviderHistory)
+		if bucket == nil {
+			return os.ErrNotExist
+		}
+		historyBinary := bucket.Get([]byte(tag))
+		if len(historyBinary) == 0 {
+			return os.ErrInvalid
+		}
+		reader := bytes.NewReader(historyBinary)
+		var length uint32
+		err := binary.Read(reader, binary.BigEndian, &length)
+		if err != nil {
+			return err
+		}
+		for i := uint32(0); i < length; i++ {
+			var (
+				outboundTag string
+				testTime    int64
+				delay       uint16
+			)
+			err = varbin.Read(reader, binary.BigEndian, &outboundTag)
+			if err != nil {
+				return err
+			}
+			err = binary.Read(reader, binary.BigEndian, &testTime)
+			if err != nil {
+				return err
+			}
+			err = binary.Read(reader, binary.BigEndian, &delay)
+			if err != nil {
+				return err
+			}
+			history[outboundTag] = &adapter.URLTestHistory{
+				Time:  time.Unix(testTime, 0),
+				Delay: delay,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return history
+}
+
+func (c *CacheFile) SaveProviderHistory(tag string, history map[string]*adapter.URLTestHistory) error {
+	var buffer bytes.Buffer
+	err := binary.Write(&buffer, binary.BigEndian, uint32(len(history)))
+	if err != nil {
+		return err
+	}
+	for outboundTag, item := range history {
+		err = varbin.Write(&buffer, binary.BigEndian, outboundTag)
+		if err != nil {
+			return err
+		}
+		err = binary.Write(&buffer, binary.BigEndian, item.Time.Unix())
+		if err != nil {
+			return err
+		}
+		err = binary.Write(&buffer, binary.BigEndian, item.Delay)
+		if err != nil {
+			return err
+		}
+	}
+	return c.DB.Batch(func(t *bbolt.Tx) error {
+		bucket, err := c.createBucket(t, bucketProviderHistory)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(tag), buffer.Bytes())
+	})
+}
@@ -0,0 +1,43 @@
+package cachefile
+
+import (
+	"encoding/binary"
+
+	"github.com/sagernet/bbolt"
+)
+
+var bucketUserTraffic = []byte("user_traffic")
+
+func (c *CacheFile) LoadUserTraffic(inbound string, user string) uint64 {
+	var traffic uint64
+	c.DB.View(func(t *bbolt.Tx) error {
+		bucket := c.bucket(t, bucketUserTraffic)
+		if bucket == nil {
+			return nil
+		}
+		bucket = bucket.Bucket([]byte(inbound))
+		if bucket == nil {
+			return nil
+		}
+		trafficBytes := bucket.Get([]byte(user))
+		if len(trafficBytes) == 8 {
+			traffic = binary.BigEndian.Uint64(trafficBytes)
+		}
+		return nil
+	})
+	return traffic
+}
+
+func (c *CacheFile) StoreUserTraffic(inbound string, user string, traffic uint64) error {
+	return c.DB.Batch(func(t *bbolt.Tx) error {
+		bucket, err := c.createBucket(t, bucketUserTraffic)
+		if err != nil {
+			return err
+		}
+		bucket, err = bucket.CreateBucketIfNotExists([]byte(inbound))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(user), binary.BigEndian.AppendUint64(nil, traffic))
+	})
+}
@@ -3,30 +3,95 @@ package clashapi
 import (
 	"context"
 	"net/http"
+	"net/netip"
+	"sort"
 
 	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/dns"
 	"github.com/sagernet/sing/service"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	mDNS "github.com/miekg/dns"
 )
 
 func cacheRouter(ctx context.Context) http.Handler {
 	r := chi.NewRouter()
+	r.Get("/fakeip", getFakeip(ctx))
 	r.Post("/fakeip/flush", flushFakeip(ctx))
 	return r
 }
 
-func flushFakeip(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+type fakeipMapping struct {
+	IP     netip.Addr `json:"ip"`
+	Domain string     `json:"domain"`
+}
+
+func getFakeip(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		cacheFile := service.FromContext[adapter.CacheFile](ctx)
-		if cacheFile != nil {
-			err := cacheFile.FakeIPReset()
+		fakeIPTransport := service.FromContext[adapter.DNSTransportManager](ctx).FakeIP()
+		if fakeIPTransport == nil {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, newError("fakeip server not found"))
+			return
+		}
+		store := fakeIPTransport.Store()
+		mappings := make([]fakeipMapping, 0)
+		query := r.URL.Query()
+		if ipQuery := query.Get("ip"); ipQuery != "" {
+			address, err := netip.ParseAddr(ipQuery)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, newError(err.Error()))
+				return
+			}
+			if domain, loaded := store.Lookup(address.Unmap()); loaded {
+				mappings = append(mappings, fakeipMapping{address.Unmap(), dns.FqdnToDomain(domain)})
+			}
+		} else if domainQuery := query.Get("domain"); domainQuery != "" {
+			domain := mDNS.Fqdn(domainQuery)
+			for _, isIPv6 := range []bool{false, true} {
+				if address, loaded := store.LookupDomain(domain, isIPv6); loaded {
+					mappings = append(mappings, fakeipMapping{address, dns.FqdnToDomain(domain)})
+				}
+			}
+		} else {
+			err := store.Range(func(address netip.Addr, domain string) bool {
+				mappings = append(mappings, fakeipMapping{address, dns.FqdnToDomain(domain)})
+				return true
+			})
 			if err != nil {
 				render.Status(r, http.StatusInternalServerError)
 				render.JSON(w, r, newError(err.Error()))
 				return
 			}
+			sort.Slice(mappings, func(i, j int) bool {
+				return mappings[i].IP.Less(mappings[j].IP)
+			})
+		}
+		if len(mappings) == 0 && (query.Has("ip") || query.Has("domain")) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, ErrNotFound)
+			return
+		}
+		render.JSON(w, r, render.M{
+			"mappings": mappings,
+		})
+	}
+}
+
+func flushFakeip(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if fakeIPTransport := service.FromContext[adapter.DNSTransportManager](ctx).FakeIP(); fakeIPTransport != nil {
+			err = fakeIPTransport.Store().Reset()
+		} else if cacheFile := service.FromContext[adapter.CacheFile](ctx); cacheFile != nil {
+			err = cacheFile.FakeIPReset()
+		}
+		if err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, newError(err.Error()))
+			return
 		}
 		render.NoContent(w, r)
 	}
@@ -1,21 +1,28 @@
 package clashapi
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 
 	"github.com/sagernet/sing-box/adapter"
 	C "github.com/sagernet/sing-box/constant"
 	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/json"
+	"github.com/sagernet/ws"
+	"github.com/sagernet/ws/wsutil"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/miekg/dns"
 )
 
-func dnsRouter(router adapter.DNSRouter) http.Handler {
+func dnsRouter(router adapter.DNSRouter, queryLog *dnsQueryLog) http.Handler {
 	r := chi.NewRouter()
 	r.Get("/query", queryDNS(router))
+	r.Get("/logs", getDNSLogs(queryLog))
+	r.Delete("/logs", resetDNSLogs(queryLog))
+	r.Get("/stats", getDNSStatistics(queryLog))
 	return r
 }
 
@@ -80,3 +87,54 @@ func queryDNS(router adapter.DNSRouter) func(w http.ResponseWriter, r *http.Requ
 		render.JSON(w, r, responseData)
 	}
 }
+
+func getDNSLogs(queryLog *dnsQueryLog) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			render.JSON(w, r, queryLog.Entries())
+			return
+		}
+		subscription, done, err := queryLog.observer.Subscribe()
+		if err != nil {
+			render.Status(r, http.StatusNoContent)
+			return
+		}
+		defer queryLog.observer.UnSubscribe(subscription)
+		conn, _, _, err := ws.UpgradeHTTP(r, w)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := &bytes.Buffer{}
+		var entry *DNSQueryLogEntry
+		for {
+			select {
+			case <-done:
+				return
+			case entry = <-subscription:
+			}
+			buf.Reset()
+			err = json.NewEncoder(buf).Encode(entry)
+			if err != nil {
+				return
+			}
+			err = wsutil.WriteServerText(conn, buf.Bytes())
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func resetDNSLogs(queryLog *dnsQueryLog) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queryLog.Reset()
+		render.NoContent(w, r)
+	}
+}
+
+func getDNSStatistics(queryLog *dnsQueryLog) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, queryLog.Statistics())
+	}
+}
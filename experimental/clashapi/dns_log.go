@@ -0,0 +1,198 @@
+package clashapi
+
+import (
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/observable"
+	"github.com/sagernet/sing/contrab/freelru"
+	"github.com/sagernet/sing/contrab/maphash"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	dnsQueryLogSize        = 1024
+	dnsQueryStatisticsSize = 4096
+)
+
+var _ adapter.DNSQueryRecorder = (*Server)(nil)
+
+type DNSQueryLogEntry struct {
+	ID        uint64    `json:"id"`
+	Time      time.Time `json:"time"`
+	Client    string    `json:"client,omitempty"`
+	Inbound   string    `json:"inbound,omitempty"`
+	Domain    string    `json:"domain"`
+	QueryType string    `json:"qType"`
+	Rule      string    `json:"rule,omitempty"`
+	Transport string    `json:"transport,omitempty"`
+	RCode     string    `json:"rcode,omitempty"`
+	Answers   []string  `json:"answer"`
+	Cached    bool      `json:"cached"`
+	Duration  int64     `json:"duration"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type DNSQueryCounter struct {
+	Queries         uint64 `json:"queries"`
+	Cached          uint64 `json:"cached"`
+	Failed          uint64 `json:"failed"`
+	AverageDuration int64  `json:"averageDuration"`
+	totalDuration   time.Duration
+}
+
+func (c *DNSQueryCounter) add(entry *DNSQueryLogEntry) {
+	c.Queries++
+	if entry.Cached {
+		c.Cached++
+	}
+	if entry.Error != "" || (entry.RCode != dns.RcodeToString[dns.RcodeSuccess] && entry.RCode != dns.RcodeToString[dns.RcodeNameError]) {
+		c.Failed++
+	}
+	c.totalDuration += time.Duration(entry.Duration)
+	c.AverageDuration = int64(c.totalDuration) / int64(c.Queries)
+}
+
+type DNSQueryStatistics struct {
+	Total      DNSQueryCounter            `json:"total"`
+	Transports map[string]DNSQueryCounter `json:"transports"`
+	Domains    map[string]DNSQueryCounter `json:"domains"`
+	Clients    map[string]DNSQueryCounter `json:"clients"`
+}
+
+// dnsQueryLog keeps recent DNS queries in a ring buffer,
+// and aggregates counters per transport, domain and client.
+type dnsQueryLog struct {
+	access     sync.Mutex
+	entries    []*DNSQueryLogEntry
+	firstID    uint64
+	nextID     uint64
+	total      DNSQueryCounter
+	transports map[string]*DNSQueryCounter
+	domains    freelru.Cache[string, *DNSQueryCounter]
+	clients    freelru.Cache[netip.Addr, *DNSQueryCounter]
+	subscriber *observable.Subscriber[*DNSQueryLogEntry]
+	observer   *observable.Observer[*DNSQueryLogEntry]
+}
+
+func newDNSQueryLog() *dnsQueryLog {
+	subscriber := observable.NewSubscriber[*DNSQueryLogEntry](128)
+	return &dnsQueryLog{
+		entries:    make([]*DNSQueryLogEntry, dnsQueryLogSize),
+		transports: make(map[string]*DNSQueryCounter),
+		domains:    common.Must1(freelru.New[string, *DNSQueryCounter](dnsQueryStatisticsSize, maphash.NewHasher[string]().Hash32)),
+		clients:    common.Must1(freelru.New[netip.Addr, *DNSQueryCounter](dnsQueryStatisticsSize, maphash.NewHasher[netip.Addr]().Hash32)),
+		subscriber: subscriber,
+		observer:   observable.NewObserver[*DNSQueryLogEntry](subscriber, 64),
+	}
+}
+
+func (l *dnsQueryLog) Record(record adapter.DNSQueryRecord) {
+	entry := &DNSQueryLogEntry{
+		Time:      time.Now(),
+		Inbound:   record.Inbound,
+		Domain:    record.Domain,
+		QueryType: record.QueryType,
+		Rule:      record.Rule,
+		Transport: record.Transport,
+		RCode:     record.RCode,
+		Answers:   record.Answers,
+		Cached:    record.Cached,
+		Duration:  record.Duration.Nanoseconds(),
+		Error:     tracingError(record.Error),
+	}
+	if entry.Answers == nil {
+		entry.Answers = []string{}
+	}
+	if record.Source.IsValid() {
+		entry.Client = record.Source.String()
+	}
+	l.access.Lock()
+	entry.ID = l.nextID
+	l.entries[l.nextID%dnsQueryLogSize] = entry
+	l.nextID++
+	l.total.add(entry)
+	if entry.Transport != "" {
+		counter, loaded := l.transports[entry.Transport]
+		if !loaded {
+			counter = new(DNSQueryCounter)
+			l.transports[entry.Transport] = counter
+		}
+		counter.add(entry)
+	}
+	counter, _, _ := l.domains.GetAndRefreshOrAdd(entry.Domain, func() (*DNSQueryCounter, bool) {
+		return new(DNSQueryCounter), true
+	})
+	counter.add(entry)
+	if record.Source.IsIP() {
+		counter, _, _ = l.clients.GetAndRefreshOrAdd(record.Source.Addr, func() (*DNSQueryCounter, bool) {
+			return new(DNSQueryCounter), true
+		})
+		counter.add(entry)
+	}
+	l.access.Unlock()
+	l.observer.Emit(entry)
+}
+
+// Entries returns recent queries, oldest first.
+func (l *dnsQueryLog) Entries() []*DNSQueryLogEntry {
+	l.access.Lock()
+	defer l.access.Unlock()
+	firstID := l.firstID
+	if l.nextID-firstID > dnsQueryLogSize {
+		firstID = l.nextID - dnsQueryLogSize
+	}
+	entries := make([]*DNSQueryLogEntry, 0, l.nextID-firstID)
+	for id := firstID; id < l.nextID; id++ {
+		entries = append(entries, l.entries[id%dnsQueryLogSize])
+	}
+	return entries
+}
+
+func (l *dnsQueryLog) Statistics() DNSQueryStatistics {
+	l.access.Lock()
+	defer l.access.Unlock()
+	statistics := DNSQueryStatistics{
+		Total:      l.total,
+		Transports: make(map[string]DNSQueryCounter, len(l.transports)),
+		Domains:    make(map[string]DNSQueryCounter, l.domains.Len()),
+		Clients:    make(map[string]DNSQueryCounter, l.clients.Len()),
+	}
+	for tag, counter := range l.transports {
+		statistics.Transports[tag] = *counter
+	}
+	for _, domain := range l.domains.Keys() {
+		if counter, loaded := l.domains.Peek(domain); loaded {
+			statistics.Domains[domain] = *counter
+		}
+	}
+	for _, client := range l.clients.Keys() {
+		if counter, loaded := l.clients.Peek(client); loaded {
+			statistics.Clients[client.String()] = *counter
+		}
+	}
+	return statistics
+}
+
+func (l *dnsQueryLog) Reset() {
+	l.access.Lock()
+	defer l.access.Unlock()
+	clear(l.entries)
+	l.firstID = l.nextID
+	l.total = DNSQueryCounter{}
+	clear(l.transports)
+	l.domains.Purge()
+	l.clients.Purge()
+}
+
+func (l *dnsQueryLog) Close() error {
+	return l.observer.Close()
+}
+
+func (s *Server) RecordDNSQuery(record adapter.DNSQueryRecord) {
+	s.dnsQueryLog.Record(record)
+}
@@ -0,0 +1,139 @@
+package clashapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing/common"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type inboundUserStatus struct {
+	adapter.InboundUser
+	TrafficUsed      uint64  `json:"traffic_used"`
+	TrafficRemaining *uint64 `json:"traffic_remaining,omitempty"`
+}
+
+func inboundRouter(inbound adapter.InboundManager, userLimiter adapter.InboundUserLimiter) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/{tag}/users", func(r chi.Router) {
+		r.Use(findManagedInboundByTag(inbound))
+		r.Get("/", getInboundUsers(userLimiter))
+		r.Post("/", addInboundUser)
+		r.Get("/{name}", getInboundUser(userLimiter))
+		r.Delete("/{name}", removeInboundUser)
+		r.Delete("/{name}/traffic", resetInboundUserTraffic(userLimiter))
+	})
+	return r
+}
+
+func getInboundUsers(userLimiter adapter.InboundUserLimiter) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inbound := r.Context().Value(CtxKeyInbound).(adapter.ManagedUserInbound)
+		render.JSON(w, r, render.M{
+			"users": common.Map(inbound.ListUsers(), func(it adapter.InboundUser) inboundUserStatus {
+				return newInboundUserStatus(userLimiter, inbound.Tag(), it)
+			}),
+		})
+	}
+}
+
+func getInboundUser(userLimiter adapter.InboundUserLimiter) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inbound := r.Context().Value(CtxKeyInbound).(adapter.ManagedUserInbound)
+		user, loaded := inbound.User(getEscapeParam(r, "name"))
+		if !loaded {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, ErrNotFound)
+			return
+		}
+		render.JSON(w, r, newInboundUserStatus(userLimiter, inbound.Tag(), user))
+	}
+}
+
+func newInboundUserStatus(userLimiter adapter.InboundUserLimiter, inbound string, user adapter.InboundUser) inboundUserStatus {
+	status := inboundUserStatus{InboundUser: user}
+	if userLimiter != nil {
+		status.TrafficUsed = userLimiter.TrafficUsage(inbound, user.Name)
+	}
+	if user.TrafficLimit > 0 {
+		var trafficRemaining uint64
+		if status.TrafficUsed < user.TrafficLimit {
+			trafficRemaining = user.TrafficLimit - status.TrafficUsed
+		}
+		status.TrafficRemaining = &trafficRemaining
+	}
+	return status
+}
+
+func addInboundUser(w http.ResponseWriter, r *http.Request) {
+	inbound := r.Context().Value(CtxKeyInbound).(adapter.ManagedUserInbound)
+	var user adapter.InboundUser
+	err := render.DecodeJSON(r.Body, &user)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, ErrBadRequest)
+		return
+	}
+	err = inbound.AddUser(user)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+	render.NoContent(w, r)
+}
+
+func removeInboundUser(w http.ResponseWriter, r *http.Request) {
+	inbound := r.Context().Value(CtxKeyInbound).(adapter.ManagedUserInbound)
+	err := inbound.RemoveUser(getEscapeParam(r, "name"))
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+	render.NoContent(w, r)
+}
+
+func resetInboundUserTraffic(userLimiter adapter.InboundUserLimiter) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inbound := r.Context().Value(CtxKeyInbound).(adapter.ManagedUserInbound)
+		name := getEscapeParam(r, "name")
+		if _, loaded := inbound.User(name); !loaded || userLimiter == nil {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, ErrNotFound)
+			return
+		}
+		err := userLimiter.ResetTrafficUsage(inbound.Tag(), name)
+		if err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, newError(err.Error()))
+			return
+		}
+		render.NoContent(w, r)
+	}
+}
+
+func findManagedInboundByTag(inboundManager adapter.InboundManager) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inbound, exist := inboundManager.Get(getEscapeParam(r, "tag"))
+			if !exist {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, ErrNotFound)
+				return
+			}
+			managedInbound, isManaged := inbound.(adapter.ManagedUserInbound)
+			if !isManaged {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, newError("inbound "+inbound.Tag()+" does not support user management"))
+				return
+			}
+			ctx := context.WithValue(r.Context(), CtxKeyInbound, managedInbound)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -1,53 +1,71 @@
 package clashapi
 
 import (
+	"bytes"
+	"net"
 	"net/http"
 
+	"github.com/sagernet/sing/common/json"
+	"github.com/sagernet/ws"
+	"github.com/sagernet/ws/wsutil"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
-func profileRouter() http.Handler {
+func profileRouter(server *Server) http.Handler {
 	r := chi.NewRouter()
-	r.Get("/tracing", subscribeTracing)
+	r.Get("/tracing", subscribeTracing(server))
 	return r
 }
 
-func subscribeTracing(w http.ResponseWriter, r *http.Request) {
-	// if !profile.Tracing.Load() {
-	render.Status(r, http.StatusNotFound)
-	render.JSON(w, r, ErrNotFound)
-	//return
-	//}
-
-	/*wsConn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		return
-	}
-
-	ch := make(chan map[string]any, 1024)
-	sub := event.Subscribe()
-	defer event.UnSubscribe(sub)
-	buf := &bytes.Buffer{}
+func subscribeTracing(server *Server) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subscription, done, err := server.tracing.Subscribe()
+		if err != nil {
+			render.Status(r, http.StatusNoContent)
+			return
+		}
+		defer server.tracing.UnSubscribe(subscription)
 
-	go func() {
-		for elm := range sub {
-			select {
-			case ch <- elm:
-			default:
+		var conn net.Conn
+		if r.Header.Get("Upgrade") == "websocket" {
+			conn, _, _, err = ws.UpgradeHTTP(r, w)
+			if err != nil {
+				return
 			}
+			defer conn.Close()
 		}
-		close(ch)
-	}()
 
-	for elm := range ch {
-		buf.Reset()
-		if err := json.NewEncoder(buf).Encode(elm); err != nil {
-			break
+		if conn == nil {
+			w.Header().Set("Content-Type", "application/json")
+			render.Status(r, http.StatusOK)
 		}
 
-		if err := wsConn.WriteMessage(websocket.TextMessage, buf.Bytes()); err != nil {
-			break
+		buf := &bytes.Buffer{}
+		var event map[string]any
+		for {
+			select {
+			case <-done:
+				return
+			case <-r.Context().Done():
+				return
+			case event = <-subscription:
+			}
+			buf.Reset()
+			err = json.NewEncoder(buf).Encode(event)
+			if err != nil {
+				break
+			}
+			if conn == nil {
+				_, err = w.Write(buf.Bytes())
+				w.(http.Flusher).Flush()
+			} else {
+				err = wsutil.WriteServerText(conn, buf.Bytes())
+			}
+			if err != nil {
+				break
+			}
 		}
-	}*/
+	}
 }
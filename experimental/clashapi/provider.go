@@ -9,6 +9,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/adapter/provider"
 	C "github.com/sagernet/sing-box/constant"
 	"github.com/sagernet/sing/common"
 	F "github.com/sagernet/sing/common/format"
@@ -24,6 +25,7 @@ func proxyProviderRouter(server *Server, router adapter.Router) http.Handler {
 		r.Get("/", getProvider(server))
 		r.Put("/", updateProvider(server, router))
 		r.Get("/healthcheck", healthCheckProvider(server))
+		r.Get("/export", exportProvider(server))
 	})
 	return r
 }
@@ -98,6 +100,32 @@ func healthCheckProvider(server *Server) func(w http.ResponseWriter, r *http.Req
 	}
 }
 
+func exportProvider(server *Server) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		outboundProvider := r.Context().Value(CtxKeyProvider).(adapter.OutboundProvider)
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = C.ProviderFormatURI
+		}
+		content, err := provider.Export(server.ctx, outboundProvider.OutboundOptions(), format)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, newError(err.Error()))
+			return
+		}
+		switch format {
+		case C.ProviderFormatClash:
+			w.Header().Set("Content-Type", "text/yaml; charset=utf-8")
+		case C.ProviderFormatSingBox:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		default:
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(content)
+	}
+}
+
 func parseProviderName(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		name := getEscapeParam(r, "name")
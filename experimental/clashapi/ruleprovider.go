@@ -1,58 +1,101 @@
 package clashapi
 
 import (
+	"context"
 	"net/http"
+	"strings"
+
+	"github.com/sagernet/sing-box/adapter"
+	C "github.com/sagernet/sing-box/constant"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
-func ruleProviderRouter() http.Handler {
+func ruleProviderRouter(server *Server, router adapter.Router) http.Handler {
 	r := chi.NewRouter()
-	r.Get("/", getRuleProviders)
+	r.Get("/", getRuleProviders(router))
 
 	r.Route("/{name}", func(r chi.Router) {
-		r.Use(parseProviderName, findRuleProviderByName)
+		r.Use(parseProviderName, findRuleProviderByName(router))
 		r.Get("/", getRuleProvider)
-		r.Put("/", updateRuleProvider)
+		r.Put("/", updateRuleProvider(server))
 	})
 	return r
 }
 
-func getRuleProviders(w http.ResponseWriter, r *http.Request) {
-	render.JSON(w, r, render.M{
-		"providers": []string{},
-	})
+func ruleProviderInfo(ruleSet adapter.RuleSet) *render.M {
+	var vehicleType string
+	switch ruleSet.Type() {
+	case C.RuleSetTypeRemote:
+		vehicleType = "HTTP"
+	case C.RuleSetTypeLocal:
+		vehicleType = "File"
+	case C.RuleSetTypeInline:
+		vehicleType = "Inline"
+	default:
+		vehicleType = strings.ToUpper(ruleSet.Type())
+	}
+	info := render.M{
+		"name":        ruleSet.Name(),
+		"type":        "Rule",
+		"vehicleType": vehicleType,
+		"behavior":    "Classical",
+		"format":      ruleSet.Format(),
+		"ruleCount":   ruleSet.RuleCount(),
+		"updatedAt":   ruleSet.UpdateTime().Format("2006-01-02T15:04:05.999999999-07:00"),
+	}
+	return &info
+}
+
+func getRuleProviders(router adapter.Router) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		providerMap := make(render.M)
+		for _, ruleSet := range router.RuleSets() {
+			providerMap[ruleSet.Name()] = ruleProviderInfo(ruleSet)
+		}
+		render.JSON(w, r, render.M{
+			"providers": providerMap,
+		})
+	}
 }
 
 func getRuleProvider(w http.ResponseWriter, r *http.Request) {
-	// provider := r.Context().Value(CtxKeyProvider).(provider.RuleProvider)
-	// render.JSON(w, r, provider)
-	render.NoContent(w, r)
+	ruleSet := r.Context().Value(CtxKeyProvider).(adapter.RuleSet)
+	render.JSON(w, r, ruleProviderInfo(ruleSet))
 }
 
-func updateRuleProvider(w http.ResponseWriter, r *http.Request) {
-	/*provider := r.Context().Value(CtxKeyProvider).(provider.RuleProvider)
-	if err := provider.Update(); err != nil {
-		render.Status(r, http.StatusServiceUnavailable)
-		render.JSON(w, r, newError(err.Error()))
-		return
-	}*/
-	render.NoContent(w, r)
+func updateRuleProvider(server *Server) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ruleSet := r.Context().Value(CtxKeyProvider).(adapter.RuleSet)
+		remoteRuleSet, isRemote := ruleSet.(adapter.RemoteRuleSet)
+		if !isRemote {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, newError("rule-set "+ruleSet.Name()+" is not a remote rule-set"))
+			return
+		}
+		err := remoteRuleSet.Update(server.ctx)
+		if err != nil {
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, newError(err.Error()))
+			return
+		}
+		render.NoContent(w, r)
+	}
 }
 
-func findRuleProviderByName(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		/*name := r.Context().Value(CtxKeyProviderName).(string)
-		providers := tunnel.RuleProviders()
-		provider, exist := providers[name]
-		if !exist {*/
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, ErrNotFound)
-		//return
-		//}
-
-		// ctx := context.WithValue(r.Context(), CtxKeyProvider, provider)
-		// next.ServeHTTP(w, r.WithContext(ctx))
-	})
+func findRuleProviderByName(router adapter.Router) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := r.Context().Value(CtxKeyProviderName).(string)
+			ruleSet, exist := router.RuleSet(name)
+			if !exist {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, ErrNotFound)
+				return
+			}
+			ctx := context.WithValue(r.Context(), CtxKeyProvider, ruleSet)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
@@ -1,77 +1,146 @@
 package clashapi
 
 import (
+	"context"
 	"net/http"
+	"net/netip"
+	"strconv"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/common/process"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing-box/route/rule"
+	E "github.com/sagernet/sing/common/exceptions"
+	M "github.com/sagernet/sing/common/metadata"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
-func scriptRouter() http.Handler {
+func scriptRouter(ctx context.Context, logger log.ContextLogger) http.Handler {
 	r := chi.NewRouter()
-	r.Post("/", testScript)
+	r.Post("/", testScript(ctx, logger))
 	r.Patch("/", patchScript)
 	return r
 }
 
-/*type TestScriptRequest struct {
-	Script   *string    `json:"script"`
-	Metadata C.Metadata `json:"metadata"`
-}*/
+type TestScriptRequest struct {
+	Script   string         `json:"script"`
+	Metadata ScriptMetadata `json:"metadata"`
+}
+
+type ScriptMetadata struct {
+	Network         string `json:"network"`
+	Type            string `json:"type"`
+	Inbound         string `json:"inbound"`
+	User            string `json:"user"`
+	Protocol        string `json:"protocol"`
+	SourceIP        string `json:"sourceIP"`
+	SourcePort      string `json:"sourcePort"`
+	DestinationIP   string `json:"destinationIP"`
+	DestinationPort string `json:"destinationPort"`
+	Host            string `json:"host"`
+	ProcessPath     string `json:"processPath"`
+	PackageName     string `json:"packageName"`
+}
+
+func (m ScriptMetadata) InboundContext() (adapter.InboundContext, error) {
+	metadata := adapter.InboundContext{
+		Network:     m.Network,
+		Inbound:     m.Inbound,
+		InboundType: m.Type,
+		User:        m.User,
+		Protocol:    m.Protocol,
+		Domain:      m.Host,
+	}
+	source, err := parseScriptAddress(m.SourceIP, m.SourcePort)
+	if err != nil {
+		return adapter.InboundContext{}, E.Cause(err, "parse source")
+	}
+	metadata.Source = source
+	destination, err := parseScriptAddress(m.DestinationIP, m.DestinationPort)
+	if err != nil {
+		return adapter.InboundContext{}, E.Cause(err, "parse destination")
+	}
+	if !destination.IsValid() && m.Host != "" {
+		destination.Fqdn = m.Host
+	}
+	metadata.Destination = destination
+	if m.ProcessPath != "" || m.PackageName != "" {
+		metadata.ProcessInfo = &process.Info{
+			ProcessPath: m.ProcessPath,
+			PackageName: m.PackageName,
+			UserId:      -1,
+		}
+	}
+	return metadata, nil
+}
+
+func parseScriptAddress(address string, port string) (M.Socksaddr, error) {
+	var socksaddr M.Socksaddr
+	if address != "" {
+		addr, err := netip.ParseAddr(address)
+		if err != nil {
+			return M.Socksaddr{}, err
+		}
+		socksaddr.Addr = addr
+	}
+	if port != "" {
+		portNumber, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return M.Socksaddr{}, err
+		}
+		socksaddr.Port = uint16(portNumber)
+	}
+	return socksaddr, nil
+}
 
-func testScript(w http.ResponseWriter, r *http.Request) {
-	/*	req := TestScriptRequest{}
+func testScript(ctx context.Context, logger log.ContextLogger) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req TestScriptRequest
 		if err := render.DecodeJSON(r.Body, &req); err != nil {
 			render.Status(r, http.StatusBadRequest)
 			render.JSON(w, r, ErrBadRequest)
 			return
 		}
-
-		fn := tunnel.ScriptFn()
-		if req.Script == nil && fn == nil {
+		if req.Script == "" {
 			render.Status(r, http.StatusBadRequest)
 			render.JSON(w, r, newError("should send `script`"))
 			return
 		}
-
-		if !req.Metadata.Valid() {
+		metadata, err := req.Metadata.InboundContext()
+		if err != nil {
 			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, newError("metadata not valid"))
+			render.JSON(w, r, newError("metadata not valid: "+err.Error()))
 			return
 		}
-
-		if req.Script != nil {
-			var err error
-			fn, err = script.ParseScript(*req.Script)
-			if err != nil {
-				render.Status(r, http.StatusBadRequest)
-				render.JSON(w, r, newError(err.Error()))
-				return
-			}
-		}
-
-		ctx, _ := script.MakeContext(tunnel.ProxyProviders(), tunnel.RuleProviders())
-
-		thread := &starlark.Thread{}
-		ret, err := starlark.Call(thread, fn, starlark.Tuple{ctx, script.MakeMetadata(&req.Metadata)}, nil)
+		script, err := rule.NewRuleActionScript(ctx, logger, option.RouteActionScript{
+			Code: req.Script,
+		})
 		if err != nil {
 			render.Status(r, http.StatusBadRequest)
 			render.JSON(w, r, newError(err.Error()))
 			return
 		}
-
-		elm, ok := ret.(starlark.String)
-		if !ok {
+		action, err := script.Evaluate(r.Context(), &metadata)
+		if err != nil {
 			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, "script fn must return a string")
+			render.JSON(w, r, newError(err.Error()))
+			return
+		}
+		if action == nil {
+			render.JSON(w, r, render.M{
+				"action": "",
+				"result": "",
+			})
 			return
 		}
-
 		render.JSON(w, r, render.M{
-			"result": string(elm),
-		})*/
-	render.Status(r, http.StatusBadRequest)
-	render.JSON(w, r, newError("not implemented"))
+			"action": action.Type(),
+			"result": action.String(),
+		})
+	}
 }
 
 type PatchScriptRequest struct {
@@ -43,12 +43,16 @@ type Server struct {
 	ctx            context.Context
 	router         adapter.Router
 	dnsRouter      adapter.DNSRouter
+	inbound        adapter.InboundManager
+	userLimiter    adapter.InboundUserLimiter
 	outbound       adapter.OutboundManager
 	endpoint       adapter.EndpointManager
 	provider       adapter.OutboundProviderManager
 	logger         log.Logger
 	httpServer     *http.Server
 	trafficManager *trafficontrol.Manager
+	tracing        *tracingManager
+	dnsQueryLog    *dnsQueryLog
 	urlTestHistory adapter.URLTestHistoryStorage
 	mode           string
 	modeList       []string
@@ -64,18 +68,22 @@ func NewServer(ctx context.Context, logFactory log.ObservableFactory, options op
 	trafficManager := trafficontrol.NewManager()
 	chiRouter := chi.NewRouter()
 	s := &Server{
-		ctx:       ctx,
-		router:    service.FromContext[adapter.Router](ctx),
-		dnsRouter: service.FromContext[adapter.DNSRouter](ctx),
-		outbound:  service.FromContext[adapter.OutboundManager](ctx),
-		endpoint:  service.FromContext[adapter.EndpointManager](ctx),
-		provider:  service.FromContext[adapter.OutboundProviderManager](ctx),
-		logger:    logFactory.NewLogger("clash-api"),
+		ctx:         ctx,
+		router:      service.FromContext[adapter.Router](ctx),
+		dnsRouter:   service.FromContext[adapter.DNSRouter](ctx),
+		inbound:     service.FromContext[adapter.InboundManager](ctx),
+		userLimiter: service.FromContext[adapter.InboundUserLimiter](ctx),
+		outbound:    service.FromContext[adapter.OutboundManager](ctx),
+		endpoint:    service.FromContext[adapter.EndpointManager](ctx),
+		provider:    service.FromContext[adapter.OutboundProviderManager](ctx),
+		logger:      logFactory.NewLogger("clash-api"),
 		httpServer: &http.Server{
 			Addr:    options.ExternalController,
 			Handler: chiRouter,
 		},
 		trafficManager:           trafficManager,
+		tracing:                  newTracingManager(),
+		dnsQueryLog:              newDNSQueryLog(),
 		modeList:                 options.ModeList,
 		externalController:       options.ExternalController != "",
 		externalUIDownloadURL:    options.ExternalUIDownloadURL,
@@ -121,10 +129,12 @@ func NewServer(ctx context.Context, logFactory log.ObservableFactory, options op
 		r.Mount("/rules", ruleRouter(s.router))
 		r.Mount("/connections", connectionRouter(s.router, trafficManager))
 		r.Mount("/providers/proxies", proxyProviderRouter(s, s.router))
-		r.Mount("/script", scriptRouter())
-		r.Mount("/profile", profileRouter())
+		r.Mount("/providers/rules", ruleProviderRouter(s, s.router))
+		r.Mount("/script", scriptRouter(ctx, logFactory.NewLogger("script")))
+		r.Mount("/profile", profileRouter(s))
 		r.Mount("/cache", cacheRouter(ctx))
-		r.Mount("/dns", dnsRouter(s.dnsRouter))
+		r.Mount("/dns", dnsRouter(s.dnsRouter, s.dnsQueryLog))
+		r.Mount("/inbounds", inboundRouter(s.inbound, s.userLimiter))
 
 		s.setupMetaAPI(r)
 	})
@@ -189,6 +199,8 @@ func (s *Server) Close() error {
 	return common.Close(
 		common.PtrOrNil(s.httpServer),
 		s.trafficManager,
+		s.tracing,
+		s.dnsQueryLog,
 		s.urlTestHistory,
 	)
 }
@@ -0,0 +1,151 @@
+package clashapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/experimental/clashapi/trafficontrol"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/atomic"
+	F "github.com/sagernet/sing/common/format"
+	"github.com/sagernet/sing/common/observable"
+)
+
+const (
+	tracingTypeRuleMatch  = "RuleMatch"
+	tracingTypeProxyDial  = "ProxyDial"
+	tracingTypeDNSRequest = "DNSRequest"
+)
+
+var _ adapter.Tracer = (*Server)(nil)
+
+// tracingManager dispatches tracing events in the Clash Premium format to subscribers.
+type tracingManager struct {
+	subscriber  *observable.Subscriber[map[string]any]
+	observer    *observable.Observer[map[string]any]
+	subscribers atomic.Int32
+}
+
+func newTracingManager() *tracingManager {
+	subscriber := observable.NewSubscriber[map[string]any](1024)
+	return &tracingManager{
+		subscriber: subscriber,
+		observer:   observable.NewObserver[map[string]any](subscriber, 1024),
+	}
+}
+
+func (m *tracingManager) Subscribe() (subscription observable.Subscription[map[string]any], done <-chan struct{}, err error) {
+	subscription, done, err = m.observer.Subscribe()
+	if err == nil {
+		m.subscribers.Add(1)
+	}
+	return
+}
+
+func (m *tracingManager) UnSubscribe(subscription observable.Subscription[map[string]any]) {
+	m.observer.UnSubscribe(subscription)
+	m.subscribers.Add(-1)
+}
+
+func (m *tracingManager) Close() error {
+	return m.observer.Close()
+}
+
+func (s *Server) TracingEnabled() bool {
+	return s.tracing.subscribers.Load() > 0
+}
+
+func (s *Server) TraceRuleMatch(ctx context.Context, metadata adapter.InboundContext, matchedRule adapter.Rule, matchOutbound string, duration time.Duration, err error) {
+	var rule string
+	if matchedRule != nil {
+		rule = F.ToString(matchedRule, " => ", matchedRule.Action())
+	} else {
+		rule = "final"
+	}
+	s.tracing.observer.Emit(map[string]any{
+		"type":     tracingTypeRuleMatch,
+		"id":       tracingID(ctx),
+		"duration": duration.Nanoseconds(),
+		"metadata": trafficontrol.MetadataJSON(metadata),
+		"proxy":    matchOutbound,
+		"rule":     rule,
+		"payload":  "",
+		"error":    tracingError(err),
+	})
+}
+
+func (s *Server) TraceDial(ctx context.Context, metadata adapter.InboundContext, outbound string, duration time.Duration, err error) {
+	var host string
+	if metadata.Domain != "" {
+		host = metadata.Domain
+	} else {
+		host = metadata.Destination.Fqdn
+	}
+	s.tracing.observer.Emit(map[string]any{
+		"type":     tracingTypeProxyDial,
+		"id":       tracingID(ctx),
+		"duration": duration.Nanoseconds(),
+		"proxy":    outbound,
+		"chain":    s.tracingChain(outbound),
+		"address":  metadata.Destination.String(),
+		"host":     host,
+		"error":    tracingError(err),
+	})
+}
+
+func (s *Server) TraceDNS(ctx context.Context, name string, queryType string, transport string, answers []string, cached bool, duration time.Duration, err error) {
+	dnsType := "exchange"
+	if cached {
+		dnsType = "cache"
+	}
+	if answers == nil {
+		answers = []string{}
+	}
+	s.tracing.observer.Emit(map[string]any{
+		"type":      tracingTypeDNSRequest,
+		"id":        tracingID(ctx),
+		"duration":  duration.Nanoseconds(),
+		"name":      name,
+		"qType":     queryType,
+		"answer":    answers,
+		"transport": transport,
+		"dnsType":   dnsType,
+		"error":     tracingError(err),
+	})
+}
+
+// tracingChain returns the chain from the dialed outbound to the outbound finally selected by groups.
+func (s *Server) tracingChain(outbound string) []string {
+	var chain []string
+	next := outbound
+	for next != "" {
+		detour, loaded := s.provider.OutboundWithProvider(next)
+		if !loaded {
+			break
+		}
+		chain = append(chain, next)
+		group, isGroup := detour.(adapter.OutboundGroup)
+		if !isGroup {
+			break
+		}
+		next = group.Now()
+	}
+	return common.Reverse(chain)
+}
+
+func tracingID(ctx context.Context) string {
+	id, loaded := log.IDFromContext(ctx)
+	if !loaded {
+		return ""
+	}
+	return F.ToString(id.ID)
+}
+
+func tracingError(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -28,36 +28,53 @@ type TrackerMetadata struct {
 	OutboundType string
 }
 
-func (t TrackerMetadata) MarshalJSON() ([]byte, error) {
+// MetadataJSON returns metadata in the format of Clash connection metadata.
+func MetadataJSON(metadata adapter.InboundContext) map[string]any {
 	var inbound string
-	if t.Metadata.Inbound != "" {
-		inbound = t.Metadata.InboundType + "/" + t.Metadata.Inbound
+	if metadata.Inbound != "" {
+		inbound = metadata.InboundType + "/" + metadata.Inbound
 	} else {
-		inbound = t.Metadata.InboundType
+		inbound = metadata.InboundType
 	}
 	var domain string
-	if t.Metadata.Domain != "" {
-		domain = t.Metadata.Domain
+	if metadata.Domain != "" {
+		domain = metadata.Domain
+	} else if metadata.Destination.Fqdn != "" {
+		domain = metadata.Destination.Fqdn
 	} else {
-		domain = t.Metadata.Destination.Fqdn
+		domain = metadata.ReverseMappingDomain
 	}
 	var processPath string
-	if t.Metadata.ProcessInfo != nil {
-		if t.Metadata.ProcessInfo.ProcessPath != "" {
-			processPath = t.Metadata.ProcessInfo.ProcessPath
-		} else if t.Metadata.ProcessInfo.PackageName != "" {
-			processPath = t.Metadata.ProcessInfo.PackageName
+	if metadata.ProcessInfo != nil {
+		if metadata.ProcessInfo.ProcessPath != "" {
+			processPath = metadata.ProcessInfo.ProcessPath
+		} else if metadata.ProcessInfo.PackageName != "" {
+			processPath = metadata.ProcessInfo.PackageName
 		}
 		if processPath == "" {
-			if t.Metadata.ProcessInfo.UserId != -1 {
-				processPath = F.ToString(t.Metadata.ProcessInfo.UserId)
+			if metadata.ProcessInfo.UserId != -1 {
+				processPath = F.ToString(metadata.ProcessInfo.UserId)
 			}
-		} else if t.Metadata.ProcessInfo.User != "" {
-			processPath = F.ToString(processPath, " (", t.Metadata.ProcessInfo.User, ")")
-		} else if t.Metadata.ProcessInfo.UserId != -1 {
-			processPath = F.ToString(processPath, " (", t.Metadata.ProcessInfo.UserId, ")")
+		} else if metadata.ProcessInfo.User != "" {
+			processPath = F.ToString(processPath, " (", metadata.ProcessInfo.User, ")")
+		} else if metadata.ProcessInfo.UserId != -1 {
+			processPath = F.ToString(processPath, " (", metadata.ProcessInfo.UserId, ")")
 		}
 	}
+	return map[string]any{
+		"network":         metadata.Network,
+		"type":            inbound,
+		"sourceIP":        metadata.Source.Addr,
+		"destinationIP":   metadata.Destination.Addr,
+		"sourcePort":      F.ToString(metadata.Source.Port),
+		"destinationPort": F.ToString(metadata.Destination.Port),
+		"host":            domain,
+		"dnsMode":         "normal",
+		"processPath":     processPath,
+	}
+}
+
+func (t TrackerMetadata) MarshalJSON() ([]byte, error) {
 	var rule string
 	if t.Rule != nil {
 		rule = F.ToString(t.Rule, " => ", t.Rule.Action())
@@ -65,18 +82,8 @@ func (t TrackerMetadata) MarshalJSON() ([]byte, error) {
 		rule = "final"
 	}
 	return json.Marshal(map[string]any{
-		"id": t.ID,
-		"metadata": map[string]any{
-			"network":         t.Metadata.Network,
-			"type":            inbound,
-			"sourceIP":        t.Metadata.Source.Addr,
-			"destinationIP":   t.Metadata.Destination.Addr,
-			"sourcePort":      F.ToString(t.Metadata.Source.Port),
-			"destinationPort": F.ToString(t.Metadata.Destination.Port),
-			"host":            domain,
-			"dnsMode":         "normal",
-			"processPath":     processPath,
-		},
+		"id":          t.ID,
+		"metadata":    MetadataJSON(t.Metadata),
 		"upload":      t.Upload.Load(),
 		"download":    t.Download.Load(),
 		"start":       t.CreatedAt,
@@ -49,10 +49,12 @@ func (s *CommandServer) handleURLTest(conn net.Conn) error {
 	if !isOutboundGroup {
 		return writeError(conn, E.New("outbound is not a group: ", groupTag))
 	}
-	urlTest, isURLTest := abstractOutboundGroup.(*group.URLTest)
-	if isURLTest {
+	switch urlTest := abstractOutboundGroup.(type) {
+	case *group.URLTest:
 		go urlTest.CheckOutbounds()
-	} else {
+	case *group.Fallback:
+		go urlTest.CheckOutbounds()
+	default:
 		historyStorage := service.PtrFromContext[urltest.HistoryStorage](serviceNow.ctx)
 		outbounds := common.Filter(common.Map(outboundGroup.All(), func(it string) adapter.Outbound {
 			itOutbound, _ := serviceNow.instance.Outbound().OutboundWithProvider(it)
@@ -0,0 +1,142 @@
+package v2rayapi
+
+import (
+	"context"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+)
+
+var _ HandlerServiceServer = (*HandlerService)(nil)
+
+type HandlerService struct {
+	inbound     adapter.InboundManager
+	userLimiter adapter.InboundUserLimiter
+}
+
+func NewHandlerService(inbound adapter.InboundManager, userLimiter adapter.InboundUserLimiter, options option.V2RayHandlerServiceOptions) *HandlerService {
+	if !options.Enabled {
+		return nil
+	}
+	return &HandlerService{
+		inbound:     inbound,
+		userLimiter: userLimiter,
+	}
+}
+
+func (s *HandlerService) AlterInbound(ctx context.Context, request *AlterInboundRequest) (*AlterInboundResponse, error) {
+	inbound, err := s.managedInbound(request.Tag)
+	if err != nil {
+		return nil, err
+	}
+	switch operation := request.Operation.(type) {
+	case *AlterInboundRequest_AddUser:
+		if operation.AddUser.GetUser() == nil {
+			return nil, E.New("missing user")
+		}
+		err = inbound.AddUser(adapter.InboundUser{
+			Name:     operation.AddUser.User.Name,
+			UUID:     operation.AddUser.User.Uuid,
+			Password: operation.AddUser.User.Password,
+			Flow:     operation.AddUser.User.Flow,
+			AlterID:  int(operation.AddUser.User.AlterId),
+
+			TrafficLimit: operation.AddUser.User.TrafficLimit,
+			ExpireAt:     operation.AddUser.User.ExpireAt,
+			SpeedLimit:   operation.AddUser.User.SpeedLimit,
+		})
+	case *AlterInboundRequest_RemoveUser:
+		err = inbound.RemoveUser(operation.RemoveUser.Name)
+	default:
+		return nil, E.New("missing operation")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &AlterInboundResponse{}, nil
+}
+
+func (s *HandlerService) GetInboundUsers(ctx context.Context, request *GetInboundUserRequest) (*GetInboundUserResponse, error) {
+	users, err := s.inboundUsers(request)
+	if err != nil {
+		return nil, err
+	}
+	return &GetInboundUserResponse{
+		Users: common.Map(users, func(it adapter.InboundUser) *User {
+			user := &User{
+				Name:         it.Name,
+				Uuid:         it.UUID,
+				Password:     it.Password,
+				Flow:         it.Flow,
+				AlterId:      int32(it.AlterID),
+				TrafficLimit: it.TrafficLimit,
+				ExpireAt:     it.ExpireAt,
+				SpeedLimit:   it.SpeedLimit,
+			}
+			if s.userLimiter != nil {
+				user.TrafficUsed = s.userLimiter.TrafficUsage(request.Tag, it.Name)
+			}
+			if user.TrafficUsed < user.TrafficLimit {
+				user.TrafficRemaining = user.TrafficLimit - user.TrafficUsed
+			}
+			return user
+		}),
+	}, nil
+}
+
+func (s *HandlerService) GetInboundUsersCount(ctx context.Context, request *GetInboundUserRequest) (*GetInboundUsersCountResponse, error) {
+	users, err := s.inboundUsers(request)
+	if err != nil {
+		return nil, err
+	}
+	return &GetInboundUsersCountResponse{Count: int64(len(users))}, nil
+}
+
+func (s *HandlerService) ResetInboundUserTraffic(ctx context.Context, request *ResetInboundUserTrafficRequest) (*ResetInboundUserTrafficResponse, error) {
+	inbound, err := s.managedInbound(request.Tag)
+	if err != nil {
+		return nil, err
+	}
+	if _, loaded := inbound.User(request.Name); !loaded {
+		return nil, E.New("user ", request.Name, " not found")
+	}
+	if s.userLimiter == nil {
+		return nil, E.New("missing user limiter")
+	}
+	err = s.userLimiter.ResetTrafficUsage(request.Tag, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &ResetInboundUserTrafficResponse{}, nil
+}
+
+func (s *HandlerService) managedInbound(tag string) (adapter.ManagedUserInbound, error) {
+	inbound, loaded := s.inbound.Get(tag)
+	if !loaded {
+		return nil, E.New("inbound ", tag, " not found")
+	}
+	managedInbound, isManaged := inbound.(adapter.ManagedUserInbound)
+	if !isManaged {
+		return nil, E.New("inbound ", tag, " does not support user management")
+	}
+	return managedInbound, nil
+}
+
+func (s *HandlerService) inboundUsers(request *GetInboundUserRequest) ([]adapter.InboundUser, error) {
+	inbound, err := s.managedInbound(request.Tag)
+	if err != nil {
+		return nil, err
+	}
+	users := inbound.ListUsers()
+	if request.Name != "" {
+		users = common.Filter(users, func(it adapter.InboundUser) bool {
+			return it.Name == request.Name
+		})
+	}
+	return users, nil
+}
+
+func (s *HandlerService) mustEmbedUnimplementedHandlerServiceServer() {
+}
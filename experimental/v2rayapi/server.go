@@ -1,6 +1,7 @@
 package v2rayapi
 
 import (
+	"context"
 	"errors"
 	"net"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"github.com/sagernet/sing-box/log"
 	"github.com/sagernet/sing-box/option"
 	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/service"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -29,12 +31,16 @@ type Server struct {
 	statsService *StatsService
 }
 
-func NewServer(logger log.Logger, options option.V2RayAPIOptions) (adapter.V2RayServer, error) {
+func NewServer(ctx context.Context, logger log.Logger, options option.V2RayAPIOptions) (adapter.V2RayServer, error) {
 	grpcServer := grpc.NewServer(grpc.Creds(insecure.NewCredentials()))
 	statsService := NewStatsService(common.PtrValueOrDefault(options.Stats))
 	if statsService != nil {
 		RegisterStatsServiceServer(grpcServer, statsService)
 	}
+	handlerService := NewHandlerService(service.FromContext[adapter.InboundManager](ctx), service.FromContext[adapter.InboundUserLimiter](ctx), common.PtrValueOrDefault(options.Handler))
+	if handlerService != nil {
+		RegisterHandlerServiceServer(grpcServer, handlerService)
+	}
 	server := &Server{
 		logger:       logger,
 		listen:       options.Listen,
@@ -78,5 +84,8 @@ func (s *Server) Close() error {
 }
 
 func (s *Server) StatsService() adapter.ConnectionTracker {
+	if s.statsService == nil {
+		return nil
+	}
 	return s.statsService
 }
@@ -50,12 +50,16 @@ func (o *DNSOptions) UnmarshalJSONContext(ctx context.Context, content []byte) e
 }
 
 type DNSClientOptions struct {
-	Strategy         DomainStrategy        `json:"strategy,omitempty"`
-	DisableCache     bool                  `json:"disable_cache,omitempty"`
-	DisableExpire    bool                  `json:"disable_expire,omitempty"`
-	IndependentCache bool                  `json:"independent_cache,omitempty"`
-	CacheCapacity    uint32                `json:"cache_capacity,omitempty"`
-	ClientSubnet     *badoption.Prefixable `json:"client_subnet,omitempty"`
+	Strategy           DomainStrategy                            `json:"strategy,omitempty"`
+	DisableCache       bool                                      `json:"disable_cache,omitempty"`
+	DisableExpire      bool                                      `json:"disable_expire,omitempty"`
+	IndependentCache   bool                                      `json:"independent_cache,omitempty"`
+	ServeStale         bool                                      `json:"serve_stale,omitempty"`
+	Prefetch           bool                                      `json:"prefetch,omitempty"`
+	DNSSEC             bool                                      `json:"dnssec,omitempty"`
+	DNSSECTrustAnchors badoption.Listable[DNSTrustAnchorOptions] `json:"dnssec_trust_anchors,omitempty"`
+	CacheCapacity      uint32                                    `json:"cache_capacity,omitempty"`
+	ClientSubnet       *badoption.Prefixable                     `json:"client_subnet,omitempty"`
 }
 
 type LegacyDNSFakeIPOptions struct {
@@ -319,11 +323,40 @@ type RemoteHTTPSDNSServerOptions struct {
 }
 
 type FakeIPDNSServerOptions struct {
-	Inet4Range *badoption.Prefix `json:"inet4_range,omitempty"`
-	Inet6Range *badoption.Prefix `json:"inet6_range,omitempty"`
+	Inet4Range *badoption.Prefix    `json:"inet4_range,omitempty"`
+	Inet6Range *badoption.Prefix    `json:"inet6_range,omitempty"`
+	Filter     *FakeIPFilterOptions `json:"fake_ip_filter,omitempty"`
+}
+
+type FakeIPFilterOptions struct {
+	Domain        badoption.Listable[string] `json:"domain,omitempty"`
+	DomainSuffix  badoption.Listable[string] `json:"domain_suffix,omitempty"`
+	DomainKeyword badoption.Listable[string] `json:"domain_keyword,omitempty"`
+	DomainRegex   badoption.Listable[string] `json:"domain_regex,omitempty"`
+	RuleSet       badoption.Listable[string] `json:"rule_set,omitempty"`
 }
 
 type DHCPDNSServerOptions struct {
 	LocalDNSServerOptions
 	Interface string `json:"interface,omitempty"`
 }
+
+type DNSInboundOptions struct {
+	ListenOptions
+	Mode string `json:"mode,omitempty"`
+	Path string `json:"path,omitempty"`
+	InboundTLSOptionsContainer
+}
+
+type ParallelDNSServerOptions struct {
+	Servers         badoption.Listable[string] `json:"servers"`
+	Mode            string                     `json:"mode,omitempty"`
+	Timeout         badoption.Duration         `json:"timeout,omitempty"`
+	ResponseChecker *DNSResponseCheckerOptions `json:"response_checker,omitempty"`
+}
+
+type DNSResponseCheckerOptions struct {
+	IPCIDR  badoption.Listable[string] `json:"ip_cidr,omitempty"`
+	RuleSet badoption.Listable[string] `json:"rule_set,omitempty"`
+	Invert  bool                       `json:"invert,omitempty"`
+}
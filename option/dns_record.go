@@ -152,3 +152,20 @@ func (o *DNSRecordOptions) unmarshalBase64(binary []byte) error {
 func (o DNSRecordOptions) build() dns.RR {
 	return o.RR
 }
+
+type DNSTrustAnchorOptions struct {
+	DNSRecordOptions
+}
+
+func (o *DNSTrustAnchorOptions) UnmarshalJSON(data []byte) error {
+	err := o.DNSRecordOptions.UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	switch o.RR.(type) {
+	case *dns.DS, *dns.DNSKEY:
+		return nil
+	default:
+		return E.New("trust anchor must be a DS or DNSKEY record: ", o.RR.String())
+	}
+}
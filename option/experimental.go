@@ -11,12 +11,14 @@ type ExperimentalOptions struct {
 }
 
 type CacheFileOptions struct {
-	Enabled     bool               `json:"enabled,omitempty"`
-	Path        string             `json:"path,omitempty"`
-	CacheID     string             `json:"cache_id,omitempty"`
-	StoreFakeIP bool               `json:"store_fakeip,omitempty"`
-	StoreRDRC   bool               `json:"store_rdrc,omitempty"`
-	RDRCTimeout badoption.Duration `json:"rdrc_timeout,omitempty"`
+	Enabled          bool               `json:"enabled,omitempty"`
+	Path             string             `json:"path,omitempty"`
+	CacheID          string             `json:"cache_id,omitempty"`
+	StoreFakeIP      bool               `json:"store_fakeip,omitempty"`
+	StoreRDRC        bool               `json:"store_rdrc,omitempty"`
+	RDRCTimeout      badoption.Duration `json:"rdrc_timeout,omitempty"`
+	StoreDNSCache    bool               `json:"store_dns_cache,omitempty"`
+	DNSCacheCapacity uint32             `json:"dns_cache_capacity,omitempty"`
 }
 
 type ClashAPIOptions struct {
@@ -43,8 +45,9 @@ type ClashAPIOptions struct {
 }
 
 type V2RayAPIOptions struct {
-	Listen string                    `json:"listen,omitempty"`
-	Stats  *V2RayStatsServiceOptions `json:"stats,omitempty"`
+	Listen  string                      `json:"listen,omitempty"`
+	Stats   *V2RayStatsServiceOptions   `json:"stats,omitempty"`
+	Handler *V2RayHandlerServiceOptions `json:"handler,omitempty"`
 }
 
 type V2RayStatsServiceOptions struct {
@@ -53,3 +56,7 @@ type V2RayStatsServiceOptions struct {
 	Outbounds []string `json:"outbounds,omitempty"`
 	Users     []string `json:"users,omitempty"`
 }
+
+type V2RayHandlerServiceOptions struct {
+	Enabled bool `json:"enabled,omitempty"`
+}
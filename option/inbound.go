@@ -104,3 +104,9 @@ func (o *ListenOptions) TakeListenOptions() ListenOptions {
 func (o *ListenOptions) ReplaceListenOptions(options ListenOptions) {
 	*o = options
 }
+
+type InboundUserLimitOptions struct {
+	TrafficLimit MemoryBytes `json:"traffic_limit,omitempty"`
+	ExpireAt     int64       `json:"expire_at,omitempty"`
+	SpeedLimit   MemoryBytes `json:"speed_limit,omitempty"`
+}
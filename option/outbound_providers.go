@@ -43,14 +43,17 @@ type OverrideDialerOptions struct {
 }
 
 type LocalProviderOptions struct {
+	Format string `json:"format,omitempty"`
 	HealthcheckOptions
 }
 
 type RemoteProviderOptions struct {
-	Url       string             `json:"download_url"`
-	UserAgent string             `json:"download_ua,omitempty"`
-	Interval  badoption.Duration `json:"download_interval,omitempty"`
-	Detour    string             `json:"download_detour,omitempty"`
+	Url       string                  `json:"download_url"`
+	UserAgent string                  `json:"download_ua,omitempty"`
+	Interval  badoption.Duration      `json:"download_interval,omitempty"`
+	Detour    string                  `json:"download_detour,omitempty"`
+	Format    string                  `json:"format,omitempty"`
+	Verify    *SignatureVerifyOptions `json:"verify,omitempty"`
 	HealthcheckOptions
 }
 
@@ -102,6 +102,7 @@ type RawDefaultRule struct {
 	WIFIBSSID                badoption.Listable[string]        `json:"wifi_bssid,omitempty"`
 	RuleSet                  badoption.Listable[string]        `json:"rule_set,omitempty"`
 	RuleSetIPCIDRMatchSource bool                              `json:"rule_set_ip_cidr_match_source,omitempty"`
+	ReverseMapping           bool                              `json:"reverse_mapping,omitempty"`
 	Invert                   bool                              `json:"invert,omitempty"`
 
 	// Deprecated: renamed to rule_set_ip_cidr_match_source
@@ -128,14 +129,16 @@ func (r *DefaultRule) UnmarshalJSON(data []byte) error {
 func (r *DefaultRule) IsValid() bool {
 	var defaultValue DefaultRule
 	defaultValue.Invert = r.Invert
+	defaultValue.ReverseMapping = r.ReverseMapping
 	defaultValue.Action = r.Action
 	return !reflect.DeepEqual(r, defaultValue)
 }
 
 type RawLogicalRule struct {
-	Mode   string `json:"mode"`
-	Rules  []Rule `json:"rules,omitempty"`
-	Invert bool   `json:"invert,omitempty"`
+	Mode           string `json:"mode"`
+	Rules          []Rule `json:"rules,omitempty"`
+	ReverseMapping bool   `json:"reverse_mapping,omitempty"`
+	Invert         bool   `json:"invert,omitempty"`
 }
 
 type LogicalRule struct {
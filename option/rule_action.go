@@ -21,6 +21,7 @@ type _RuleAction struct {
 	RejectOptions       RejectActionOptions       `json:"-"`
 	SniffOptions        RouteActionSniff          `json:"-"`
 	ResolveOptions      RouteActionResolve        `json:"-"`
+	ScriptOptions       RouteActionScript         `json:"-"`
 }
 
 type RuleAction _RuleAction
@@ -46,6 +47,8 @@ func (r RuleAction) MarshalJSON() ([]byte, error) {
 		v = r.SniffOptions
 	case C.RuleActionTypeResolve:
 		v = r.ResolveOptions
+	case C.RuleActionTypeScript:
+		v = r.ScriptOptions
 	default:
 		return nil, E.New("unknown rule action: " + r.Action)
 	}
@@ -77,6 +80,8 @@ func (r *RuleAction) UnmarshalJSON(data []byte) error {
 		v = &r.SniffOptions
 	case C.RuleActionTypeResolve:
 		v = &r.ResolveOptions
+	case C.RuleActionTypeScript:
+		v = &r.ScriptOptions
 	default:
 		return E.New("unknown rule action: " + r.Action)
 	}
@@ -92,6 +97,8 @@ type _DNSRuleAction struct {
 	RouteOptions        DNSRouteActionOptions        `json:"-"`
 	RouteOptionsOptions DNSRouteOptionsActionOptions `json:"-"`
 	RejectOptions       RejectActionOptions          `json:"-"`
+	PredefinedOptions   DNSRouteActionPredefined     `json:"-"`
+	RewriteOptions      DNSRouteActionRewrite        `json:"-"`
 }
 
 type DNSRuleAction _DNSRuleAction
@@ -109,6 +116,10 @@ func (r DNSRuleAction) MarshalJSON() ([]byte, error) {
 		v = r.RouteOptionsOptions
 	case C.RuleActionTypeReject:
 		v = r.RejectOptions
+	case C.RuleActionTypePredefined:
+		v = r.PredefinedOptions
+	case C.RuleActionTypeRewrite:
+		v = r.RewriteOptions
 	default:
 		return nil, E.New("unknown DNS rule action: " + r.Action)
 	}
@@ -129,6 +140,10 @@ func (r *DNSRuleAction) UnmarshalJSONContext(ctx context.Context, data []byte) e
 		v = &r.RouteOptionsOptions
 	case C.RuleActionTypeReject:
 		v = &r.RejectOptions
+	case C.RuleActionTypePredefined:
+		v = &r.PredefinedOptions
+	case C.RuleActionTypeRewrite:
+		v = &r.RewriteOptions
 	default:
 		return E.New("unknown DNS rule action: " + r.Action)
 	}
@@ -174,6 +189,31 @@ type DNSRouteActionOptions struct {
 	DisableCache bool                  `json:"disable_cache,omitempty"`
 	RewriteTTL   *uint32               `json:"rewrite_ttl,omitempty"`
 	ClientSubnet *badoption.Prefixable `json:"client_subnet,omitempty"`
+	DNSSEC       bool                  `json:"dnssec,omitempty"`
+}
+
+type DNSRouteActionPredefined struct {
+	RCode  *DNSRCode                            `json:"rcode,omitempty"`
+	Answer badoption.Listable[DNSRecordOptions] `json:"answer,omitempty"`
+	Ns     badoption.Listable[DNSRecordOptions] `json:"ns,omitempty"`
+	Extra  badoption.Listable[DNSRecordOptions] `json:"extra,omitempty"`
+}
+
+type _DNSRouteActionRewrite struct {
+	QueryName string `json:"query_name,omitempty"`
+}
+
+type DNSRouteActionRewrite _DNSRouteActionRewrite
+
+func (r *DNSRouteActionRewrite) UnmarshalJSON(data []byte) error {
+	err := json.Unmarshal(data, (*_DNSRouteActionRewrite)(r))
+	if err != nil {
+		return err
+	}
+	if r.QueryName == "" {
+		return E.New("missing query_name for rewrite action")
+	}
+	return nil
 }
 
 type _DNSRouteOptionsActionOptions struct {
@@ -181,6 +221,7 @@ type _DNSRouteOptionsActionOptions struct {
 	DisableCache bool                  `json:"disable_cache,omitempty"`
 	RewriteTTL   *uint32               `json:"rewrite_ttl,omitempty"`
 	ClientSubnet *badoption.Prefixable `json:"client_subnet,omitempty"`
+	DNSSEC       bool                  `json:"dnssec,omitempty"`
 }
 
 type DNSRouteOptionsActionOptions _DNSRouteOptionsActionOptions
@@ -283,3 +324,9 @@ type RouteActionResolve struct {
 	Strategy DomainStrategy `json:"strategy,omitempty"`
 	Server   string         `json:"server,omitempty"`
 }
+
+type RouteActionScript struct {
+	Code    string             `json:"code,omitempty"`
+	Path    string             `json:"path,omitempty"`
+	Timeout badoption.Duration `json:"timeout,omitempty"`
+}
@@ -85,9 +85,15 @@ type LocalRuleSet struct {
 }
 
 type RemoteRuleSet struct {
-	URL            string             `json:"url"`
-	DownloadDetour string             `json:"download_detour,omitempty"`
-	UpdateInterval badoption.Duration `json:"update_interval,omitempty"`
+	URL            string                  `json:"url"`
+	DownloadDetour string                  `json:"download_detour,omitempty"`
+	UpdateInterval badoption.Duration      `json:"update_interval,omitempty"`
+	Verify         *SignatureVerifyOptions `json:"verify,omitempty"`
+}
+
+type SignatureVerifyOptions struct {
+	PublicKey       string `json:"public_key"`
+	SignatureHeader string `json:"signature_header,omitempty"`
 }
 
 type _HeadlessRule struct {
@@ -13,6 +13,7 @@ type ShadowsocksInboundOptions struct {
 type ShadowsocksUser struct {
 	Name     string `json:"name"`
 	Password string `json:"password"`
+	InboundUserLimitOptions
 }
 
 type ShadowsocksDestination struct {
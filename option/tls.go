@@ -96,6 +96,7 @@ type OutboundECHOptions struct {
 	DynamicRecordSizingDisabled bool                       `json:"dynamic_record_sizing_disabled,omitempty"`
 	Config                      badoption.Listable[string] `json:"config,omitempty"`
 	ConfigPath                  string                     `json:"config_path,omitempty"`
+	QueryServerName             string                     `json:"query_server_name,omitempty"`
 }
 
 type OutboundUTLSOptions struct {
@@ -16,6 +16,7 @@ type TUICUser struct {
 	Name     string `json:"name,omitempty"`
 	UUID     string `json:"uuid,omitempty"`
 	Password string `json:"password,omitempty"`
+	InboundUserLimitOptions
 }
 
 type TUICOutboundOptions struct {
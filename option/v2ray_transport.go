@@ -15,6 +15,7 @@ type _V2RayTransportOptions struct {
 	QUICOptions        V2RayQUICOptions        `json:"-"`
 	GRPCOptions        V2RayGRPCOptions        `json:"-"`
 	HTTPUpgradeOptions V2RayHTTPUpgradeOptions `json:"-"`
+	SplitHTTPOptions   V2RaySplitHTTPOptions   `json:"-"`
 }
 
 type V2RayTransportOptions _V2RayTransportOptions
@@ -32,6 +33,8 @@ func (o V2RayTransportOptions) MarshalJSON() ([]byte, error) {
 		v = o.GRPCOptions
 	case C.V2RayTransportTypeHTTPUpgrade:
 		v = o.HTTPUpgradeOptions
+	case C.V2RayTransportTypeSplitHTTP:
+		v = o.SplitHTTPOptions
 	case "":
 		return nil, E.New("missing transport type")
 	default:
@@ -57,6 +60,8 @@ func (o *V2RayTransportOptions) UnmarshalJSON(bytes []byte) error {
 		v = &o.GRPCOptions
 	case C.V2RayTransportTypeHTTPUpgrade:
 		v = &o.HTTPUpgradeOptions
+	case C.V2RayTransportTypeSplitHTTP:
+		v = &o.SplitHTTPOptions
 	default:
 		return E.New("unknown transport type: " + o.Type)
 	}
@@ -99,3 +104,11 @@ type V2RayHTTPUpgradeOptions struct {
 	Path    string               `json:"path,omitempty"`
 	Headers badoption.HTTPHeader `json:"headers,omitempty"`
 }
+
+type V2RaySplitHTTPOptions struct {
+	Host                 string               `json:"host,omitempty"`
+	Path                 string               `json:"path,omitempty"`
+	Headers              badoption.HTTPHeader `json:"headers,omitempty"`
+	MaxUploadSize        uint32               `json:"max_upload_size,omitempty"`
+	MaxConcurrentUploads uint32               `json:"max_concurrent_uploads,omitempty"`
+}
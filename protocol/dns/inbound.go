@@ -0,0 +1,374 @@
+package dns
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/adapter/inbound"
+	"github.com/sagernet/sing-box/common/listener"
+	"github.com/sagernet/sing-box/common/tls"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/dns"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing-tun"
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/buf"
+	E "github.com/sagernet/sing/common/exceptions"
+	"github.com/sagernet/sing/common/logger"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+	"github.com/sagernet/sing/service"
+
+	mDNS "github.com/miekg/dns"
+)
+
+const dnsMessageMimeType = "application/dns-message"
+
+var ConfigureQUICListenerFunc func(listener *listener.Listener, tlsConfig tls.ServerConfig, handler QUICStreamHandler, logger logger.Logger) (io.Closer, error)
+
+type QUICStreamHandler interface {
+	NewQUICStream(ctx context.Context, stream io.ReadWriter, source M.Socksaddr) error
+}
+
+func RegisterInbound(registry *inbound.Registry) {
+	inbound.Register[option.DNSInboundOptions](registry, C.TypeDNS, NewInbound)
+}
+
+var _ QUICStreamHandler = (*Inbound)(nil)
+
+type Inbound struct {
+	inbound.Adapter
+	ctx        context.Context
+	router     adapter.DNSRouter
+	logger     log.ContextLogger
+	listener   *listener.Listener
+	mode       string
+	path       string
+	tlsConfig  tls.ServerConfig
+	httpServer *http.Server
+	quicServer io.Closer
+}
+
+func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLogger, tag string, options option.DNSInboundOptions) (adapter.Inbound, error) {
+	inbound := &Inbound{
+		Adapter: inbound.NewAdapter(C.TypeDNS, tag),
+		ctx:     ctx,
+		router:  service.FromContext[adapter.DNSRouter](ctx),
+		logger:  logger,
+		mode:    options.Mode,
+		path:    options.Path,
+	}
+	var network []string
+	switch inbound.mode {
+	case "":
+		network = []string{N.NetworkTCP, N.NetworkUDP}
+	case C.DNSTypeUDP:
+		network = []string{N.NetworkUDP}
+	case C.DNSTypeTCP:
+		network = []string{N.NetworkTCP}
+	case C.DNSTypeTLS, C.DNSTypeHTTPS:
+	case C.DNSTypeQUIC:
+		if ConfigureQUICListenerFunc == nil {
+			return nil, C.ErrQUICNotIncluded
+		}
+	default:
+		return nil, E.New("unknown DNS inbound mode: ", inbound.mode)
+	}
+	if inbound.path == "" {
+		inbound.path = "/dns-query"
+	} else if inbound.mode != C.DNSTypeHTTPS {
+		return nil, E.New("`path` is only available in https mode")
+	}
+	tlsEnabled := options.TLS != nil && options.TLS.Enabled
+	switch inbound.mode {
+	case C.DNSTypeTLS, C.DNSTypeQUIC:
+		if !tlsEnabled {
+			return nil, E.New("TLS is required for ", inbound.mode, " mode")
+		}
+	case C.DNSTypeHTTPS:
+	default:
+		if tlsEnabled {
+			return nil, E.New("TLS is not available in ", inbound.mode, " mode")
+		}
+	}
+	if tlsEnabled {
+		tlsConfig, err := tls.NewServer(ctx, logger, common.PtrValueOrDefault(options.TLS))
+		if err != nil {
+			return nil, err
+		}
+		if len(tlsConfig.NextProtos()) == 0 {
+			switch inbound.mode {
+			case C.DNSTypeTLS:
+				tlsConfig.SetNextProtos([]string{"dot"})
+			case C.DNSTypeQUIC:
+				tlsConfig.SetNextProtos([]string{"doq"})
+			}
+		}
+		inbound.tlsConfig = tlsConfig
+	}
+	inbound.listener = listener.New(listener.Options{
+		Context:           ctx,
+		Logger:            logger,
+		Network:           network,
+		Listen:            options.ListenOptions,
+		ConnectionHandler: inbound,
+		PacketHandler:     inbound,
+	})
+	return inbound, nil
+}
+
+func (i *Inbound) Start(stage adapter.StartStage) error {
+	if stage != adapter.StartStateStart {
+		return nil
+	}
+	if i.tlsConfig != nil {
+		err := i.tlsConfig.Start()
+		if err != nil {
+			return E.Cause(err, "create TLS config")
+		}
+	}
+	switch i.mode {
+	case C.DNSTypeTLS:
+		tcpListener, err := i.listener.ListenTCP()
+		if err != nil {
+			return err
+		}
+		go i.loopTLSIn(tcpListener)
+		return nil
+	case C.DNSTypeHTTPS:
+		return i.startHTTPServer()
+	case C.DNSTypeQUIC:
+		quicServer, err := ConfigureQUICListenerFunc(i.listener, i.tlsConfig, i, i.logger)
+		if err != nil {
+			return err
+		}
+		i.quicServer = quicServer
+		return nil
+	default:
+		return i.listener.Start()
+	}
+}
+
+func (i *Inbound) Close() error {
+	return common.Close(
+		i.listener,
+		common.PtrOrNil(i.httpServer),
+		i.quicServer,
+		i.tlsConfig,
+	)
+}
+
+func (i *Inbound) startHTTPServer() error {
+	tcpListener, err := i.listener.ListenTCP()
+	if err != nil {
+		return err
+	}
+	var tlsConfig *tls.STDConfig
+	if i.tlsConfig != nil {
+		if len(i.tlsConfig.NextProtos()) == 0 {
+			i.tlsConfig.SetNextProtos([]string{"h2", "http/1.1"})
+		}
+		tlsConfig, err = i.tlsConfig.Config()
+		if err != nil {
+			return err
+		}
+	}
+	i.httpServer = &http.Server{
+		Handler:   i,
+		TLSConfig: tlsConfig,
+		BaseContext: func(listener net.Listener) context.Context {
+			return i.ctx
+		},
+	}
+	go func() {
+		var sErr error
+		if tlsConfig != nil {
+			sErr = i.httpServer.ServeTLS(tcpListener, "", "")
+		} else {
+			sErr = i.httpServer.Serve(tcpListener)
+		}
+		if sErr != nil && !E.IsClosedOrCanceled(sErr) {
+			i.logger.Error("http server serve error: ", sErr)
+		}
+	}()
+	return nil
+}
+
+func (i *Inbound) loopTLSIn(tcpListener net.Listener) {
+	for {
+		conn, err := tcpListener.Accept()
+		if err != nil {
+			if !E.IsClosedOrCanceled(err) {
+				i.logger.Error("tcp listener closed: ", err)
+			}
+			return
+		}
+		go func() {
+			ctx := log.ContextWithNewID(i.ctx)
+			tlsConn, err := tls.ServerHandshake(ctx, conn, i.tlsConfig)
+			if err != nil {
+				conn.Close()
+				i.logger.ErrorContext(ctx, E.Cause(err, "process connection from ", conn.RemoteAddr(), ": TLS handshake"))
+				return
+			}
+			var metadata adapter.InboundContext
+			metadata.Source = M.SocksaddrFromNet(conn.RemoteAddr()).Unwrap()
+			metadata.OriginDestination = M.SocksaddrFromNet(conn.LocalAddr()).Unwrap()
+			i.NewConnectionEx(ctx, tlsConn, metadata, nil)
+		}()
+	}
+}
+
+func (i *Inbound) newMetadata(network string, source M.Socksaddr) adapter.InboundContext {
+	var metadata adapter.InboundContext
+	metadata.Inbound = i.Tag()
+	metadata.InboundType = i.Type()
+	metadata.Network = network
+	metadata.Source = source
+	metadata.Protocol = C.ProtocolDNS
+	return metadata
+}
+
+func (i *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
+	i.logger.InfoContext(ctx, "inbound connection from ", metadata.Source)
+	queryMetadata := i.newMetadata(N.NetworkTCP, metadata.Source)
+	for {
+		conn.SetReadDeadline(time.Now().Add(C.DNSTimeout))
+		err := HandleStreamDNSRequest(ctx, i.router, conn, queryMetadata)
+		if err != nil {
+			conn.Close()
+			if !E.IsClosedOrCanceled(err) && !errors.Is(err, io.EOF) && !E.IsTimeout(err) {
+				i.logger.ErrorContext(ctx, E.Cause(err, "process connection from ", metadata.Source))
+			}
+			if onClose != nil {
+				onClose(err)
+			}
+			return
+		}
+	}
+}
+
+func (i *Inbound) NewPacketEx(buffer *buf.Buffer, source M.Socksaddr) {
+	var message mDNS.Msg
+	err := message.Unpack(buffer.Bytes())
+	if err != nil {
+		i.logger.Error(E.Cause(err, "unpack request from ", source))
+		return
+	}
+	go func() {
+		ctx := log.ContextWithNewID(i.ctx)
+		metadata := i.newMetadata(N.NetworkUDP, source)
+		response, err := i.router.Exchange(adapter.WithContext(ctx, &metadata), &message, adapter.DNSQueryOptions{})
+		if err != nil {
+			if !errors.Is(err, tun.ErrDrop) {
+				i.logger.ErrorContext(ctx, E.Cause(err, "process packet from ", source))
+			}
+			return
+		}
+		responseBuffer, err := dns.TruncateDNSMessage(&message, response, 0)
+		if err != nil {
+			i.logger.ErrorContext(ctx, E.Cause(err, "pack response to ", source))
+			return
+		}
+		err = i.listener.PacketWriter().WritePacket(responseBuffer, source)
+		if err != nil {
+			i.logger.ErrorContext(ctx, E.Cause(err, "write response to ", source))
+		}
+	}()
+}
+
+// NewQUICStream serves a DNS-over-QUIC stream, which carries exactly one query and one response.
+func (i *Inbound) NewQUICStream(ctx context.Context, stream io.ReadWriter, source M.Socksaddr) error {
+	var queryLength uint16
+	err := binary.Read(stream, binary.BigEndian, &queryLength)
+	if err != nil {
+		return err
+	}
+	if queryLength == 0 {
+		return dns.RCodeFormatError
+	}
+	request := make([]byte, queryLength)
+	_, err = io.ReadFull(stream, request)
+	if err != nil {
+		return err
+	}
+	var message mDNS.Msg
+	err = message.Unpack(request)
+	if err != nil {
+		return err
+	}
+	metadata := i.newMetadata(N.NetworkUDP, source)
+	response, err := i.router.Exchange(adapter.WithContext(ctx, &metadata), &message, adapter.DNSQueryOptions{})
+	if err != nil {
+		return err
+	}
+	responseBuffer := buf.NewPacket()
+	defer responseBuffer.Release()
+	responseBuffer.Resize(2, 0)
+	rawResponse, err := response.PackBuffer(responseBuffer.FreeBytes())
+	if err != nil {
+		return err
+	}
+	responseBuffer.Truncate(len(rawResponse))
+	binary.BigEndian.PutUint16(responseBuffer.ExtendHeader(2), uint16(len(rawResponse)))
+	_, err = stream.Write(responseBuffer.Bytes())
+	return err
+}
+
+func (i *Inbound) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != i.path {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var (
+		request []byte
+		err     error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		request, err = base64.RawURLEncoding.DecodeString(r.URL.Query().Get("dns"))
+	case http.MethodPost:
+		if r.Header.Get("Content-Type") != dnsMessageMimeType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		request, err = io.ReadAll(io.LimitReader(r.Body, mDNS.MaxMsgSize))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var message mDNS.Msg
+	if err == nil {
+		err = message.Unpack(request)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	ctx := log.ContextWithNewID(r.Context())
+	metadata := i.newMetadata(N.NetworkTCP, M.ParseSocksaddr(r.RemoteAddr).Unwrap())
+	i.logger.DebugContext(ctx, "inbound HTTP request from ", metadata.Source)
+	response, err := i.router.Exchange(adapter.WithContext(ctx, &metadata), &message, adapter.DNSQueryOptions{})
+	if err != nil {
+		if !errors.Is(err, tun.ErrDrop) {
+			i.logger.ErrorContext(ctx, E.Cause(err, "process HTTP request from ", metadata.Source))
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	rawResponse, err := response.Pack()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", dnsMessageMimeType)
+	_, _ = w.Write(rawResponse)
+}
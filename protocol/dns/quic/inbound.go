@@ -0,0 +1,70 @@
+package quic
+
+import (
+	"context"
+	"io"
+
+	"github.com/sagernet/quic-go"
+	"github.com/sagernet/sing-box/common/listener"
+	"github.com/sagernet/sing-box/common/tls"
+	"github.com/sagernet/sing-box/log"
+	protocolDNS "github.com/sagernet/sing-box/protocol/dns"
+	"github.com/sagernet/sing-quic"
+	E "github.com/sagernet/sing/common/exceptions"
+	"github.com/sagernet/sing/common/logger"
+	M "github.com/sagernet/sing/common/metadata"
+)
+
+func init() {
+	protocolDNS.ConfigureQUICListenerFunc = func(listener *listener.Listener, tlsConfig tls.ServerConfig, handler protocolDNS.QUICStreamHandler, logger logger.Logger) (io.Closer, error) {
+		udpConn, err := listener.ListenUDP()
+		if err != nil {
+			return nil, err
+		}
+		quicListener, err := qtls.ListenEarly(udpConn, tlsConfig, &quic.Config{
+			MaxIncomingStreams: 1 << 60,
+			Allow0RTT:          true,
+		})
+		if err != nil {
+			udpConn.Close()
+			return nil, err
+		}
+		go func() {
+			for {
+				conn, err := quicListener.Accept(context.Background())
+				if err != nil {
+					udpConn.Close()
+					if !E.IsClosedOrCanceled(err) {
+						logger.Error("quic listener closed: ", err)
+					}
+					return
+				}
+				go serveConnection(conn, handler, logger)
+			}
+		}()
+		return quicListener, nil
+	}
+}
+
+func serveConnection(conn quic.EarlyConnection, handler protocolDNS.QUICStreamHandler, logger logger.Logger) {
+	source := M.SocksaddrFromNet(conn.RemoteAddr()).Unwrap()
+	for {
+		stream, err := conn.AcceptStream(conn.Context())
+		if err != nil {
+			return
+		}
+		go func() {
+			ctx := log.ContextWithNewID(conn.Context())
+			err := handler.NewQUICStream(ctx, stream, source)
+			if err != nil {
+				stream.CancelRead(0)
+				stream.CancelWrite(0)
+				if !E.IsClosedOrCanceled(err) {
+					logger.Error(E.Cause(err, "process DNS stream from ", source))
+				}
+				return
+			}
+			stream.Close()
+		}()
+	}
+}
@@ -0,0 +1,354 @@
+package group
+
+import (
+	"context"
+	"hash/fnv"
+	"net"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/adapter/outbound"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/atomic"
+	E "github.com/sagernet/sing/common/exceptions"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+	"github.com/sagernet/sing/contrab/freelru"
+	"github.com/sagernet/sing/contrab/maphash"
+	"github.com/sagernet/sing/service"
+
+	R "github.com/dlclark/regexp2"
+)
+
+func RegisterLoadBalance(registry *outbound.Registry) {
+	outbound.Register[option.LoadBalanceOutboundOptions](registry, C.TypeLoadBalance, NewLoadBalance)
+}
+
+var (
+	_ adapter.OutboundGroup             = (*LoadBalance)(nil)
+	_ adapter.URLTestGroup              = (*LoadBalance)(nil)
+	_ adapter.InterfaceUpdateListener   = (*LoadBalance)(nil)
+	_ adapter.ConnectionHandlerEx       = (*LoadBalance)(nil)
+	_ adapter.PacketConnectionHandlerEx = (*LoadBalance)(nil)
+)
+
+type LoadBalance struct {
+	outbound.Adapter
+	router     adapter.Router
+	outbound   adapter.OutboundManager
+	provider   adapter.OutboundProviderManager
+	connection adapter.ConnectionManager
+	logger     log.ContextLogger
+	myGroupAdapter
+	strategy    string
+	link        string
+	interval    time.Duration
+	idleTimeout time.Duration
+	ttl         time.Duration
+	group       *URLTestGroup
+	index       atomic.Uint32
+	last        atomic.TypedValue[adapter.Outbound]
+	sessions    freelru.Cache[string, string]
+}
+
+func NewLoadBalance(ctx context.Context, router adapter.Router, logger log.ContextLogger, tag string, options option.LoadBalanceOutboundOptions) (adapter.Outbound, error) {
+	outbound := &LoadBalance{
+		Adapter:    outbound.NewAdapter(C.TypeLoadBalance, tag, []string{N.NetworkTCP, N.NetworkUDP}, options.Outbounds),
+		router:     router,
+		outbound:   service.FromContext[adapter.OutboundManager](ctx),
+		provider:   service.FromContext[adapter.OutboundProviderManager](ctx),
+		connection: service.FromContext[adapter.ConnectionManager](ctx),
+		logger:     logger,
+		myGroupAdapter: myGroupAdapter{
+			ctx:             ctx,
+			tags:            options.Outbounds,
+			uses:            options.Providers,
+			useAllProviders: options.UseAllProviders,
+			types:           options.Types,
+			ports:           make(map[int]bool),
+			providers:       make(map[string]adapter.OutboundProvider),
+		},
+		strategy:    options.Strategy,
+		link:        options.URL,
+		interval:    time.Duration(options.Interval),
+		idleTimeout: time.Duration(options.IdleTimeout),
+		ttl:         time.Duration(options.TTL),
+	}
+	if len(outbound.tags) == 0 && len(outbound.uses) == 0 && !outbound.useAllProviders {
+		return nil, E.New("missing tags and uses")
+	}
+	switch outbound.strategy {
+	case "":
+		outbound.strategy = C.LoadBalanceStrategyRoundRobin
+	case C.LoadBalanceStrategyRoundRobin, C.LoadBalanceStrategyConsistentHashing:
+	case C.LoadBalanceStrategyStickySessions:
+		if outbound.ttl == 0 {
+			outbound.ttl = 10 * time.Minute
+		}
+		outbound.sessions = common.Must1(freelru.NewSharded[string, string](1024, maphash.NewHasher[string]().Hash32))
+		outbound.sessions.SetLifetime(outbound.ttl)
+	default:
+		return nil, E.New("unknown load-balance strategy: ", outbound.strategy)
+	}
+	if len(options.Includes) > 0 {
+		includes := make([]*R.Regexp, 0, len(options.Includes))
+		for i, include := range options.Includes {
+			regex, err := R.Compile(include, R.IgnoreCase)
+			if err != nil {
+				return nil, E.Cause(err, "parse includes[", i, "]")
+			}
+			includes = append(includes, regex)
+		}
+		outbound.includes = includes
+	}
+	if options.Excludes != "" {
+		regex, err := R.Compile(options.Excludes, R.IgnoreCase)
+		if err != nil {
+			return nil, E.Cause(err, "parse excludes")
+		}
+		outbound.excludes = regex
+	}
+	if !CheckType(outbound.types) {
+		return nil, E.New("invalid types")
+	}
+	if portMap, err := CreatePortsMap(options.Ports); err == nil {
+		outbound.ports = portMap
+	} else {
+		return nil, err
+	}
+	return outbound, nil
+}
+
+func (s *LoadBalance) pickOutbounds() ([]adapter.Outbound, error) {
+	outbounds := []adapter.Outbound{}
+	for i, tag := range s.tags {
+		detour, loaded := s.outbound.Outbound(tag)
+		if !loaded {
+			return nil, E.New("outbound ", i, " not found: ", tag)
+		}
+		outbounds = append(outbounds, detour)
+	}
+	for i, tag := range s.uses {
+		provider, loaded := s.provider.OutboundProvider(tag)
+		if !loaded {
+			return nil, E.New("provider ", i, " not found: ", tag)
+		}
+		if _, ok := s.providers[tag]; !ok {
+			s.providers[tag] = provider
+		}
+		for _, outbound := range provider.Outbounds() {
+			if !s.OutboundFilter(outbound) {
+				continue
+			}
+			outbounds = append(outbounds, outbound)
+		}
+	}
+	if len(outbounds) == 0 {
+		OUTBOUNDLESS, _ := s.outbound.Outbound("OUTBOUNDLESS")
+		outbounds = append(outbounds, OUTBOUNDLESS)
+	}
+	return outbounds, nil
+}
+
+func (s *LoadBalance) Start() error {
+	if s.useAllProviders {
+		uses := []string{}
+		for _, provider := range s.provider.OutboundProviders() {
+			uses = append(uses, provider.Tag())
+		}
+		s.uses = uses
+	}
+	outbounds, err := s.pickOutbounds()
+	if err != nil {
+		return err
+	}
+	group, err := NewURLTestGroup(s.ctx, s.outbound, s.provider, s.logger, outbounds, s.link, s.interval, 0, s.idleTimeout, false)
+	if err != nil {
+		return err
+	}
+	s.group = group
+	return nil
+}
+
+func (s *LoadBalance) UpdateOutbounds(tag string) error {
+	if _, ok := s.providers[tag]; ok {
+		outbounds, err := s.pickOutbounds()
+		if err != nil {
+			return E.New("update outbounds failed: ", s.Tag(), ", with reason: ", err)
+		}
+		s.group.outbounds = outbounds
+		if s.sessions != nil {
+			s.sessions.Purge()
+		}
+	}
+	return nil
+}
+
+func (s *LoadBalance) PostStart() error {
+	s.group.PostStart()
+	return nil
+}
+
+func (s *LoadBalance) Close() error {
+	return common.Close(
+		common.PtrOrNil(s.group),
+	)
+}
+
+func (s *LoadBalance) Now() string {
+	if last := s.last.Load(); last != nil {
+		return last.Tag()
+	}
+	return ""
+}
+
+func (s *LoadBalance) All() []string {
+	var all []string
+	for _, outbound := range s.group.outbounds {
+		all = append(all, outbound.Tag())
+	}
+	return all
+}
+
+func (s *LoadBalance) URLTest(ctx context.Context) (map[string]uint16, error) {
+	return s.group.URLTest(ctx)
+}
+
+func (s *LoadBalance) PerformUpdateCheck(tag string, force bool) {
+	// members are selected per connection, so there is no selection to refresh
+}
+
+func (s *LoadBalance) InterfaceUpdated() {
+	go s.group.CheckOutbounds(true)
+}
+
+// availableOutbounds returns the members that support network and are not marked as failed.
+// Until the first check finishes no member has history, so all of them are returned.
+func (s *LoadBalance) availableOutbounds(network string) []adapter.Outbound {
+	var supported, available []adapter.Outbound
+	for _, detour := range s.group.outbounds {
+		if !common.Contains(detour.Network(), network) {
+			continue
+		}
+		supported = append(supported, detour)
+		if s.group.history.LoadURLTestHistory(RealTag(detour)) != nil {
+			available = append(available, detour)
+		}
+	}
+	if len(available) == 0 {
+		return supported
+	}
+	return available
+}
+
+func (s *LoadBalance) selectOutbound(ctx context.Context, network string, destination M.Socksaddr) adapter.Outbound {
+	outbounds := s.availableOutbounds(network)
+	if len(outbounds) == 0 {
+		return nil
+	}
+	var selected adapter.Outbound
+	switch s.strategy {
+	case C.LoadBalanceStrategyConsistentHashing:
+		selected = selectByHash(outbounds, hashKey(ctx, destination, false))
+	case C.LoadBalanceStrategyStickySessions:
+		key := hashKey(ctx, destination, true)
+		if tag, loaded := s.sessions.GetAndRefresh(key); loaded {
+			selected = common.Find(outbounds, func(it adapter.Outbound) bool {
+				return it.Tag() == tag
+			})
+		}
+		if selected == nil {
+			selected = outbounds[int(s.index.Add(1)-1)%len(outbounds)]
+			s.sessions.Add(key, selected.Tag())
+		}
+	default:
+		selected = outbounds[int(s.index.Add(1)-1)%len(outbounds)]
+	}
+	s.last.Store(selected)
+	return selected
+}
+
+func (s *LoadBalance) DialContext(ctx context.Context, network string, destination M.Socksaddr) (net.Conn, error) {
+	s.group.Touch()
+	switch N.NetworkName(network) {
+	case N.NetworkTCP, N.NetworkUDP:
+	default:
+		return nil, E.Extend(N.ErrUnknownNetwork, network)
+	}
+	outbound := s.selectOutbound(ctx, N.NetworkName(network), destination)
+	if outbound == nil {
+		return nil, E.New("missing supported outbound")
+	}
+	conn, err := outbound.DialContext(ctx, network, destination)
+	if err == nil {
+		return conn, nil
+	}
+	s.logger.ErrorContext(ctx, err)
+	s.group.history.DeleteURLTestHistory(RealTag(outbound))
+	return nil, err
+}
+
+func (s *LoadBalance) ListenPacket(ctx context.Context, destination M.Socksaddr) (net.PacketConn, error) {
+	s.group.Touch()
+	outbound := s.selectOutbound(ctx, N.NetworkUDP, destination)
+	if outbound == nil {
+		return nil, E.New("missing supported outbound")
+	}
+	conn, err := outbound.ListenPacket(ctx, destination)
+	if err == nil {
+		return conn, nil
+	}
+	s.logger.ErrorContext(ctx, err)
+	s.group.history.DeleteURLTestHistory(RealTag(outbound))
+	return nil, err
+}
+
+func (s *LoadBalance) NewConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
+	s.connection.NewConnection(ctx, s, conn, metadata, onClose)
+}
+
+func (s *LoadBalance) NewPacketConnectionEx(ctx context.Context, conn N.PacketConn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
+	s.connection.NewPacketConnection(ctx, s, conn, metadata, onClose)
+}
+
+// hashKey builds the key used by hash-based strategies: the destination domain (or IP),
+// prefixed with the source IP when withSource is set.
+func hashKey(ctx context.Context, destination M.Socksaddr, withSource bool) string {
+	var key string
+	metadata := adapter.ContextFrom(ctx)
+	if metadata != nil && metadata.Domain != "" {
+		key = metadata.Domain
+	} else if destination.IsFqdn() {
+		key = destination.Fqdn
+	} else if metadata != nil && metadata.Destination.IsValid() {
+		key = metadata.Destination.AddrString()
+	} else {
+		key = destination.AddrString()
+	}
+	if withSource && metadata != nil && metadata.Source.IsValid() {
+		key = metadata.Source.Addr.String() + "|" + key
+	}
+	return key
+}
+
+// selectByHash uses rendezvous hashing, so only keys mapped to a removed member move.
+func selectByHash(outbounds []adapter.Outbound, key string) adapter.Outbound {
+	var (
+		selected  adapter.Outbound
+		maxWeight uint64
+	)
+	for _, detour := range outbounds {
+		hasher := fnv.New64a()
+		hasher.Write([]byte(key))
+		hasher.Write([]byte(detour.Tag()))
+		weight := hasher.Sum64()
+		if selected == nil || weight > maxWeight {
+			selected = detour
+			maxWeight = weight
+		}
+	}
+	return selected
+}
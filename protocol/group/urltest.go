@@ -290,6 +290,7 @@ type URLTestGroup struct {
 	selectedOutboundUDP          adapter.Outbound
 	interruptGroup               *interrupt.Group
 	interruptExternalConnections bool
+	fallback                     bool
 
 	access     sync.Mutex
 	ticker     *time.Ticker
@@ -384,6 +385,9 @@ func (g *URLTestGroup) Close() error {
 }
 
 func (g *URLTestGroup) Select(network string) (adapter.Outbound, bool) {
+	if g.fallback {
+		return g.selectFallback(network)
+	}
 	var minDelay uint16
 	var minOutbound adapter.Outbound
 	switch network {
@@ -427,6 +431,23 @@ func (g *URLTestGroup) Select(network string) (adapter.Outbound, bool) {
 	return minOutbound, true
 }
 
+// selectFallback returns the first available outbound in configuration order.
+func (g *URLTestGroup) selectFallback(network string) (adapter.Outbound, bool) {
+	var firstOutbound adapter.Outbound
+	for _, detour := range g.outbounds {
+		if !common.Contains(detour.Network(), network) {
+			continue
+		}
+		if g.history.LoadURLTestHistory(RealTag(detour)) != nil {
+			return detour, true
+		}
+		if firstOutbound == nil {
+			firstOutbound = detour
+		}
+	}
+	return firstOutbound, false
+}
+
 func (g *URLTestGroup) loopCheck() {
 	if time.Now().Sub(g.lastActive.Load()) > g.interval {
 		g.lastActive.Store(time.Now())
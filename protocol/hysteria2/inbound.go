@@ -10,6 +10,7 @@ import (
 
 	"github.com/sagernet/sing-box/adapter"
 	"github.com/sagernet/sing-box/adapter/inbound"
+	"github.com/sagernet/sing-box/common/inbounduser"
 	"github.com/sagernet/sing-box/common/listener"
 	"github.com/sagernet/sing-box/common/tls"
 	C "github.com/sagernet/sing-box/constant"
@@ -28,14 +29,16 @@ func RegisterInbound(registry *inbound.Registry) {
 	inbound.Register[option.Hysteria2InboundOptions](registry, C.TypeHysteria2, NewInbound)
 }
 
+var _ adapter.ManagedUserInbound = (*Inbound)(nil)
+
 type Inbound struct {
 	inbound.Adapter
-	router       adapter.Router
-	logger       log.ContextLogger
-	listener     *listener.Listener
-	tlsConfig    tls.ServerConfig
-	service      *hysteria2.Service[int]
-	userNameList []string
+	router    adapter.Router
+	logger    log.ContextLogger
+	listener  *listener.Listener
+	tlsConfig tls.ServerConfig
+	service   *hysteria2.Service[int]
+	users     *inbounduser.Users[option.Hysteria2User]
 }
 
 func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLogger, tag string, options option.Hysteria2InboundOptions) (adapter.Inbound, error) {
@@ -129,20 +132,59 @@ func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLo
 	if err != nil {
 		return nil, err
 	}
-	userList := make([]int, 0, len(options.Users))
-	userNameList := make([]string, 0, len(options.Users))
-	userPasswordList := make([]string, 0, len(options.Users))
-	for index, user := range options.Users {
-		userList = append(userList, index)
-		userNameList = append(userNameList, user.Name)
-		userPasswordList = append(userPasswordList, user.Password)
+	inbound.users, err = inbounduser.New(options.Users, func(it option.Hysteria2User) string {
+		return it.Name
+	}, func(userIDs []int, users []option.Hysteria2User) error {
+		// UpdateUsers replaces the user map of the running service,
+		// so established QUIC connections are kept.
+		service.UpdateUsers(userIDs, common.Map(users, func(it option.Hysteria2User) string {
+			return it.Password
+		}))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	service.UpdateUsers(userList, userPasswordList)
 	inbound.service = service
-	inbound.userNameList = userNameList
 	return inbound, nil
 }
 
+func (h *Inbound) AddUser(user adapter.InboundUser) error {
+	if user.Password == "" {
+		return E.New("missing password")
+	}
+	return h.users.Add(option.Hysteria2User{
+		Name:                    user.Name,
+		Password:                user.Password,
+		InboundUserLimitOptions: inbounduser.LimitOptions(user),
+	})
+}
+
+func (h *Inbound) RemoveUser(name string) error {
+	return h.users.Remove(name)
+}
+
+func (h *Inbound) User(name string) (adapter.InboundUser, bool) {
+	user, loaded := h.users.Get(name)
+	if !loaded {
+		return adapter.InboundUser{}, false
+	}
+	return newInboundUser(user), true
+}
+
+func (h *Inbound) ListUsers() []adapter.InboundUser {
+	return common.Map(h.users.List(), newInboundUser)
+}
+
+func newInboundUser(it option.Hysteria2User) adapter.InboundUser {
+	user := adapter.InboundUser{
+		Name:     it.Name,
+		Password: it.Password,
+	}
+	inbounduser.SetLimitOptions(&user, it.InboundUserLimitOptions)
+	return user
+}
+
 func (h *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, source M.Socksaddr, destination M.Socksaddr, onClose N.CloseHandlerFunc) {
 	ctx = log.ContextWithNewID(ctx)
 	var metadata adapter.InboundContext
@@ -157,7 +199,12 @@ func (h *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, source M.S
 	metadata.Destination = destination
 	h.logger.InfoContext(ctx, "inbound connection from ", metadata.Source)
 	userID, _ := auth.UserFromContext[int](ctx)
-	if userName := h.userNameList[userID]; userName != "" {
+	user, loaded := h.users.User(userID)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userID, " removed"))
+		return
+	}
+	if userName := user.Name; userName != "" {
 		metadata.User = userName
 		h.logger.InfoContext(ctx, "[", userName, "] inbound connection to ", metadata.Destination)
 	} else {
@@ -180,7 +227,12 @@ func (h *Inbound) NewPacketConnectionEx(ctx context.Context, conn N.PacketConn,
 	metadata.Destination = destination
 	h.logger.InfoContext(ctx, "inbound packet connection from ", metadata.Source)
 	userID, _ := auth.UserFromContext[int](ctx)
-	if userName := h.userNameList[userID]; userName != "" {
+	user, loaded := h.users.User(userID)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userID, " removed"))
+		return
+	}
+	if userName := user.Name; userName != "" {
 		metadata.User = userName
 		h.logger.InfoContext(ctx, "[", userName, "] inbound packet connection to ", metadata.Destination)
 	} else {
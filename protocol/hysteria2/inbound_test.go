@@ -0,0 +1,144 @@
+package hysteria2
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/common/tls"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing-quic/hysteria2"
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/json/badoption"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRouter struct {
+	adapter.Router
+	users chan string
+}
+
+func (r *testRouter) RouteConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
+	r.users <- metadata.User
+	go func() {
+		_, err := io.Copy(conn, conn)
+		conn.Close()
+		if onClose != nil {
+			onClose(err)
+		}
+	}()
+}
+
+func TestInboundUpdateUsers(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	privateKeyPem, certificatePem, err := tls.GenerateCertificate(nil, nil, time.Now, "example.org", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	serverAddress := M.SocksaddrFromNet(packetConn.LocalAddr())
+	packetConn.Close()
+	router := &testRouter{users: make(chan string, 1)}
+	inbound, err := NewInbound(ctx, router, log.NewNOPFactory().Logger(), "hysteria2-in", option.Hysteria2InboundOptions{
+		ListenOptions: option.ListenOptions{
+			Listen:     common.Ptr(badoption.Addr(netip.AddrFrom4([4]byte{127, 0, 0, 1}))),
+			ListenPort: serverAddress.Port,
+		},
+		Users: []option.Hysteria2User{{Name: "alice", Password: "alice password"}},
+		InboundTLSOptionsContainer: option.InboundTLSOptionsContainer{
+			TLS: &option.InboundTLSOptions{
+				Enabled:     true,
+				Certificate: []string{string(certificatePem)},
+				Key:         []string{string(privateKeyPem)},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, inbound.Start(adapter.StartStateStart))
+	defer inbound.Close()
+	managedInbound := inbound.(adapter.ManagedUserInbound)
+
+	newClient := func(password string) *hysteria2.Client {
+		tlsConfig, err := tls.NewClient(ctx, serverAddress.String(), option.OutboundTLSOptions{
+			Enabled:    true,
+			ServerName: "example.org",
+			Insecure:   true,
+		})
+		require.NoError(t, err)
+		client, err := hysteria2.NewClient(hysteria2.ClientOptions{
+			Context:       ctx,
+			Dialer:        N.SystemDialer,
+			Logger:        log.NewNOPFactory().Logger(),
+			ServerAddress: serverAddress,
+			Password:      password,
+			TLSConfig:     tlsConfig,
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			client.CloseWithError(nil)
+		})
+		return client
+	}
+	dial := func(client *hysteria2.Client, user string) net.Conn {
+		conn, err := client.DialConn(ctx, M.ParseSocksaddr("example.com:80"))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			conn.Close()
+		})
+		require.NoError(t, echo(conn))
+		select {
+		case routedUser := <-router.users:
+			require.Equal(t, user, routedUser)
+		case <-time.After(5 * time.Second):
+			t.Fatal("connection not routed")
+		}
+		return conn
+	}
+	dialRejected := func(client *hysteria2.Client) {
+		conn, err := client.DialConn(ctx, M.ParseSocksaddr("example.com:80"))
+		if err == nil {
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+			err = echo(conn)
+		}
+		require.Error(t, err)
+	}
+
+	aliceConn := dial(newClient("alice password"), "alice")
+	require.NoError(t, managedInbound.AddUser(adapter.InboundUser{Name: "bob", Password: "bob password"}))
+	// the QUIC connection of alice is kept when bob is added
+	require.NoError(t, echo(aliceConn))
+	bobClient := newClient("bob password")
+	dial(bobClient, "bob")
+
+	require.NoError(t, managedInbound.RemoveUser("bob"))
+	dialRejected(newClient("bob password"))
+	// new streams of a removed user are rejected
+	dialRejected(bobClient)
+	require.NoError(t, echo(aliceConn))
+}
+
+func echo(conn net.Conn) error {
+	_, err := conn.Write([]byte("ping"))
+	if err != nil {
+		return err
+	}
+	response := make([]byte, 4)
+	_, err = io.ReadFull(conn, response)
+	if err != nil {
+		return err
+	}
+	if string(response) != "ping" {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
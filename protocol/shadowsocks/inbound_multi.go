@@ -8,6 +8,7 @@ import (
 
 	"github.com/sagernet/sing-box/adapter"
 	"github.com/sagernet/sing-box/adapter/inbound"
+	"github.com/sagernet/sing-box/common/inbounduser"
 	"github.com/sagernet/sing-box/common/listener"
 	"github.com/sagernet/sing-box/common/mux"
 	"github.com/sagernet/sing-box/common/uot"
@@ -18,6 +19,7 @@ import (
 	"github.com/sagernet/sing-shadowsocks/shadowaead"
 	"github.com/sagernet/sing-shadowsocks/shadowaead_2022"
 	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/atomic"
 	"github.com/sagernet/sing/common/auth"
 	"github.com/sagernet/sing/common/buf"
 	E "github.com/sagernet/sing/common/exceptions"
@@ -28,7 +30,10 @@ import (
 	"github.com/sagernet/sing/common/ntp"
 )
 
-var _ adapter.TCPInjectableInbound = (*MultiInbound)(nil)
+var (
+	_ adapter.TCPInjectableInbound = (*MultiInbound)(nil)
+	_ adapter.ManagedUserInbound   = (*MultiInbound)(nil)
+)
 
 type MultiInbound struct {
 	inbound.Adapter
@@ -36,8 +41,8 @@ type MultiInbound struct {
 	router   adapter.ConnectionRouterEx
 	logger   logger.ContextLogger
 	listener *listener.Listener
-	service  shadowsocks.MultiService[int]
-	users    []option.ShadowsocksUser
+	service  atomic.TypedValue[shadowsocks.MultiService[int]]
+	users    *inbounduser.Users[option.ShadowsocksUser]
 }
 
 func newMultiInbound(ctx context.Context, router adapter.Router, logger log.ContextLogger, tag string, options option.ShadowsocksInboundOptions) (*MultiInbound, error) {
@@ -58,37 +63,57 @@ func newMultiInbound(ctx context.Context, router adapter.Router, logger log.Cont
 	} else {
 		udpTimeout = C.UDPTimeout
 	}
-	var service shadowsocks.MultiService[int]
+	var newService func() (shadowsocks.MultiService[int], error)
 	if common.Contains(shadowaead_2022.List, options.Method) {
-		service, err = shadowaead_2022.NewMultiServiceWithPassword[int](
+		service, err := shadowaead_2022.NewMultiServiceWithPassword[int](
 			options.Method,
 			options.Password,
 			int64(udpTimeout.Seconds()),
 			adapter.NewUpstreamHandler(adapter.InboundContext{}, inbound.newConnection, inbound.newPacketConnection, inbound),
 			ntp.TimeFuncFromContext(ctx),
 		)
+		if err != nil {
+			return nil, err
+		}
+		inbound.service.Store(service)
 	} else if common.Contains(shadowaead.List, options.Method) {
-		service, err = shadowaead.NewMultiService[int](
-			options.Method,
-			int64(udpTimeout.Seconds()),
-			adapter.NewUpstreamHandler(adapter.InboundContext{}, inbound.newConnection, inbound.newPacketConnection, inbound),
-		)
+		newService = func() (shadowsocks.MultiService[int], error) {
+			return shadowaead.NewMultiService[int](
+				options.Method,
+				int64(udpTimeout.Seconds()),
+				adapter.NewUpstreamHandler(adapter.InboundContext{}, inbound.newConnection, inbound.newPacketConnection, inbound),
+			)
+		}
 	} else {
 		return nil, E.New("unsupported method: " + options.Method)
 	}
+	inbound.users, err = inbounduser.New(options.Users, func(user option.ShadowsocksUser) string {
+		return user.Name
+	}, func(userIDs []int, users []option.ShadowsocksUser) error {
+		passwordList := common.Map(users, func(user option.ShadowsocksUser) string {
+			return user.Password
+		})
+		if newService == nil {
+			// UpdateUsersWithPasswords replaces the user maps of the running service,
+			// so its salt replay filter and UDP sessions are kept.
+			return inbound.service.Load().UpdateUsersWithPasswords(userIDs, passwordList)
+		}
+		// shadowaead.MultiService fills its user map in place while connections iterate it,
+		// so a new service is created for each update.
+		service, err := newService()
+		if err != nil {
+			return err
+		}
+		err = service.UpdateUsersWithPasswords(userIDs, passwordList)
+		if err != nil {
+			return err
+		}
+		inbound.service.Store(service)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	err = service.UpdateUsersWithPasswords(common.MapIndexed(options.Users, func(index int, user option.ShadowsocksUser) int {
-		return index
-	}), common.Map(options.Users, func(user option.ShadowsocksUser) string {
-		return user.Password
-	}))
-	if err != nil {
-		return nil, err
-	}
-	inbound.service = service
-	inbound.users = options.Users
 	inbound.listener = listener.New(listener.Options{
 		Context:                  ctx,
 		Logger:                   logger,
@@ -114,7 +139,7 @@ func (h *MultiInbound) Close() error {
 
 //nolint:staticcheck
 func (h *MultiInbound) NewConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
-	err := h.service.NewConnection(ctx, conn, adapter.UpstreamMetadata(metadata))
+	err := h.service.Load().NewConnection(ctx, conn, adapter.UpstreamMetadata(metadata))
 	N.CloseOnHandshakeFailure(conn, onClose, err)
 	if err != nil {
 		if E.IsClosedOrCanceled(err) {
@@ -127,7 +152,7 @@ func (h *MultiInbound) NewConnectionEx(ctx context.Context, conn net.Conn, metad
 
 //nolint:staticcheck
 func (h *MultiInbound) NewPacketEx(buffer *buf.Buffer, source M.Socksaddr) {
-	err := h.service.NewPacket(h.ctx, &stubPacketConn{h.listener.PacketWriter()}, buffer, M.Metadata{Source: source})
+	err := h.service.Load().NewPacket(h.ctx, &stubPacketConn{h.listener.PacketWriter()}, buffer, M.Metadata{Source: source})
 	if err != nil {
 		h.logger.Error(E.Cause(err, "process packet from ", source))
 	}
@@ -138,7 +163,11 @@ func (h *MultiInbound) newConnection(ctx context.Context, conn net.Conn, metadat
 	if !loaded {
 		return os.ErrInvalid
 	}
-	user := h.users[userIndex].Name
+	ssUser, loaded := h.users.User(userIndex)
+	if !loaded {
+		return E.New("user ", userIndex, " removed")
+	}
+	user := ssUser.Name
 	if user == "" {
 		user = F.ToString(userIndex)
 	} else {
@@ -159,7 +188,11 @@ func (h *MultiInbound) newPacketConnection(ctx context.Context, conn N.PacketCon
 	if !loaded {
 		return os.ErrInvalid
 	}
-	user := h.users[userIndex].Name
+	ssUser, loaded := h.users.User(userIndex)
+	if !loaded {
+		return E.New("user ", userIndex, " removed")
+	}
+	user := ssUser.Name
 	if user == "" {
 		user = F.ToString(userIndex)
 	} else {
@@ -181,3 +214,39 @@ func (h *MultiInbound) newPacketConnection(ctx context.Context, conn N.PacketCon
 func (h *MultiInbound) NewError(ctx context.Context, err error) {
 	NewError(h.logger, ctx, err)
 }
+
+func (h *MultiInbound) AddUser(user adapter.InboundUser) error {
+	if user.Password == "" {
+		return E.New("missing password")
+	}
+	return h.users.Add(option.ShadowsocksUser{
+		Name:                    user.Name,
+		Password:                user.Password,
+		InboundUserLimitOptions: inbounduser.LimitOptions(user),
+	})
+}
+
+func (h *MultiInbound) RemoveUser(name string) error {
+	return h.users.Remove(name)
+}
+
+func (h *MultiInbound) User(name string) (adapter.InboundUser, bool) {
+	user, loaded := h.users.Get(name)
+	if !loaded {
+		return adapter.InboundUser{}, false
+	}
+	return newInboundUser(user), true
+}
+
+func (h *MultiInbound) ListUsers() []adapter.InboundUser {
+	return common.Map(h.users.List(), newInboundUser)
+}
+
+func newInboundUser(it option.ShadowsocksUser) adapter.InboundUser {
+	user := adapter.InboundUser{
+		Name:     it.Name,
+		Password: it.Password,
+	}
+	inbounduser.SetLimitOptions(&user, it.InboundUserLimitOptions)
+	return user
+}
@@ -0,0 +1,124 @@
+package shadowsocks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing-shadowsocks"
+	"github.com/sagernet/sing-shadowsocks/shadowaead"
+	"github.com/sagernet/sing-shadowsocks/shadowaead_2022"
+	"github.com/sagernet/sing/common"
+	M "github.com/sagernet/sing/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRouter struct {
+	adapter.Router
+	users chan string
+}
+
+func (r *testRouter) RouteConnection(ctx context.Context, conn net.Conn, metadata adapter.InboundContext) error {
+	r.users <- metadata.User
+	defer conn.Close()
+	_, err := io.Copy(conn, conn)
+	return err
+}
+
+func TestMultiInboundUpdateUsers(t *testing.T) {
+	t.Parallel()
+	newPassword := func() string {
+		var key [16]byte
+		common.Must1(rand.Read(key[:]))
+		return base64.StdEncoding.EncodeToString(key[:])
+	}
+	for _, method := range []string{"2022-blake3-aes-128-gcm", "aes-128-gcm"} {
+		t.Run(method, func(t *testing.T) {
+			t.Parallel()
+			var serverPassword string
+			if method == "2022-blake3-aes-128-gcm" {
+				serverPassword = newPassword()
+			}
+			router := &testRouter{users: make(chan string, 1)}
+			alicePassword := newPassword()
+			bobPassword := newPassword()
+			inbound, err := NewInbound(context.Background(), router, log.NewNOPFactory().Logger(), "shadowsocks-in", option.ShadowsocksInboundOptions{
+				Method:   method,
+				Password: serverPassword,
+				Users:    []option.ShadowsocksUser{{Name: "alice", Password: alicePassword}},
+			})
+			require.NoError(t, err)
+			managedInbound := inbound.(adapter.ManagedUserInbound)
+
+			dial := func(password string) (net.Conn, error) {
+				var (
+					clientMethod shadowsocks.Method
+					err          error
+				)
+				if serverPassword != "" {
+					clientMethod, err = shadowaead_2022.NewWithPassword(method, serverPassword+":"+password, nil)
+				} else {
+					clientMethod, err = shadowaead.New(method, nil, password)
+				}
+				require.NoError(t, err)
+				serverConn, clientConn := net.Pipe()
+				t.Cleanup(func() {
+					clientConn.Close()
+				})
+				go inbound.(adapter.TCPInjectableInbound).NewConnectionEx(context.Background(), serverConn, adapter.InboundContext{}, func(error) {})
+				clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+				conn, err := clientMethod.DialConn(clientConn, M.ParseSocksaddr("example.com:80"))
+				if err != nil {
+					return nil, err
+				}
+				return conn, echo(conn)
+			}
+			requireUser := func(user string) {
+				select {
+				case routedUser := <-router.users:
+					require.Equal(t, user, routedUser)
+				case <-time.After(5 * time.Second):
+					t.Fatal("connection not routed")
+				}
+			}
+
+			aliceConn, err := dial(alicePassword)
+			require.NoError(t, err)
+			requireUser("alice")
+			require.NoError(t, managedInbound.AddUser(adapter.InboundUser{Name: "bob", Password: bobPassword}))
+			require.NoError(t, echo(aliceConn))
+			_, err = dial(bobPassword)
+			require.NoError(t, err)
+			requireUser("bob")
+
+			require.NoError(t, managedInbound.RemoveUser("bob"))
+			_, err = dial(bobPassword)
+			require.Error(t, err)
+			require.NoError(t, echo(aliceConn))
+		})
+	}
+}
+
+func echo(conn net.Conn) error {
+	_, err := conn.Write([]byte("ping"))
+	if err != nil {
+		return err
+	}
+	response := make([]byte, 4)
+	_, err = io.ReadFull(conn, response)
+	if err != nil {
+		return err
+	}
+	if string(response) != "ping" {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
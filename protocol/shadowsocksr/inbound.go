@@ -0,0 +1,127 @@
+package shadowsocksr
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/adapter/inbound"
+	"github.com/sagernet/sing-box/common/listener"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing-box/transport/shadowsocksr"
+	"github.com/sagernet/sing/common/auth"
+	E "github.com/sagernet/sing/common/exceptions"
+	N "github.com/sagernet/sing/common/network"
+	"github.com/sagernet/sing/common/ntp"
+)
+
+func RegisterInbound(registry *inbound.Registry) {
+	inbound.Register[option.ShadowsocksRInboundOptions](registry, C.TypeShadowsocksR, NewInbound)
+}
+
+var _ adapter.TCPInjectableInbound = (*Inbound)(nil)
+
+type Inbound struct {
+	inbound.Adapter
+	router   adapter.ConnectionRouterEx
+	logger   log.ContextLogger
+	listener *listener.Listener
+	service  *shadowsocksr.Service[string]
+}
+
+func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLogger, tag string, options option.ShadowsocksRInboundOptions) (adapter.Inbound, error) {
+	inbound := &Inbound{
+		Adapter: inbound.NewAdapter(C.TypeShadowsocksR, tag),
+		router:  router,
+		logger:  logger,
+	}
+	service, err := shadowsocksr.NewService[string](shadowsocksr.ServiceOptions{
+		Method:    options.Method,
+		Password:  options.Password,
+		Obfs:      options.Obfs,
+		ObfsParam: options.ObfsParam,
+		Protocol:  options.Protocol,
+		TimeFunc:  ntp.TimeFuncFromContext(ctx),
+		Handler:   adapter.NewUpstreamContextHandlerEx(inbound.newConnection, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	users, userIDs, passwords, err := parseProtocolParam(options.ProtocolParam)
+	if err != nil {
+		return nil, E.Cause(err, "parse protocol_param")
+	}
+	err = service.UpdateUsers(users, userIDs, passwords)
+	if err != nil {
+		return nil, err
+	}
+	inbound.service = service
+	inbound.listener = listener.New(listener.Options{
+		Context:           ctx,
+		Logger:            logger,
+		Network:           []string{N.NetworkTCP},
+		Listen:            options.ListenOptions,
+		ConnectionHandler: inbound,
+	})
+	return inbound, nil
+}
+
+// parseProtocolParam parses users in the format of ShadowsocksR servers: `[max_clients#]id:password[,id:password...]`.
+func parseProtocolParam(protocolParam string) (users []string, userIDs []uint32, passwords []string, err error) {
+	if index := strings.IndexByte(protocolParam, '#'); index >= 0 {
+		protocolParam = protocolParam[index+1:]
+	}
+	if protocolParam == "" {
+		return
+	}
+	for _, entry := range strings.Split(protocolParam, ",") {
+		userID, password, loaded := strings.Cut(strings.TrimSpace(entry), ":")
+		if !loaded || password == "" {
+			return nil, nil, nil, E.New("invalid user: ", entry)
+		}
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			return nil, nil, nil, E.Cause(err, "invalid user id: ", userID)
+		}
+		users = append(users, userID)
+		userIDs = append(userIDs, uint32(id))
+		passwords = append(passwords, password)
+	}
+	return
+}
+
+func (h *Inbound) Start(stage adapter.StartStage) error {
+	if stage != adapter.StartStateStart {
+		return nil
+	}
+	return h.listener.Start()
+}
+
+func (h *Inbound) Close() error {
+	return h.listener.Close()
+}
+
+func (h *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
+	err := h.service.NewConnection(adapter.WithContext(ctx, &metadata), conn, metadata.Source, onClose)
+	if err != nil {
+		N.CloseOnHandshakeFailure(conn, onClose, err)
+		h.logger.ErrorContext(ctx, E.Cause(err, "process connection from ", metadata.Source))
+	}
+}
+
+func (h *Inbound) newConnection(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
+	metadata.Inbound = h.Tag()
+	metadata.InboundType = h.Type()
+	user, loaded := auth.UserFromContext[string](ctx)
+	if loaded {
+		metadata.User = user
+		h.logger.InfoContext(ctx, "[", user, "] inbound connection to ", metadata.Destination)
+	} else {
+		h.logger.InfoContext(ctx, "inbound connection to ", metadata.Destination)
+	}
+	h.router.RouteConnectionEx(ctx, conn, metadata, onClose)
+}
@@ -0,0 +1,268 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"net"
+	"os"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/adapter/inbound"
+	"github.com/sagernet/sing-box/common/listener"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func RegisterInbound(registry *inbound.Registry) {
+	inbound.Register[option.SSHInboundOptions](registry, C.TypeSSH, NewInbound)
+}
+
+var _ adapter.TCPInjectableInbound = (*Inbound)(nil)
+
+type Inbound struct {
+	inbound.Adapter
+	router   adapter.ConnectionRouterEx
+	logger   log.ContextLogger
+	listener *listener.Listener
+	users    []sshUser
+	config   *ssh.ServerConfig
+}
+
+type sshUser struct {
+	name           string
+	password       string
+	authorizedKeys [][]byte
+}
+
+// directTCPIPRequest is the payload of direct-tcpip channels, see RFC 4254 section 7.2.
+type directTCPIPRequest struct {
+	DestinationAddress string
+	DestinationPort    uint32
+	OriginatorAddress  string
+	OriginatorPort     uint32
+}
+
+func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLogger, tag string, options option.SSHInboundOptions) (adapter.Inbound, error) {
+	inbound := &Inbound{
+		Adapter: inbound.NewAdapter(C.TypeSSH, tag),
+		router:  router,
+		logger:  logger,
+	}
+	for index, user := range options.Users {
+		if user.Name == "" {
+			return nil, E.New("missing name for user[", index, "]")
+		}
+		if user.Password == "" && len(user.AuthorizedKeys) == 0 {
+			return nil, E.New("missing password or authorized_keys for user ", user.Name)
+		}
+		sshUser := sshUser{
+			name:     user.Name,
+			password: user.Password,
+		}
+		for _, authorizedKey := range user.AuthorizedKeys {
+			publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+			if err != nil {
+				return nil, E.Cause(err, "parse authorized key for user ", user.Name)
+			}
+			sshUser.authorizedKeys = append(sshUser.authorizedKeys, publicKey.Marshal())
+		}
+		inbound.users = append(inbound.users, sshUser)
+	}
+	config := &ssh.ServerConfig{
+		ServerVersion: options.ServerVersion,
+	}
+	if config.ServerVersion == "" {
+		config.ServerVersion = randomVersion()
+	}
+	if len(inbound.users) == 0 {
+		config.NoClientAuth = true
+	} else {
+		config.PasswordCallback = inbound.passwordCallback
+		config.PublicKeyCallback = inbound.publicKeyCallback
+	}
+	hostKeys := options.HostKey
+	for _, hostKeyPath := range options.HostKeyPath {
+		content, err := os.ReadFile(os.ExpandEnv(hostKeyPath))
+		if err != nil {
+			return nil, E.Cause(err, "read host key")
+		}
+		hostKeys = append(hostKeys, string(content))
+	}
+	for _, hostKey := range hostKeys {
+		signer, err := ssh.ParsePrivateKey([]byte(hostKey))
+		if err != nil {
+			return nil, E.Cause(err, "parse host key")
+		}
+		config.AddHostKey(signer)
+	}
+	if len(hostKeys) == 0 {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, E.Cause(err, "generate host key")
+		}
+		signer, err := ssh.NewSignerFromKey(privateKey)
+		if err != nil {
+			return nil, E.Cause(err, "generate host key")
+		}
+		config.AddHostKey(signer)
+		logger.Warn("host key not configured, using a temporary one")
+	}
+	inbound.config = config
+	inbound.listener = listener.New(listener.Options{
+		Context:           ctx,
+		Logger:            logger,
+		Network:           []string{N.NetworkTCP},
+		Listen:            options.ListenOptions,
+		ConnectionHandler: inbound,
+	})
+	return inbound, nil
+}
+
+func (h *Inbound) passwordCallback(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	for _, user := range h.users {
+		if user.name == conn.User() && user.password != "" && subtle.ConstantTimeCompare([]byte(user.password), password) == 1 {
+			return userPermissions(user.name), nil
+		}
+	}
+	return nil, E.New("authentication failed for user ", conn.User())
+}
+
+func (h *Inbound) publicKeyCallback(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	publicKey := key.Marshal()
+	for _, user := range h.users {
+		if user.name != conn.User() {
+			continue
+		}
+		for _, authorizedKey := range user.authorizedKeys {
+			if subtle.ConstantTimeCompare(authorizedKey, publicKey) == 1 {
+				return userPermissions(user.name), nil
+			}
+		}
+	}
+	return nil, E.New("public key rejected for user ", conn.User())
+}
+
+func userPermissions(user string) *ssh.Permissions {
+	return &ssh.Permissions{
+		Extensions: map[string]string{
+			"user": user,
+		},
+	}
+}
+
+func (h *Inbound) Start(stage adapter.StartStage) error {
+	if stage != adapter.StartStateStart {
+		return nil
+	}
+	return h.listener.Start()
+}
+
+func (h *Inbound) Close() error {
+	return h.listener.Close()
+}
+
+func (h *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, h.config)
+	if err != nil {
+		N.CloseOnHandshakeFailure(conn, onClose, err)
+		h.logger.ErrorContext(ctx, E.Cause(err, "process connection from ", metadata.Source))
+		return
+	}
+	var user string
+	if serverConn.Permissions != nil {
+		user = serverConn.Permissions.Extensions["user"]
+	}
+	if user != "" {
+		h.logger.InfoContext(ctx, "[", user, "] ssh connection established")
+	} else {
+		h.logger.InfoContext(ctx, "ssh connection established")
+	}
+	// global requests such as tcpip-forward are not supported
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type: "+newChannel.ChannelType())
+			continue
+		}
+		var request directTCPIPRequest
+		err = ssh.Unmarshal(newChannel.ExtraData(), &request)
+		if err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, "invalid direct-tcpip request")
+			continue
+		}
+		destination := M.ParseSocksaddrHostPort(request.DestinationAddress, uint16(request.DestinationPort))
+		if !destination.IsValid() {
+			_ = newChannel.Reject(ssh.ConnectionFailed, "invalid destination")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			h.logger.ErrorContext(ctx, E.Cause(err, "accept direct-tcpip channel"))
+			continue
+		}
+		go ssh.DiscardRequests(channelRequests)
+		go h.newChannel(log.ContextWithNewID(ctx), &channelConn{channel, conn}, metadata, user, destination)
+	}
+	_ = serverConn.Wait()
+	common.Close(serverConn)
+	if onClose != nil {
+		onClose(nil)
+	}
+}
+
+func (h *Inbound) newChannel(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, user string, destination M.Socksaddr) {
+	metadata.Inbound = h.Tag()
+	metadata.InboundType = h.Type()
+	metadata.Destination = destination
+	metadata.User = user
+	if user != "" {
+		h.logger.InfoContext(ctx, "[", user, "] inbound connection to ", metadata.Destination)
+	} else {
+		h.logger.InfoContext(ctx, "inbound connection to ", metadata.Destination)
+	}
+	h.router.RouteConnectionEx(ctx, conn, metadata, nil)
+}
+
+// channelConn wraps a direct-tcpip channel as a net.Conn.
+type channelConn struct {
+	ssh.Channel
+	conn net.Conn
+}
+
+func (c *channelConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *channelConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *channelConn) SetDeadline(t time.Time) error {
+	return os.ErrInvalid
+}
+
+func (c *channelConn) SetReadDeadline(t time.Time) error {
+	return os.ErrInvalid
+}
+
+func (c *channelConn) SetWriteDeadline(t time.Time) error {
+	return os.ErrInvalid
+}
+
+func (c *channelConn) NeedAdditionalReadDeadline() bool {
+	return true
+}
+
+func (c *channelConn) Upstream() any {
+	return c.Channel
+}
@@ -0,0 +1,109 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/json/badoption"
+	N "github.com/sagernet/sing/common/network"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+type testRouter struct {
+	adapter.Router
+	connections chan adapter.InboundContext
+}
+
+func (r *testRouter) RouteConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
+	r.connections <- metadata
+	go func() {
+		_, err := io.Copy(conn, conn)
+		conn.Close()
+		if onClose != nil {
+			onClose(err)
+		}
+	}()
+}
+
+func TestInbound(t *testing.T) {
+	t.Parallel()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	require.NoError(t, err)
+	router := &testRouter{connections: make(chan adapter.InboundContext, 1)}
+	inbound, err := NewInbound(context.Background(), router, log.NewNOPFactory().Logger(), "ssh-in", option.SSHInboundOptions{
+		ListenOptions: option.ListenOptions{
+			Listen: common.Ptr(badoption.Addr(netip.AddrFrom4([4]byte{127, 0, 0, 1}))),
+		},
+		Users: []option.SSHUser{
+			{Name: "alice", Password: "alice password"},
+			{Name: "bob", AuthorizedKeys: []string{string(ssh.MarshalAuthorizedKey(sshPublicKey))}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, inbound.Start(adapter.StartStateStart))
+	defer inbound.Close()
+	serverAddress := inbound.(*Inbound).listener.TCPListener().Addr().String()
+
+	dial := func(user string, auth ssh.AuthMethod) (*ssh.Client, error) {
+		client, err := ssh.Dial("tcp", serverAddress, &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{auth},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Timeout:         5 * time.Second,
+		})
+		if err != nil {
+			return nil, err
+		}
+		t.Cleanup(func() {
+			client.Close()
+		})
+		return client, nil
+	}
+	requireForward := func(client *ssh.Client, user string) {
+		conn, err := client.Dial("tcp", "example.com:80")
+		require.NoError(t, err)
+		defer conn.Close()
+		select {
+		case metadata := <-router.connections:
+			require.Equal(t, "ssh-in", metadata.Inbound)
+			require.Equal(t, "example.com:80", metadata.Destination.String())
+			require.Equal(t, user, metadata.User)
+		case <-time.After(5 * time.Second):
+			t.Fatal("connection not routed")
+		}
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		response := make([]byte, 4)
+		_, err = io.ReadFull(conn, response)
+		require.NoError(t, err)
+		require.Equal(t, "ping", string(response))
+	}
+
+	client, err := dial("alice", ssh.Password("alice password"))
+	require.NoError(t, err)
+	requireForward(client, "alice")
+	client, err = dial("bob", ssh.PublicKeys(signer))
+	require.NoError(t, err)
+	requireForward(client, "bob")
+
+	_, err = dial("alice", ssh.Password("wrong password"))
+	require.Error(t, err)
+	_, err = dial("bob", ssh.Password("alice password"))
+	require.Error(t, err)
+}
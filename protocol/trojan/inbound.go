@@ -7,6 +7,7 @@ import (
 
 	"github.com/sagernet/sing-box/adapter"
 	"github.com/sagernet/sing-box/adapter/inbound"
+	"github.com/sagernet/sing-box/common/inbounduser"
 	"github.com/sagernet/sing-box/common/listener"
 	"github.com/sagernet/sing-box/common/mux"
 	"github.com/sagernet/sing-box/common/tls"
@@ -27,7 +28,10 @@ func RegisterInbound(registry *inbound.Registry) {
 	inbound.Register[option.TrojanInboundOptions](registry, C.TypeTrojan, NewInbound)
 }
 
-var _ adapter.TCPInjectableInbound = (*Inbound)(nil)
+var (
+	_ adapter.TCPInjectableInbound = (*Inbound)(nil)
+	_ adapter.ManagedUserInbound   = (*Inbound)(nil)
+)
 
 type Inbound struct {
 	inbound.Adapter
@@ -35,7 +39,7 @@ type Inbound struct {
 	logger                   log.ContextLogger
 	listener                 *listener.Listener
 	service                  *trojan.Service[int]
-	users                    []option.TrojanUser
+	users                    *inbounduser.Users[option.TrojanUser]
 	tlsConfig                tls.ServerConfig
 	fallbackAddr             M.Socksaddr
 	fallbackAddrTLSNextProto map[string]M.Socksaddr
@@ -47,7 +51,6 @@ func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLo
 		Adapter: inbound.NewAdapter(C.TypeTrojan, tag),
 		router:  router,
 		logger:  logger,
-		users:   options.Users,
 	}
 	if options.TLS != nil {
 		tlsConfig, err := tls.NewServer(ctx, logger, common.PtrValueOrDefault(options.TLS))
@@ -81,11 +84,14 @@ func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLo
 		fallbackHandler = adapter.NewUpstreamContextHandlerEx(inbound.fallbackConnection, nil)
 	}
 	service := trojan.NewService[int](adapter.NewUpstreamContextHandlerEx(inbound.newConnection, inbound.newPacketConnection), fallbackHandler, logger)
-	err := service.UpdateUsers(common.MapIndexed(options.Users, func(index int, it option.TrojanUser) int {
-		return index
-	}), common.Map(options.Users, func(it option.TrojanUser) string {
-		return it.Password
-	}))
+	var err error
+	inbound.users, err = inbounduser.New(options.Users, func(it option.TrojanUser) string {
+		return it.Name
+	}, func(userIDs []int, users []option.TrojanUser) error {
+		return service.UpdateUsers(userIDs, common.Map(users, func(it option.TrojanUser) string {
+			return it.Password
+		}))
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -158,6 +164,42 @@ func (h *Inbound) Close() error {
 	)
 }
 
+func (h *Inbound) AddUser(user adapter.InboundUser) error {
+	if user.Password == "" {
+		return E.New("missing password")
+	}
+	return h.users.Add(option.TrojanUser{
+		Name:                    user.Name,
+		Password:                user.Password,
+		InboundUserLimitOptions: inbounduser.LimitOptions(user),
+	})
+}
+
+func (h *Inbound) RemoveUser(name string) error {
+	return h.users.Remove(name)
+}
+
+func (h *Inbound) User(name string) (adapter.InboundUser, bool) {
+	user, loaded := h.users.Get(name)
+	if !loaded {
+		return adapter.InboundUser{}, false
+	}
+	return newInboundUser(user), true
+}
+
+func (h *Inbound) ListUsers() []adapter.InboundUser {
+	return common.Map(h.users.List(), newInboundUser)
+}
+
+func newInboundUser(it option.TrojanUser) adapter.InboundUser {
+	user := adapter.InboundUser{
+		Name:     it.Name,
+		Password: it.Password,
+	}
+	inbounduser.SetLimitOptions(&user, it.InboundUserLimitOptions)
+	return user
+}
+
 func (h *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
 	if h.tlsConfig != nil && h.transport == nil {
 		tlsConn, err := tls.ServerHandshake(ctx, conn, h.tlsConfig)
@@ -183,7 +225,12 @@ func (h *Inbound) newConnection(ctx context.Context, conn net.Conn, metadata ada
 		N.CloseOnHandshakeFailure(conn, onClose, os.ErrInvalid)
 		return
 	}
-	user := h.users[userIndex].Name
+	trojanUser, loaded := h.users.User(userIndex)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userIndex, " removed"))
+		return
+	}
+	user := trojanUser.Name
 	if user == "" {
 		user = F.ToString(userIndex)
 	} else {
@@ -201,7 +248,12 @@ func (h *Inbound) newPacketConnection(ctx context.Context, conn N.PacketConn, me
 		N.CloseOnHandshakeFailure(conn, onClose, os.ErrInvalid)
 		return
 	}
-	user := h.users[userIndex].Name
+	trojanUser, loaded := h.users.User(userIndex)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userIndex, " removed"))
+		return
+	}
+	user := trojanUser.Name
 	if user == "" {
 		user = F.ToString(userIndex)
 	} else {
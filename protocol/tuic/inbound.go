@@ -7,6 +7,7 @@ import (
 
 	"github.com/sagernet/sing-box/adapter"
 	"github.com/sagernet/sing-box/adapter/inbound"
+	"github.com/sagernet/sing-box/common/inbounduser"
 	"github.com/sagernet/sing-box/common/listener"
 	"github.com/sagernet/sing-box/common/tls"
 	"github.com/sagernet/sing-box/common/uot"
@@ -27,14 +28,16 @@ func RegisterInbound(registry *inbound.Registry) {
 	inbound.Register[option.TUICInboundOptions](registry, C.TypeTUIC, NewInbound)
 }
 
+var _ adapter.ManagedUserInbound = (*Inbound)(nil)
+
 type Inbound struct {
 	inbound.Adapter
-	router       adapter.ConnectionRouterEx
-	logger       log.ContextLogger
-	listener     *listener.Listener
-	tlsConfig    tls.ServerConfig
-	server       *tuic.Service[int]
-	userNameList []string
+	router    adapter.ConnectionRouterEx
+	logger    log.ContextLogger
+	listener  *listener.Listener
+	tlsConfig tls.ServerConfig
+	server    *tuic.Service[int]
+	users     *inbounduser.Users[option.TUICUser]
 }
 
 func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLogger, tag string, options option.TUICInboundOptions) (adapter.Inbound, error) {
@@ -77,29 +80,76 @@ func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLo
 	if err != nil {
 		return nil, err
 	}
-	var userList []int
-	var userNameList []string
-	var userUUIDList [][16]byte
-	var userPasswordList []string
 	for index, user := range options.Users {
 		if user.UUID == "" {
 			return nil, E.New("missing uuid for user ", index)
 		}
-		userUUID, err := uuid.FromString(user.UUID)
+		_, err = uuid.FromString(user.UUID)
 		if err != nil {
 			return nil, E.Cause(err, "invalid uuid for user ", index)
 		}
-		userList = append(userList, index)
-		userNameList = append(userNameList, user.Name)
-		userUUIDList = append(userUUIDList, userUUID)
-		userPasswordList = append(userPasswordList, user.Password)
 	}
-	service.UpdateUsers(userList, userUUIDList, userPasswordList)
+	inbound.users, err = inbounduser.New(options.Users, func(it option.TUICUser) string {
+		return it.Name
+	}, func(userIDs []int, users []option.TUICUser) error {
+		// UpdateUsers replaces the user maps of the running service,
+		// so established QUIC connections are kept.
+		service.UpdateUsers(userIDs, common.Map(users, func(it option.TUICUser) [16]byte {
+			return uuid.FromStringOrNil(it.UUID)
+		}), common.Map(users, func(it option.TUICUser) string {
+			return it.Password
+		}))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	inbound.server = service
-	inbound.userNameList = userNameList
 	return inbound, nil
 }
 
+func (h *Inbound) AddUser(user adapter.InboundUser) error {
+	if user.UUID == "" {
+		return E.New("missing uuid")
+	}
+	_, err := uuid.FromString(user.UUID)
+	if err != nil {
+		return E.Cause(err, "invalid uuid")
+	}
+	return h.users.Add(option.TUICUser{
+		Name:                    user.Name,
+		UUID:                    user.UUID,
+		Password:                user.Password,
+		InboundUserLimitOptions: inbounduser.LimitOptions(user),
+	})
+}
+
+func (h *Inbound) RemoveUser(name string) error {
+	return h.users.Remove(name)
+}
+
+func (h *Inbound) User(name string) (adapter.InboundUser, bool) {
+	user, loaded := h.users.Get(name)
+	if !loaded {
+		return adapter.InboundUser{}, false
+	}
+	return newInboundUser(user), true
+}
+
+func (h *Inbound) ListUsers() []adapter.InboundUser {
+	return common.Map(h.users.List(), newInboundUser)
+}
+
+func newInboundUser(it option.TUICUser) adapter.InboundUser {
+	user := adapter.InboundUser{
+		Name:     it.Name,
+		UUID:     it.UUID,
+		Password: it.Password,
+	}
+	inbounduser.SetLimitOptions(&user, it.InboundUserLimitOptions)
+	return user
+}
+
 func (h *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, source M.Socksaddr, destination M.Socksaddr, onClose N.CloseHandlerFunc) {
 	ctx = log.ContextWithNewID(ctx)
 	var metadata adapter.InboundContext
@@ -114,7 +164,12 @@ func (h *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, source M.S
 	metadata.Destination = destination
 	h.logger.InfoContext(ctx, "inbound connection from ", metadata.Source)
 	userID, _ := auth.UserFromContext[int](ctx)
-	if userName := h.userNameList[userID]; userName != "" {
+	user, loaded := h.users.User(userID)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userID, " removed"))
+		return
+	}
+	if userName := user.Name; userName != "" {
 		metadata.User = userName
 		h.logger.InfoContext(ctx, "[", userName, "] inbound connection to ", metadata.Destination)
 	} else {
@@ -137,7 +192,12 @@ func (h *Inbound) NewPacketConnectionEx(ctx context.Context, conn N.PacketConn,
 	metadata.Destination = destination
 	h.logger.InfoContext(ctx, "inbound packet connection from ", metadata.Source)
 	userID, _ := auth.UserFromContext[int](ctx)
-	if userName := h.userNameList[userID]; userName != "" {
+	user, loaded := h.users.User(userID)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userID, " removed"))
+		return
+	}
+	if userName := user.Name; userName != "" {
 		metadata.User = userName
 		h.logger.InfoContext(ctx, "[", userName, "] inbound packet connection to ", metadata.Destination)
 	} else {
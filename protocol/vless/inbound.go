@@ -7,6 +7,7 @@ import (
 
 	"github.com/sagernet/sing-box/adapter"
 	"github.com/sagernet/sing-box/adapter/inbound"
+	"github.com/sagernet/sing-box/common/inbounduser"
 	"github.com/sagernet/sing-box/common/listener"
 	"github.com/sagernet/sing-box/common/mux"
 	"github.com/sagernet/sing-box/common/tls"
@@ -31,7 +32,10 @@ func RegisterInbound(registry *inbound.Registry) {
 	inbound.Register[option.VLESSInboundOptions](registry, C.TypeVLESS, NewInbound)
 }
 
-var _ adapter.TCPInjectableInbound = (*Inbound)(nil)
+var (
+	_ adapter.TCPInjectableInbound = (*Inbound)(nil)
+	_ adapter.ManagedUserInbound   = (*Inbound)(nil)
+)
 
 type Inbound struct {
 	inbound.Adapter
@@ -39,7 +43,7 @@ type Inbound struct {
 	router    adapter.ConnectionRouterEx
 	logger    logger.ContextLogger
 	listener  *listener.Listener
-	users     []option.VLESSUser
+	users     *inbounduser.Users[option.VLESSUser]
 	service   *vless.Service[int]
 	tlsConfig tls.ServerConfig
 	transport adapter.V2RayServerTransport
@@ -51,7 +55,6 @@ func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLo
 		ctx:     ctx,
 		router:  uot.NewRouter(router, logger),
 		logger:  logger,
-		users:   options.Users,
 	}
 	var err error
 	inbound.router, err = mux.NewRouterWithOptions(inbound.router, logger, common.PtrValueOrDefault(options.Multiplex))
@@ -59,13 +62,21 @@ func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLo
 		return nil, err
 	}
 	service := vless.NewService[int](logger, adapter.NewUpstreamContextHandlerEx(inbound.newConnectionEx, inbound.newPacketConnectionEx))
-	service.UpdateUsers(common.MapIndexed(inbound.users, func(index int, _ option.VLESSUser) int {
-		return index
-	}), common.Map(inbound.users, func(it option.VLESSUser) string {
-		return it.UUID
-	}), common.Map(inbound.users, func(it option.VLESSUser) string {
-		return it.Flow
-	}))
+	inbound.users, err = inbounduser.New(options.Users, func(it option.VLESSUser) string {
+		return it.Name
+	}, func(userIDs []int, users []option.VLESSUser) error {
+		// UpdateUsers replaces the user maps of the service, so connections being authenticated
+		// see either the previous or the updated users.
+		service.UpdateUsers(userIDs, common.Map(users, func(it option.VLESSUser) string {
+			return it.UUID
+		}), common.Map(users, func(it option.VLESSUser) string {
+			return it.Flow
+		}))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	inbound.service = service
 	if options.TLS != nil {
 		inbound.tlsConfig, err = tls.NewServer(ctx, logger, common.PtrValueOrDefault(options.TLS))
@@ -138,6 +149,44 @@ func (h *Inbound) Close() error {
 	)
 }
 
+func (h *Inbound) AddUser(user adapter.InboundUser) error {
+	if user.UUID == "" {
+		return E.New("missing uuid")
+	}
+	return h.users.Add(option.VLESSUser{
+		Name:                    user.Name,
+		UUID:                    user.UUID,
+		Flow:                    user.Flow,
+		InboundUserLimitOptions: inbounduser.LimitOptions(user),
+	})
+}
+
+func (h *Inbound) RemoveUser(name string) error {
+	return h.users.Remove(name)
+}
+
+func (h *Inbound) User(name string) (adapter.InboundUser, bool) {
+	user, loaded := h.users.Get(name)
+	if !loaded {
+		return adapter.InboundUser{}, false
+	}
+	return newInboundUser(user), true
+}
+
+func (h *Inbound) ListUsers() []adapter.InboundUser {
+	return common.Map(h.users.List(), newInboundUser)
+}
+
+func newInboundUser(it option.VLESSUser) adapter.InboundUser {
+	user := adapter.InboundUser{
+		Name: it.Name,
+		UUID: it.UUID,
+		Flow: it.Flow,
+	}
+	inbounduser.SetLimitOptions(&user, it.InboundUserLimitOptions)
+	return user
+}
+
 func (h *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
 	if h.tlsConfig != nil && h.transport == nil {
 		tlsConn, err := tls.ServerHandshake(ctx, conn, h.tlsConfig)
@@ -163,7 +212,12 @@ func (h *Inbound) newConnectionEx(ctx context.Context, conn net.Conn, metadata a
 		N.CloseOnHandshakeFailure(conn, onClose, os.ErrInvalid)
 		return
 	}
-	user := h.users[userIndex].Name
+	vlessUser, loaded := h.users.User(userIndex)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userIndex, " removed"))
+		return
+	}
+	user := vlessUser.Name
 	if user == "" {
 		user = F.ToString(userIndex)
 	} else {
@@ -181,7 +235,12 @@ func (h *Inbound) newPacketConnectionEx(ctx context.Context, conn N.PacketConn,
 		N.CloseOnHandshakeFailure(conn, onClose, os.ErrInvalid)
 		return
 	}
-	user := h.users[userIndex].Name
+	vlessUser, loaded := h.users.User(userIndex)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userIndex, " removed"))
+		return
+	}
+	user := vlessUser.Name
 	if user == "" {
 		user = F.ToString(userIndex)
 	} else {
@@ -4,9 +4,11 @@ import (
 	"context"
 	"net"
 	"os"
+	"sync"
 
 	"github.com/sagernet/sing-box/adapter"
 	"github.com/sagernet/sing-box/adapter/inbound"
+	"github.com/sagernet/sing-box/common/inbounduser"
 	"github.com/sagernet/sing-box/common/listener"
 	"github.com/sagernet/sing-box/common/mux"
 	"github.com/sagernet/sing-box/common/tls"
@@ -31,16 +33,25 @@ func RegisterInbound(registry *inbound.Registry) {
 	inbound.Register[option.VMessInboundOptions](registry, C.TypeVMess, NewInbound)
 }
 
-var _ adapter.TCPInjectableInbound = (*Inbound)(nil)
+var (
+	_ adapter.TCPInjectableInbound = (*Inbound)(nil)
+	_ adapter.ManagedUserInbound   = (*Inbound)(nil)
+)
 
 type Inbound struct {
 	inbound.Adapter
-	ctx       context.Context
-	router    adapter.ConnectionRouterEx
-	logger    logger.ContextLogger
-	listener  *listener.Listener
-	service   *vmess.Service[int]
-	users     []option.VMessUser
+	ctx      context.Context
+	router   adapter.ConnectionRouterEx
+	logger   logger.ContextLogger
+	listener *listener.Listener
+	service  *vmess.Service[int]
+	users    *inbounduser.Users[option.VMessUser]
+
+	access        sync.Mutex
+	started       bool
+	legacyUsers   bool
+	legacyStarted bool
+
 	tlsConfig tls.ServerConfig
 	transport adapter.V2RayServerTransport
 }
@@ -51,7 +62,6 @@ func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLo
 		ctx:     ctx,
 		router:  uot.NewRouter(router, logger),
 		logger:  logger,
-		users:   options.Users,
 	}
 	var err error
 	inbound.router, err = mux.NewRouterWithOptions(inbound.router, logger, common.PtrValueOrDefault(options.Multiplex))
@@ -67,13 +77,25 @@ func NewInbound(ctx context.Context, router adapter.Router, logger log.ContextLo
 	}
 	service := vmess.NewService[int](adapter.NewUpstreamContextHandlerEx(inbound.newConnectionEx, inbound.newPacketConnectionEx), serviceOptions...)
 	inbound.service = service
-	err = service.UpdateUsers(common.MapIndexed(options.Users, func(index int, it option.VMessUser) int {
-		return index
-	}), common.Map(options.Users, func(it option.VMessUser) string {
-		return it.UUID
-	}), common.Map(options.Users, func(it option.VMessUser) int {
-		return it.AlterId
-	}))
+	inbound.users, err = inbounduser.New(options.Users, func(it option.VMessUser) string {
+		return it.Name
+	}, func(userIDs []int, users []option.VMessUser) error {
+		// UpdateUsers replaces the user maps of the running service, so its replay filter is kept.
+		err := service.UpdateUsers(userIDs, common.Map(users, func(it option.VMessUser) string {
+			return it.UUID
+		}), common.Map(users, func(it option.VMessUser) int {
+			return it.AlterId
+		}))
+		if err != nil {
+			return err
+		}
+		inbound.access.Lock()
+		defer inbound.access.Unlock()
+		inbound.legacyUsers = common.Any(users, func(it option.VMessUser) bool {
+			return it.AlterId > 0
+		})
+		return inbound.startService()
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +125,10 @@ func (h *Inbound) Start(stage adapter.StartStage) error {
 	if stage != adapter.StartStateStart {
 		return nil
 	}
-	err := h.service.Start()
+	h.access.Lock()
+	h.started = true
+	err := h.startService()
+	h.access.Unlock()
 	if err != nil {
 		return err
 	}
@@ -143,6 +168,20 @@ func (h *Inbound) Start(stage adapter.StartStage) error {
 	return nil
 }
 
+// startService starts the service once the inbound is started and users with alter IDs exist,
+// as the service only refreshes their keys if it is started with such users.
+func (h *Inbound) startService() error {
+	if !h.started || !h.legacyUsers || h.legacyStarted {
+		return nil
+	}
+	err := h.service.Start()
+	if err != nil {
+		return err
+	}
+	h.legacyStarted = true
+	return nil
+}
+
 func (h *Inbound) Close() error {
 	return common.Close(
 		h.service,
@@ -152,6 +191,44 @@ func (h *Inbound) Close() error {
 	)
 }
 
+func (h *Inbound) AddUser(user adapter.InboundUser) error {
+	if user.UUID == "" {
+		return E.New("missing uuid")
+	}
+	return h.users.Add(option.VMessUser{
+		Name:                    user.Name,
+		UUID:                    user.UUID,
+		AlterId:                 user.AlterID,
+		InboundUserLimitOptions: inbounduser.LimitOptions(user),
+	})
+}
+
+func (h *Inbound) RemoveUser(name string) error {
+	return h.users.Remove(name)
+}
+
+func (h *Inbound) User(name string) (adapter.InboundUser, bool) {
+	user, loaded := h.users.Get(name)
+	if !loaded {
+		return adapter.InboundUser{}, false
+	}
+	return newInboundUser(user), true
+}
+
+func (h *Inbound) ListUsers() []adapter.InboundUser {
+	return common.Map(h.users.List(), newInboundUser)
+}
+
+func newInboundUser(it option.VMessUser) adapter.InboundUser {
+	user := adapter.InboundUser{
+		Name:    it.Name,
+		UUID:    it.UUID,
+		AlterID: it.AlterId,
+	}
+	inbounduser.SetLimitOptions(&user, it.InboundUserLimitOptions)
+	return user
+}
+
 func (h *Inbound) NewConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
 	if h.tlsConfig != nil && h.transport == nil {
 		tlsConn, err := tls.ServerHandshake(ctx, conn, h.tlsConfig)
@@ -177,7 +254,12 @@ func (h *Inbound) newConnectionEx(ctx context.Context, conn net.Conn, metadata a
 		N.CloseOnHandshakeFailure(conn, onClose, os.ErrInvalid)
 		return
 	}
-	user := h.users[userIndex].Name
+	vmessUser, loaded := h.users.User(userIndex)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userIndex, " removed"))
+		return
+	}
+	user := vmessUser.Name
 	if user == "" {
 		user = F.ToString(userIndex)
 	} else {
@@ -195,7 +277,12 @@ func (h *Inbound) newPacketConnectionEx(ctx context.Context, conn N.PacketConn,
 		N.CloseOnHandshakeFailure(conn, onClose, os.ErrInvalid)
 		return
 	}
-	user := h.users[userIndex].Name
+	vmessUser, loaded := h.users.User(userIndex)
+	if !loaded {
+		N.CloseOnHandshakeFailure(conn, onClose, E.New("user ", userIndex, " removed"))
+		return
+	}
+	user := vmessUser.Name
 	if user == "" {
 		user = F.ToString(userIndex)
 	} else {
@@ -0,0 +1,103 @@
+package vmess
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing-vmess"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type testRouter struct {
+	adapter.Router
+	users chan string
+}
+
+func (r *testRouter) RouteConnectionEx(ctx context.Context, conn net.Conn, metadata adapter.InboundContext, onClose N.CloseHandlerFunc) {
+	r.users <- metadata.User
+	go func() {
+		_, err := io.Copy(conn, conn)
+		conn.Close()
+		onClose(err)
+	}()
+}
+
+func TestInboundUpdateUsers(t *testing.T) {
+	t.Parallel()
+	router := &testRouter{users: make(chan string, 1)}
+	aliceUUID := uuid.Must(uuid.NewV4()).String()
+	bobUUID := uuid.Must(uuid.NewV4()).String()
+	inbound, err := NewInbound(context.Background(), router, log.NewNOPFactory().Logger(), "vmess-in", option.VMessInboundOptions{
+		Users: []option.VMessUser{{Name: "alice", UUID: aliceUUID}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, inbound.Start(adapter.StartStateStart))
+	defer inbound.Close()
+	managedInbound := inbound.(adapter.ManagedUserInbound)
+
+	dial := func(userUUID string, alterID int) (net.Conn, error) {
+		client, err := vmess.NewClient(userUUID, "auto", alterID)
+		require.NoError(t, err)
+		serverConn, clientConn := net.Pipe()
+		t.Cleanup(func() {
+			clientConn.Close()
+		})
+		go inbound.(adapter.TCPInjectableInbound).NewConnectionEx(context.Background(), serverConn, adapter.InboundContext{}, func(error) {})
+		clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+		conn, err := client.DialConn(clientConn, M.ParseSocksaddr("example.com:80"))
+		if err != nil {
+			return nil, err
+		}
+		return conn, echo(conn)
+	}
+	requireUser := func(user string) {
+		select {
+		case routedUser := <-router.users:
+			require.Equal(t, user, routedUser)
+		case <-time.After(5 * time.Second):
+			t.Fatal("connection not routed")
+		}
+	}
+
+	aliceConn, err := dial(aliceUUID, 0)
+	require.NoError(t, err)
+	requireUser("alice")
+	// bob uses the legacy protocol, whose keys are refreshed by the started service
+	require.NoError(t, managedInbound.AddUser(adapter.InboundUser{Name: "bob", UUID: bobUUID, AlterID: 1}))
+	require.True(t, inbound.(*Inbound).legacyStarted)
+	require.NoError(t, echo(aliceConn))
+	_, err = dial(bobUUID, 1)
+	require.NoError(t, err)
+	requireUser("bob")
+
+	require.NoError(t, managedInbound.RemoveUser("bob"))
+	_, err = dial(bobUUID, 1)
+	require.Error(t, err)
+	require.NoError(t, echo(aliceConn))
+}
+
+func echo(conn net.Conn) error {
+	_, err := conn.Write([]byte("ping"))
+	if err != nil {
+		return err
+	}
+	response := make([]byte, 4)
+	_, err = io.ReadFull(conn, response)
+	if err != nil {
+		return err
+	}
+	if string(response) != "ping" {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
@@ -21,23 +21,30 @@ import (
 	M "github.com/sagernet/sing/common/metadata"
 	N "github.com/sagernet/sing/common/network"
 	"github.com/sagernet/sing/common/x/list"
+	"github.com/sagernet/sing/service"
 )
 
 var _ adapter.ConnectionManager = (*ConnectionManager)(nil)
 
 type ConnectionManager struct {
+	ctx         context.Context
 	logger      logger.ContextLogger
+	tracer      adapter.Tracer
 	access      sync.Mutex
 	connections list.List[io.Closer]
 }
 
-func NewConnectionManager(logger logger.ContextLogger) *ConnectionManager {
+func NewConnectionManager(ctx context.Context, logger logger.ContextLogger) *ConnectionManager {
 	return &ConnectionManager{
+		ctx:    ctx,
 		logger: logger,
 	}
 }
 
 func (m *ConnectionManager) Start(stage adapter.StartStage) error {
+	if stage == adapter.StartStateStart {
+		m.tracer = service.FromContext[adapter.Tracer](m.ctx)
+	}
 	return nil
 }
 
@@ -57,11 +64,13 @@ func (m *ConnectionManager) NewConnection(ctx context.Context, this N.Dialer, co
 		remoteConn net.Conn
 		err        error
 	)
+	dialStartedAt := time.Now()
 	if len(metadata.DestinationAddresses) > 0 || metadata.Destination.IsIP() {
 		remoteConn, err = dialer.DialSerialNetwork(ctx, this, N.NetworkTCP, metadata.Destination, metadata.DestinationAddresses, metadata.NetworkStrategy, metadata.NetworkType, metadata.FallbackNetworkType, metadata.FallbackDelay)
 	} else {
 		remoteConn, err = this.DialContext(ctx, N.NetworkTCP, metadata.Destination)
 	}
+	m.traceDial(ctx, this, metadata, dialStartedAt, err)
 	if err != nil {
 		err = E.Cause(err, "open outbound connection")
 		N.CloseOnHandshakeFailure(conn, onClose, err)
@@ -112,6 +121,7 @@ func (m *ConnectionManager) NewPacketConnection(ctx context.Context, this N.Dial
 		destinationAddress netip.Addr
 		err                error
 	)
+	dialStartedAt := time.Now()
 	if metadata.UDPConnect {
 		parallelDialer, isParallelDialer := this.(dialer.ParallelInterfaceDialer)
 		if len(metadata.DestinationAddresses) > 0 {
@@ -129,6 +139,7 @@ func (m *ConnectionManager) NewPacketConnection(ctx context.Context, this N.Dial
 		} else {
 			remoteConn, err = this.DialContext(ctx, N.NetworkUDP, metadata.Destination)
 		}
+		m.traceDial(ctx, this, metadata, dialStartedAt, err)
 		if err != nil {
 			N.CloseOnHandshakeFailure(conn, onClose, err)
 			m.logger.ErrorContext(ctx, "open outbound packet connection: ", err)
@@ -145,6 +156,7 @@ func (m *ConnectionManager) NewPacketConnection(ctx context.Context, this N.Dial
 		} else {
 			remotePacketConn, err = this.ListenPacket(ctx, metadata.Destination)
 		}
+		m.traceDial(ctx, this, metadata, dialStartedAt, err)
 		if err != nil {
 			N.CloseOnHandshakeFailure(conn, onClose, err)
 			m.logger.ErrorContext(ctx, "listen outbound packet connection: ", err)
@@ -211,6 +223,17 @@ func (m *ConnectionManager) NewPacketConnection(ctx context.Context, this N.Dial
 	go m.packetConnectionCopy(ctx, destination, conn, true, &done, onClose)
 }
 
+func (m *ConnectionManager) traceDial(ctx context.Context, this N.Dialer, metadata adapter.InboundContext, startedAt time.Time, err error) {
+	if m.tracer == nil || !m.tracer.TracingEnabled() {
+		return
+	}
+	var outbound string
+	if outboundAdapter, isOutbound := this.(adapter.Outbound); isOutbound {
+		outbound = outboundAdapter.Tag()
+	}
+	m.tracer.TraceDial(ctx, metadata, outbound, time.Since(startedAt), err)
+}
+
 func (m *ConnectionManager) connectionCopy(ctx context.Context, source io.Reader, destination io.Writer, direction bool, done *atomic.Bool, onClose N.CloseHandlerFunc) {
 	originSource := source
 	originDestination := destination
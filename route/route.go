@@ -139,8 +139,17 @@ func (r *Router) routeConnection(ctx context.Context, conn net.Conn, metadata ad
 	for _, buffer := range buffers {
 		conn = bufio.NewCachedConn(conn, buffer)
 	}
-	if r.tracker != nil {
-		conn = r.tracker.RoutedConnection(ctx, conn, metadata, selectedRule, selectedOutbound)
+	if r.userLimiter != nil {
+		limitedConn, err := r.userLimiter.LimitConnection(ctx, conn, metadata)
+		if err != nil {
+			r.logger.InfoContext(ctx, "reject connection from user ", metadata.User, ": ", err)
+			N.CloseOnHandshakeFailure(conn, onClose, err)
+			return nil
+		}
+		conn = limitedConn
+	}
+	for _, tracker := range r.trackers {
+		conn = tracker.RoutedConnection(ctx, conn, metadata, selectedRule, selectedOutbound)
 	}
 	if outboundHandler, isHandler := selectedOutbound.(adapter.ConnectionHandlerEx); isHandler {
 		outboundHandler.NewConnectionEx(ctx, conn, metadata, onClose)
@@ -257,8 +266,17 @@ func (r *Router) routePacketConnection(ctx context.Context, conn N.PacketConn, m
 		conn = bufio.NewCachedPacketConn(conn, buffer.Buffer, buffer.Destination)
 		N.PutPacketBuffer(buffer)
 	}
-	if r.tracker != nil {
-		conn = r.tracker.RoutedPacketConnection(ctx, conn, metadata, selectedRule, selectedOutbound)
+	if r.userLimiter != nil {
+		limitedConn, err := r.userLimiter.LimitPacketConnection(ctx, conn, metadata)
+		if err != nil {
+			r.logger.InfoContext(ctx, "reject packet connection from user ", metadata.User, ": ", err)
+			N.CloseOnHandshakeFailure(conn, onClose, err)
+			return nil
+		}
+		conn = limitedConn
+	}
+	for _, tracker := range r.trackers {
+		conn = tracker.RoutedPacketConnection(ctx, conn, metadata, selectedRule, selectedOutbound)
 	}
 	if metadata.FakeIP {
 		conn = bufio.NewNATPacketConn(bufio.NewNetPacketConn(conn), metadata.OriginDestination, metadata.Destination)
@@ -293,6 +311,12 @@ func (r *Router) matchRule(
 	selectedRule adapter.Rule, selectedRuleIndex int,
 	buffers []*buf.Buffer, packetBuffers []*N.PacketBuffer, fatalErr error,
 ) {
+	if !preMatch && r.tracer != nil && r.tracer.TracingEnabled() {
+		startedAt := time.Now()
+		defer func() {
+			r.traceRuleMatch(ctx, *metadata, selectedRule, time.Since(startedAt), fatalErr)
+		}()
+	}
 	if r.processSearcher != nil && metadata.ProcessInfo == nil {
 		var originDestination netip.AddrPort
 		if metadata.OriginDestination.IsValid() {
@@ -342,8 +366,11 @@ func (r *Router) matchRule(
 	} else if metadata.Domain == "" {
 		domain, loaded := r.dns.LookupReverseMapping(metadata.Destination.Addr)
 		if loaded {
-			metadata.Domain = domain
-			r.logger.DebugContext(ctx, "found reserve mapped domain: ", metadata.Domain)
+			metadata.ReverseMappingDomain = domain
+			if r.reverseMapping {
+				metadata.Domain = domain
+			}
+			r.logger.DebugContext(ctx, "found reverse mapped domain: ", domain)
 		}
 	}
 	if metadata.Destination.IsIPv4() {
@@ -385,28 +412,46 @@ func (r *Router) matchRule(
 
 match:
 	for currentRuleIndex, currentRule := range r.rules {
-		metadata.ResetRuleCache()
-		if !currentRule.Match(metadata) {
+		matched, viaReverseMapping := r.matchWithReverseMapping(currentRule, metadata)
+		if !matched {
 			continue
 		}
+		var matchSuffix string
+		if viaReverseMapping {
+			matchSuffix = " via reverse mapping"
+		}
 		if !preMatch {
 			ruleDescription := currentRule.String()
 			if ruleDescription != "" {
-				r.logger.DebugContext(ctx, "match[", currentRuleIndex, "] ", currentRule, " => ", currentRule.Action())
+				r.logger.DebugContext(ctx, "match[", currentRuleIndex, "] ", currentRule, " => ", currentRule.Action(), matchSuffix)
 			} else {
-				r.logger.DebugContext(ctx, "match[", currentRuleIndex, "] => ", currentRule.Action())
+				r.logger.DebugContext(ctx, "match[", currentRuleIndex, "] => ", currentRule.Action(), matchSuffix)
 			}
 		} else {
 			switch currentRule.Action().Type() {
 			case C.RuleActionTypeReject:
 				ruleDescription := currentRule.String()
 				if ruleDescription != "" {
-					r.logger.DebugContext(ctx, "pre-match[", currentRuleIndex, "] ", currentRule, " => ", currentRule.Action())
+					r.logger.DebugContext(ctx, "pre-match[", currentRuleIndex, "] ", currentRule, " => ", currentRule.Action(), matchSuffix)
 				} else {
-					r.logger.DebugContext(ctx, "pre-match[", currentRuleIndex, "] => ", currentRule.Action())
+					r.logger.DebugContext(ctx, "pre-match[", currentRuleIndex, "] => ", currentRule.Action(), matchSuffix)
 				}
 			}
 		}
+		if scriptAction, isScript := currentRule.Action().(*rule.RuleActionScript); isScript {
+			scriptResult, err := scriptAction.Evaluate(ctx, metadata)
+			if err != nil {
+				r.logger.ErrorContext(ctx, E.Cause(err, "evaluate script[", currentRuleIndex, "]"))
+				continue
+			}
+			if scriptResult == nil {
+				continue
+			}
+			if !preMatch {
+				r.logger.DebugContext(ctx, "script[", currentRuleIndex, "] => ", scriptResult)
+			}
+			currentRule = rule.NewScriptResultRule(currentRule, scriptResult)
+		}
 		var routeOptions *rule.RuleActionRouteOptions
 		switch action := currentRule.Action().(type) {
 		case *rule.RuleActionRoute:
@@ -508,6 +553,40 @@ match:
 	return
 }
 
+func (r *Router) traceRuleMatch(ctx context.Context, metadata adapter.InboundContext, selectedRule adapter.Rule, duration time.Duration, err error) {
+	var outbound string
+	if selectedRule == nil {
+		outbound = r.outbound.Default().Tag()
+	} else if routeAction, isRoute := selectedRule.Action().(*rule.RuleActionRoute); isRoute {
+		outbound = routeAction.Outbound
+	} else {
+		outbound = selectedRule.Action().Type()
+	}
+	r.tracer.TraceRuleMatch(ctx, metadata, selectedRule, outbound, duration, err)
+}
+
+// matchWithReverseMapping matches the rule with the domain found by the DNS reverse mapping if enabled for the rule,
+// and reports whether a domain item of the rule matched the domain.
+func (r *Router) matchWithReverseMapping(currentRule adapter.Rule, metadata *adapter.InboundContext) (matched bool, viaReverseMapping bool) {
+	metadata.ResetRuleCache()
+	metadata.ReverseMappingMatch = false
+	if metadata.ReverseMappingDomain == "" || metadata.Domain != "" || !isReverseMappingRule(currentRule) {
+		matched = currentRule.Match(metadata)
+	} else {
+		metadata.Domain = metadata.ReverseMappingDomain
+		matched = currentRule.Match(metadata)
+		metadata.Domain = ""
+	}
+	return matched, matched && metadata.ReverseMappingMatch
+}
+
+func isReverseMappingRule(currentRule adapter.Rule) bool {
+	reverseMappingRule, isReverseMapping := currentRule.(interface {
+		ReverseMapping() bool
+	})
+	return isReverseMapping && reverseMappingRule.ReverseMapping()
+}
+
 func (r *Router) actionSniff(
 	ctx context.Context, metadata *adapter.InboundContext, action *rule.RuleActionSniff,
 	inputConn net.Conn, inputPacketConn N.PacketConn,
@@ -37,14 +37,18 @@ type Router struct {
 	ruleSetMap        map[string]adapter.RuleSet
 	processSearcher   process.Searcher
 	pauseManager      pause.Manager
-	tracker           adapter.ConnectionTracker
+	trackers          []adapter.ConnectionTracker
+	tracer            adapter.Tracer
+	userLimiter       adapter.InboundUserLimiter
 	platformInterface platform.Interface
 	needWIFIState     bool
+	reverseMapping    bool
+	dnsReverseMapping bool
 	started           bool
 }
 
 func NewRouter(ctx context.Context, logFactory log.Factory, options option.RouteOptions, dnsOptions option.DNSOptions) *Router {
-	return &Router{
+	router := &Router{
 		ctx:               ctx,
 		logger:            logFactory.NewLogger("router"),
 		inbound:           service.FromContext[adapter.InboundManager](ctx),
@@ -60,7 +64,13 @@ func NewRouter(ctx context.Context, logFactory log.Factory, options option.Route
 		pauseManager:      service.FromContext[pause.Manager](ctx),
 		platformInterface: service.FromContext[platform.Interface](ctx),
 		needWIFIState:     hasRule(options.Rules, isWIFIRule) || hasDNSRule(dnsOptions.Rules, isWIFIDNSRule),
+		reverseMapping:    dnsOptions.ReverseMapping,
+		dnsReverseMapping: dnsOptions.ReverseMapping,
 	}
+	if options.ReverseMapping != nil {
+		router.reverseMapping = *options.ReverseMapping
+	}
+	return router
 }
 
 func (r *Router) Initialize(rules []option.Rule, ruleSets []option.RuleSet) error {
@@ -69,8 +79,14 @@ func (r *Router) Initialize(rules []option.Rule, ruleSets []option.RuleSet) erro
 		if err != nil {
 			return E.Cause(err, "parse rule[", i, "]")
 		}
+		if isReverseMappingRule(rule) && !r.dnsReverseMapping {
+			return E.New("parse rule[", i, "]: `reverse_mapping` requires `dns.reverse_mapping` to be enabled")
+		}
 		r.rules = append(r.rules, rule)
 	}
+	if r.reverseMapping && !r.dnsReverseMapping {
+		return E.New("`route.reverse_mapping` requires `dns.reverse_mapping` to be enabled")
+	}
 	for i, options := range ruleSets {
 		if _, exists := r.ruleSetMap[options.Tag]; exists {
 			return E.New("duplicate rule-set tag: ", options.Tag)
@@ -89,6 +105,8 @@ func (r *Router) Start(stage adapter.StartStage) error {
 	monitor := taskmonitor.New(r.logger, C.StartTimeout)
 	switch stage {
 	case adapter.StartStateStart:
+		r.tracer = service.FromContext[adapter.Tracer](r.ctx)
+		r.userLimiter = service.FromContext[adapter.InboundUserLimiter](r.ctx)
 		var cacheContext *adapter.HTTPStartContext
 		if len(r.ruleSets) > 0 {
 			monitor.Start("initialize rule-set")
@@ -205,6 +223,10 @@ func (r *Router) RuleSet(tag string) (adapter.RuleSet, bool) {
 	return ruleSet, loaded
 }
 
+func (r *Router) RuleSets() []adapter.RuleSet {
+	return r.ruleSets
+}
+
 func (r *Router) NeedWIFIState() bool {
 	return r.needWIFIState
 }
@@ -213,8 +235,8 @@ func (r *Router) Rules() []adapter.Rule {
 	return r.rules
 }
 
-func (r *Router) SetTracker(tracker adapter.ConnectionTracker) {
-	r.tracker = tracker
+func (r *Router) AppendTracker(tracker adapter.ConnectionTracker) {
+	r.trackers = append(r.trackers, tracker)
 }
 
 func (r *Router) ResetNetwork() {
@@ -20,6 +20,8 @@ import (
 	"github.com/sagernet/sing/common/logger"
 	M "github.com/sagernet/sing/common/metadata"
 	N "github.com/sagernet/sing/common/network"
+
+	mDNS "github.com/miekg/dns"
 )
 
 func NewRuleAction(ctx context.Context, logger logger.ContextLogger, action option.RuleAction) (adapter.RuleAction, error) {
@@ -91,6 +93,8 @@ func NewRuleAction(ctx context.Context, logger logger.ContextLogger, action opti
 			Strategy: C.DomainStrategy(action.ResolveOptions.Strategy),
 			Server:   action.ResolveOptions.Server,
 		}, nil
+	case C.RuleActionTypeScript:
+		return NewRuleActionScript(ctx, logger, action.ScriptOptions)
 	default:
 		panic(F.ToString("unknown rule action: ", action.Action))
 	}
@@ -108,6 +112,7 @@ func NewDNSRuleAction(logger logger.ContextLogger, action option.DNSRuleAction)
 				DisableCache: action.RouteOptions.DisableCache,
 				RewriteTTL:   action.RouteOptions.RewriteTTL,
 				ClientSubnet: netip.Prefix(common.PtrValueOrDefault(action.RouteOptions.ClientSubnet)),
+				DNSSEC:       action.RouteOptions.DNSSEC,
 			},
 		}
 	case C.RuleActionTypeRouteOptions:
@@ -116,6 +121,7 @@ func NewDNSRuleAction(logger logger.ContextLogger, action option.DNSRuleAction)
 			DisableCache: action.RouteOptionsOptions.DisableCache,
 			RewriteTTL:   action.RouteOptionsOptions.RewriteTTL,
 			ClientSubnet: netip.Prefix(common.PtrValueOrDefault(action.RouteOptionsOptions.ClientSubnet)),
+			DNSSEC:       action.RouteOptionsOptions.DNSSEC,
 		}
 	case C.RuleActionTypeReject:
 		return &RuleActionReject{
@@ -123,6 +129,17 @@ func NewDNSRuleAction(logger logger.ContextLogger, action option.DNSRuleAction)
 			NoDrop: action.RejectOptions.NoDrop,
 			logger: logger,
 		}
+	case C.RuleActionTypePredefined:
+		return &RuleActionPredefined{
+			RCode:  action.PredefinedOptions.RCode.Build(),
+			Answer: dnsRecords(action.PredefinedOptions.Answer),
+			Ns:     dnsRecords(action.PredefinedOptions.Ns),
+			Extra:  dnsRecords(action.PredefinedOptions.Extra),
+		}
+	case C.RuleActionTypeRewrite:
+		return &RuleActionDNSRewrite{
+			QueryName: mDNS.Fqdn(action.RewriteOptions.QueryName),
+		}
 	default:
 		panic(F.ToString("unknown rule action: ", action.Action))
 	}
@@ -223,6 +240,9 @@ func (r *RuleActionDNSRoute) String() string {
 	if r.ClientSubnet.IsValid() {
 		descriptions = append(descriptions, F.ToString("client-subnet=", r.ClientSubnet))
 	}
+	if r.DNSSEC {
+		descriptions = append(descriptions, "dnssec")
+	}
 	return F.ToString("route(", strings.Join(descriptions, ","), ")")
 }
 
@@ -231,6 +251,7 @@ type RuleActionDNSRouteOptions struct {
 	DisableCache bool
 	RewriteTTL   *uint32
 	ClientSubnet netip.Prefix
+	DNSSEC       bool
 }
 
 func (r *RuleActionDNSRouteOptions) Type() string {
@@ -248,9 +269,94 @@ func (r *RuleActionDNSRouteOptions) String() string {
 	if r.ClientSubnet.IsValid() {
 		descriptions = append(descriptions, F.ToString("client-subnet=", r.ClientSubnet))
 	}
+	if r.DNSSEC {
+		descriptions = append(descriptions, "dnssec")
+	}
 	return F.ToString("route-options(", strings.Join(descriptions, ","), ")")
 }
 
+type RuleActionPredefined struct {
+	RCode  int
+	Answer []mDNS.RR
+	Ns     []mDNS.RR
+	Extra  []mDNS.RR
+}
+
+func (r *RuleActionPredefined) Type() string {
+	return C.RuleActionTypePredefined
+}
+
+func (r *RuleActionPredefined) String() string {
+	var descriptions []string
+	descriptions = append(descriptions, mDNS.RcodeToString[r.RCode])
+	for _, record := range r.Answer {
+		descriptions = append(descriptions, strings.TrimPrefix(record.String(), record.Header().String()))
+	}
+	return F.ToString("predefined(", strings.Join(descriptions, ","), ")")
+}
+
+// Response builds the answer for the request.
+//
+// Records with the owner name `@` or `*` are renamed to the query name,
+// and answer records of other types than the query type (except CNAME) are dropped.
+func (r *RuleActionPredefined) Response(request *mDNS.Msg) *mDNS.Msg {
+	response := &mDNS.Msg{
+		MsgHdr: mDNS.MsgHdr{
+			Id:                 request.Id,
+			Response:           true,
+			Authoritative:      true,
+			RecursionDesired:   request.RecursionDesired,
+			RecursionAvailable: true,
+			Rcode:              r.RCode,
+		},
+		Question: request.Question,
+	}
+	var question mDNS.Question
+	if len(request.Question) > 0 {
+		question = request.Question[0]
+	}
+	for _, record := range r.Answer {
+		recordType := record.Header().Rrtype
+		if recordType != question.Qtype && recordType != mDNS.TypeCNAME && question.Qtype != mDNS.TypeANY {
+			continue
+		}
+		response.Answer = append(response.Answer, predefinedRecord(record, question))
+	}
+	for _, record := range r.Ns {
+		response.Ns = append(response.Ns, predefinedRecord(record, question))
+	}
+	for _, record := range r.Extra {
+		response.Extra = append(response.Extra, predefinedRecord(record, question))
+	}
+	return response
+}
+
+func dnsRecords(records []option.DNSRecordOptions) []mDNS.RR {
+	return common.Map(records, func(it option.DNSRecordOptions) mDNS.RR {
+		return it.RR
+	})
+}
+
+func predefinedRecord(record mDNS.RR, question mDNS.Question) mDNS.RR {
+	record = mDNS.Copy(record)
+	if question.Name != "" && (record.Header().Name == "." || record.Header().Name == "*.") {
+		record.Header().Name = question.Name
+	}
+	return record
+}
+
+type RuleActionDNSRewrite struct {
+	QueryName string
+}
+
+func (r *RuleActionDNSRewrite) Type() string {
+	return C.RuleActionTypeRewrite
+}
+
+func (r *RuleActionDNSRewrite) String() string {
+	return F.ToString("rewrite(", strings.TrimSuffix(r.QueryName, "."), ")")
+}
+
 type RuleActionDirect struct {
 	Dialer      N.Dialer
 	description string
@@ -0,0 +1,265 @@
+package rule
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/option"
+	E "github.com/sagernet/sing/common/exceptions"
+	F "github.com/sagernet/sing/common/format"
+	"github.com/sagernet/sing/common/json"
+	"github.com/sagernet/sing/common/logger"
+	"github.com/sagernet/sing/service/filemanager"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+const (
+	scriptFunctionName      = "route"
+	scriptDefaultTimeout    = 100 * time.Millisecond
+	scriptMaxExecutionSteps = 1_000_000
+)
+
+var _ adapter.RuleAction = (*RuleActionScript)(nil)
+
+// RuleActionScript calls the `route(metadata)` function of a Starlark script
+// to decide the action of a connection.
+//
+// The function may return None to continue matching the next rule,
+// an outbound tag, or a dict in the format of a rule action.
+type RuleActionScript struct {
+	ctx         context.Context
+	logger      logger.ContextLogger
+	path        string
+	timeout     time.Duration
+	routeScript starlark.Callable
+}
+
+func NewRuleActionScript(ctx context.Context, logger logger.ContextLogger, options option.RouteActionScript) (*RuleActionScript, error) {
+	var (
+		name    string
+		content []byte
+	)
+	if options.Code != "" {
+		if options.Path != "" {
+			return nil, E.New("code and path are mutually exclusive")
+		}
+		name = "script"
+		content = []byte(options.Code)
+	} else if options.Path != "" {
+		name = filemanager.BasePath(ctx, options.Path)
+		var err error
+		content, err = os.ReadFile(name)
+		if err != nil {
+			return nil, E.Cause(err, "read script")
+		}
+	} else {
+		return nil, E.New("missing code or path")
+	}
+	script := &RuleActionScript{
+		ctx:     ctx,
+		logger:  logger,
+		path:    options.Path,
+		timeout: time.Duration(options.Timeout),
+	}
+	if script.timeout == 0 {
+		script.timeout = scriptDefaultTimeout
+	}
+	thread, done := script.newThread(ctx)
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, name, content, nil)
+	done()
+	if err != nil {
+		return nil, E.Cause(err, "load script")
+	}
+	routeScript, isCallable := globals[scriptFunctionName].(starlark.Callable)
+	if !isCallable {
+		return nil, E.New("missing function `", scriptFunctionName, "` in script")
+	}
+	script.routeScript = routeScript
+	return script, nil
+}
+
+func (r *RuleActionScript) Type() string {
+	return C.RuleActionTypeScript
+}
+
+func (r *RuleActionScript) String() string {
+	if r.path == "" {
+		return "script"
+	}
+	return F.ToString("script(", r.path, ")")
+}
+
+// newThread creates a thread limited in execution steps, and cancelled on timeout or when the context is done.
+// The returned function must be called after the thread finishes.
+func (r *RuleActionScript) newThread(ctx context.Context) (*starlark.Thread, func()) {
+	thread := &starlark.Thread{
+		Name: "script",
+		Print: func(_ *starlark.Thread, message string) {
+			r.logger.DebugContext(ctx, "script: ", message)
+		},
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			return nil, E.New("load is not allowed in script")
+		},
+	}
+	thread.SetMaxExecutionSteps(scriptMaxExecutionSteps)
+	if ctx.Err() != nil {
+		thread.Cancel(ctx.Err().Error())
+	}
+	timer := time.AfterFunc(r.timeout, func() {
+		thread.Cancel("timeout")
+	})
+	stopCancel := context.AfterFunc(ctx, func() {
+		thread.Cancel(ctx.Err().Error())
+	})
+	return thread, func() {
+		timer.Stop()
+		stopCancel()
+	}
+}
+
+// Evaluate returns the action selected by the script, or nil if matching should continue.
+func (r *RuleActionScript) Evaluate(ctx context.Context, metadata *adapter.InboundContext) (adapter.RuleAction, error) {
+	thread, done := r.newThread(ctx)
+	result, err := starlark.Call(thread, r.routeScript, starlark.Tuple{scriptMetadata(metadata)}, nil)
+	done()
+	if err != nil {
+		return nil, err
+	}
+	switch result := result.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.String:
+		return &RuleActionRoute{Outbound: string(result)}, nil
+	case *starlark.Dict:
+		rawAction, err := fromStarlark(result)
+		if err != nil {
+			return nil, E.Cause(err, "parse script result")
+		}
+		content, err := json.Marshal(rawAction)
+		if err != nil {
+			return nil, E.Cause(err, "parse script result")
+		}
+		var action option.RuleAction
+		err = json.Unmarshal(content, &action)
+		if err != nil {
+			return nil, E.Cause(err, "parse script result")
+		}
+		if action.Action == C.RuleActionTypeScript {
+			return nil, E.New("script action is not allowed in script result")
+		}
+		return NewRuleAction(r.ctx, r.logger, action)
+	default:
+		return nil, E.New("unexpected script result type: ", result.Type())
+	}
+}
+
+func scriptMetadata(metadata *adapter.InboundContext) *starlark.Dict {
+	domain := metadata.Domain
+	if domain == "" {
+		domain = metadata.Destination.Fqdn
+	}
+	var destination string
+	if metadata.Destination.IsIP() {
+		destination = metadata.Destination.AddrString()
+	}
+	var source string
+	if metadata.Source.IsIP() {
+		source = metadata.Source.AddrString()
+	}
+	var processName, processPath, packageName, processUser string
+	if metadata.ProcessInfo != nil {
+		processPath = metadata.ProcessInfo.ProcessPath
+		if processPath != "" {
+			processName = filepath.Base(processPath)
+		}
+		packageName = metadata.ProcessInfo.PackageName
+		processUser = metadata.ProcessInfo.User
+	}
+	fields := []starlark.Tuple{
+		{starlark.String("network"), starlark.String(metadata.Network)},
+		{starlark.String("inbound"), starlark.String(metadata.Inbound)},
+		{starlark.String("inbound_type"), starlark.String(metadata.InboundType)},
+		{starlark.String("protocol"), starlark.String(metadata.Protocol)},
+		{starlark.String("user"), starlark.String(metadata.User)},
+		{starlark.String("source"), starlark.String(source)},
+		{starlark.String("source_port"), starlark.MakeInt(int(metadata.Source.Port))},
+		{starlark.String("domain"), starlark.String(domain)},
+		{starlark.String("destination"), starlark.String(destination)},
+		{starlark.String("port"), starlark.MakeInt(int(metadata.Destination.Port))},
+		{starlark.String("process_name"), starlark.String(processName)},
+		{starlark.String("process_path"), starlark.String(processPath)},
+		{starlark.String("package_name"), starlark.String(packageName)},
+		{starlark.String("process_user"), starlark.String(processUser)},
+	}
+	dict := starlark.NewDict(len(fields))
+	for _, field := range fields {
+		_ = dict.SetKey(field[0], field[1])
+	}
+	dict.Freeze()
+	return dict
+}
+
+func fromStarlark(value starlark.Value) (any, error) {
+	switch value := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(value), nil
+	case starlark.Int:
+		intValue, ok := value.Int64()
+		if !ok {
+			return nil, E.New("integer out of range: ", value.String())
+		}
+		return intValue, nil
+	case starlark.Float:
+		return float64(value), nil
+	case starlark.String:
+		return string(value), nil
+	case starlark.Indexable:
+		values := make([]any, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item, err := fromStarlark(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, item)
+		}
+		return values, nil
+	case *starlark.Dict:
+		values := make(map[string]any, value.Len())
+		for _, item := range value.Items() {
+			key, isString := item[0].(starlark.String)
+			if !isString {
+				return nil, E.New("unexpected dict key type: ", item[0].Type())
+			}
+			itemValue, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			values[string(key)] = itemValue
+		}
+		return values, nil
+	default:
+		return nil, E.New("unexpected value type: ", value.Type())
+	}
+}
+
+type scriptResultRule struct {
+	adapter.Rule
+	action adapter.RuleAction
+}
+
+// NewScriptResultRule wraps a rule with a script action so that it reports the action selected by the script.
+func NewScriptResultRule(rule adapter.Rule, action adapter.RuleAction) adapter.Rule {
+	return &scriptResultRule{Rule: rule, action: action}
+}
+
+func (r *scriptResultRule) Action() adapter.RuleAction {
+	return r.action
+}
@@ -0,0 +1,85 @@
+package rule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing/common/json/badoption"
+	M "github.com/sagernet/sing/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testScript = `
+def route(metadata):
+    if metadata["domain"].endswith(".cn"):
+        return "direct"
+    if metadata["port"] == 25:
+        return {"action": "reject"}
+    if metadata["port"] == 0:
+        fail("missing port")
+    return None
+`
+
+func newTestScript(code string) (*RuleActionScript, error) {
+	return NewRuleActionScript(context.Background(), log.NewNOPFactory().Logger(), option.RouteActionScript{
+		Code:    code,
+		Timeout: badoption.Duration(time.Second),
+	})
+}
+
+func TestScriptResult(t *testing.T) {
+	t.Parallel()
+	script, err := newTestScript(testScript)
+	require.NoError(t, err)
+
+	action, err := script.Evaluate(context.Background(), &adapter.InboundContext{Destination: M.ParseSocksaddr("example.cn:443")})
+	require.NoError(t, err)
+	require.IsType(t, &RuleActionRoute{}, action)
+	require.Equal(t, "direct", action.(*RuleActionRoute).Outbound)
+
+	action, err = script.Evaluate(context.Background(), &adapter.InboundContext{Destination: M.ParseSocksaddr("example.com:25")})
+	require.NoError(t, err)
+	require.Equal(t, C.RuleActionTypeReject, action.Type())
+
+	action, err = script.Evaluate(context.Background(), &adapter.InboundContext{Destination: M.ParseSocksaddr("example.com:443")})
+	require.NoError(t, err)
+	require.Nil(t, action)
+
+	_, err = script.Evaluate(context.Background(), &adapter.InboundContext{Destination: M.ParseSocksaddr("example.com")})
+	require.ErrorContains(t, err, "missing port")
+}
+
+func TestScriptLimit(t *testing.T) {
+	t.Parallel()
+	_, err := newTestScript(`
+def loop():
+    for _ in range(1000000000):
+        pass
+loop()
+def route(metadata):
+    return None
+`)
+	require.Error(t, err)
+
+	script, err := newTestScript(`
+def route(metadata):
+    for _ in range(1000000000):
+        pass
+`)
+	require.NoError(t, err)
+	_, err = script.Evaluate(context.Background(), &adapter.InboundContext{})
+	require.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	script, err = newTestScript(testScript)
+	require.NoError(t, err)
+	_, err = script.Evaluate(ctx, &adapter.InboundContext{Destination: M.ParseSocksaddr("example.com:443")})
+	require.ErrorContains(t, err, context.Canceled.Error())
+}
@@ -46,6 +46,7 @@ var _ adapter.Rule = (*DefaultRule)(nil)
 
 type DefaultRule struct {
 	abstractDefaultRule
+	reverseMapping bool
 }
 
 type RuleItem interface {
@@ -63,6 +64,7 @@ func NewDefaultRule(ctx context.Context, logger log.ContextLogger, options optio
 			invert: options.Invert,
 			action: action,
 		},
+		options.ReverseMapping,
 	}
 	router := service.FromContext[adapter.Router](ctx)
 	networkManager := service.FromContext[adapter.NetworkManager](ctx)
@@ -260,10 +262,16 @@ func NewDefaultRule(ctx context.Context, logger log.ContextLogger, options optio
 	return rule, nil
 }
 
+// ReverseMapping returns whether domains of IP connections found by the DNS reverse mapping are matched.
+func (r *DefaultRule) ReverseMapping() bool {
+	return r.reverseMapping
+}
+
 var _ adapter.Rule = (*LogicalRule)(nil)
 
 type LogicalRule struct {
 	abstractLogicalRule
+	reverseMapping bool
 }
 
 func NewLogicalRule(ctx context.Context, logger log.ContextLogger, options option.LogicalRule) (*LogicalRule, error) {
@@ -277,6 +285,7 @@ func NewLogicalRule(ctx context.Context, logger log.ContextLogger, options optio
 			invert: options.Invert,
 			action: action,
 		},
+		options.ReverseMapping,
 	}
 	switch options.Mode {
 	case C.LogicalTypeAnd:
@@ -295,3 +304,12 @@ func NewLogicalRule(ctx context.Context, logger log.ContextLogger, options optio
 	}
 	return rule, nil
 }
+
+func (r *LogicalRule) ReverseMapping() bool {
+	return r.reverseMapping || common.Any(r.rules, func(it adapter.HeadlessRule) bool {
+		subRule, isReverseMapping := it.(interface {
+			ReverseMapping() bool
+		})
+		return isReverseMapping && subRule.ReverseMapping()
+	})
+}
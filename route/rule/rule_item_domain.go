@@ -51,18 +51,29 @@ func NewRawDomainItem(matcher *domain.Matcher) *DomainItem {
 }
 
 func (r *DomainItem) Match(metadata *adapter.InboundContext) bool {
+	return matchDomainHost(metadata, func(domainHost string) bool {
+		return r.matcher.Match(domainHost)
+	})
+}
+
+func (r *DomainItem) String() string {
+	return r.description
+}
+
+// matchDomainHost matches the domain of the connection in lower case,
+// and records in the metadata if the domain is found by the DNS reverse mapping.
+func matchDomainHost(metadata *adapter.InboundContext, match func(domainHost string) bool) bool {
 	var domainHost string
 	if metadata.Domain != "" {
 		domainHost = metadata.Domain
 	} else {
 		domainHost = metadata.Destination.Fqdn
 	}
-	if domainHost == "" {
+	if domainHost == "" || !match(strings.ToLower(domainHost)) {
 		return false
 	}
-	return r.matcher.Match(strings.ToLower(domainHost))
-}
-
-func (r *DomainItem) String() string {
-	return r.description
+	if metadata.Domain != "" && metadata.Domain == metadata.ReverseMappingDomain {
+		metadata.ReverseMappingMatch = true
+	}
+	return true
 }
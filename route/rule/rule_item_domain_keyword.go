@@ -17,22 +17,14 @@ func NewDomainKeywordItem(keywords []string) *DomainKeywordItem {
 }
 
 func (r *DomainKeywordItem) Match(metadata *adapter.InboundContext) bool {
-	var domainHost string
-	if metadata.Domain != "" {
-		domainHost = metadata.Domain
-	} else {
-		domainHost = metadata.Destination.Fqdn
-	}
-	if domainHost == "" {
-		return false
-	}
-	domainHost = strings.ToLower(domainHost)
-	for _, keyword := range r.keywords {
-		if strings.Contains(domainHost, keyword) {
-			return true
+	return matchDomainHost(metadata, func(domainHost string) bool {
+		for _, keyword := range r.keywords {
+			if strings.Contains(domainHost, keyword) {
+				return true
+			}
 		}
-	}
-	return false
+		return false
+	})
 }
 
 func (r *DomainKeywordItem) String() string {
@@ -38,22 +38,14 @@ func NewDomainRegexItem(expressions []string) (*DomainRegexItem, error) {
 }
 
 func (r *DomainRegexItem) Match(metadata *adapter.InboundContext) bool {
-	var domainHost string
-	if metadata.Domain != "" {
-		domainHost = metadata.Domain
-	} else {
-		domainHost = metadata.Destination.Fqdn
-	}
-	if domainHost == "" {
-		return false
-	}
-	domainHost = strings.ToLower(domainHost)
-	for _, matcher := range r.matchers {
-		if matcher.MatchString(domainHost) {
-			return true
+	return matchDomainHost(metadata, func(domainHost string) bool {
+		for _, matcher := range r.matchers {
+			if matcher.MatchString(domainHost) {
+				return true
+			}
 		}
-	}
-	return false
+		return false
+	})
 }
 
 func (r *DomainRegexItem) String() string {
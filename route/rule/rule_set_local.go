@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/sagernet/fswatch"
 	"github.com/sagernet/sing-box/adapter"
@@ -30,9 +31,12 @@ type LocalRuleSet struct {
 	ctx            context.Context
 	logger         logger.Logger
 	tag            string
+	setType        string
 	rules          []adapter.HeadlessRule
+	ruleCount      int
 	metadata       adapter.RuleSetMetadata
 	fileFormat     string
+	lastUpdated    time.Time
 	watcher        *fswatch.Watcher
 	callbackAccess sync.Mutex
 	callbacks      list.List[adapter.RuleSetUpdateCallback]
@@ -44,8 +48,12 @@ func NewLocalRuleSet(ctx context.Context, logger logger.Logger, options option.R
 		ctx:        ctx,
 		logger:     logger,
 		tag:        options.Tag,
+		setType:    options.Type,
 		fileFormat: options.Format,
 	}
+	if ruleSet.setType == "" {
+		ruleSet.setType = C.RuleSetTypeLocal
+	}
 	if options.Type == C.RuleSetTypeInline {
 		if len(options.InlineOptions.Rules) == 0 {
 			return nil, E.New("empty inline rule-set")
@@ -82,6 +90,28 @@ func (s *LocalRuleSet) Name() string {
 	return s.tag
 }
 
+func (s *LocalRuleSet) Type() string {
+	return s.setType
+}
+
+func (s *LocalRuleSet) Format() string {
+	if s.setType == C.RuleSetTypeInline {
+		return ""
+	}
+	if s.fileFormat == "" {
+		return C.RuleSetFormatSource
+	}
+	return s.fileFormat
+}
+
+func (s *LocalRuleSet) RuleCount() int {
+	return s.ruleCount
+}
+
+func (s *LocalRuleSet) UpdateTime() time.Time {
+	return s.lastUpdated
+}
+
 func (s *LocalRuleSet) String() string {
 	return strings.Join(F.MapToString(s.rules), " ")
 }
@@ -142,7 +172,9 @@ func (s *LocalRuleSet) reloadRules(headlessRules []option.HeadlessRule) error {
 	metadata.ContainsWIFIRule = hasHeadlessRule(headlessRules, isWIFIHeadlessRule)
 	metadata.ContainsIPCIDRRule = hasHeadlessRule(headlessRules, isIPCIDRHeadlessRule)
 	s.rules = rules
+	s.ruleCount = len(rules)
 	s.metadata = metadata
+	s.lastUpdated = time.Now()
 	s.callbackAccess.Lock()
 	callbacks := s.callbacks.Array()
 	s.callbackAccess.Unlock()
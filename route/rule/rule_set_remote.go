@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/common/signature"
 	"github.com/sagernet/sing-box/common/srs"
 	C "github.com/sagernet/sing-box/constant"
 	"github.com/sagernet/sing-box/option"
@@ -32,7 +33,7 @@ import (
 	"go4.org/netipx"
 )
 
-var _ adapter.RuleSet = (*RemoteRuleSet)(nil)
+var _ adapter.RemoteRuleSet = (*RemoteRuleSet)(nil)
 
 type RemoteRuleSet struct {
 	ctx            context.Context
@@ -44,17 +45,28 @@ type RemoteRuleSet struct {
 	updateInterval time.Duration
 	dialer         N.Dialer
 	rules          []adapter.HeadlessRule
+	ruleCount      int
 	lastUpdated    time.Time
 	lastEtag       string
+	updateAccess   sync.Mutex
 	updateTicker   *time.Ticker
 	cacheFile      adapter.CacheFile
+	verifier       *signature.Verifier
 	pauseManager   pause.Manager
 	callbackAccess sync.Mutex
 	callbacks      list.List[adapter.RuleSetUpdateCallback]
 	refs           atomic.Int32
 }
 
-func NewRemoteRuleSet(ctx context.Context, logger logger.ContextLogger, options option.RuleSet) *RemoteRuleSet {
+func NewRemoteRuleSet(ctx context.Context, logger logger.ContextLogger, options option.RuleSet) (*RemoteRuleSet, error) {
+	var verifier *signature.Verifier
+	if options.RemoteOptions.Verify != nil {
+		var err error
+		verifier, err = signature.NewVerifier(*options.RemoteOptions.Verify)
+		if err != nil {
+			return nil, E.Cause(err, "parse verify options")
+		}
+	}
 	ctx, cancel := context.WithCancel(ctx)
 	var updateInterval time.Duration
 	if options.RemoteOptions.UpdateInterval > 0 {
@@ -69,14 +81,31 @@ func NewRemoteRuleSet(ctx context.Context, logger logger.ContextLogger, options
 		logger:         logger,
 		options:        options,
 		updateInterval: updateInterval,
+		verifier:       verifier,
 		pauseManager:   service.FromContext[pause.Manager](ctx),
-	}
+	}, nil
 }
 
 func (s *RemoteRuleSet) Name() string {
 	return s.options.Tag
 }
 
+func (s *RemoteRuleSet) Type() string {
+	return C.RuleSetTypeRemote
+}
+
+func (s *RemoteRuleSet) Format() string {
+	return s.options.Format
+}
+
+func (s *RemoteRuleSet) RuleCount() int {
+	return s.ruleCount
+}
+
+func (s *RemoteRuleSet) UpdateTime() time.Time {
+	return s.lastUpdated
+}
+
 func (s *RemoteRuleSet) String() string {
 	return strings.Join(F.MapToString(s.rules), " ")
 }
@@ -189,6 +218,7 @@ func (s *RemoteRuleSet) loadBytes(content []byte) error {
 	s.metadata.ContainsWIFIRule = hasHeadlessRule(plainRuleSet.Rules, isWIFIHeadlessRule)
 	s.metadata.ContainsIPCIDRRule = hasHeadlessRule(plainRuleSet.Rules, isIPCIDRHeadlessRule)
 	s.rules = rules
+	s.ruleCount = len(rules)
 	s.callbackAccess.Lock()
 	callbacks := s.callbacks.Array()
 	s.callbackAccess.Unlock()
@@ -200,11 +230,9 @@ func (s *RemoteRuleSet) loadBytes(content []byte) error {
 
 func (s *RemoteRuleSet) loopUpdate() {
 	if time.Since(s.lastUpdated) > s.updateInterval {
-		err := s.fetchOnce(s.ctx, nil)
+		err := s.Update(s.ctx)
 		if err != nil {
 			s.logger.Error("fetch rule-set ", s.options.Tag, ": ", err)
-		} else if s.refs.Load() == 0 {
-			s.rules = nil
 		}
 	}
 	for {
@@ -214,16 +242,27 @@ func (s *RemoteRuleSet) loopUpdate() {
 			return
 		case <-s.updateTicker.C:
 			s.pauseManager.WaitActive()
-			err := s.fetchOnce(s.ctx, nil)
+			err := s.Update(s.ctx)
 			if err != nil {
 				s.logger.Error("fetch rule-set ", s.options.Tag, ": ", err)
-			} else if s.refs.Load() == 0 {
-				s.rules = nil
 			}
 		}
 	}
 }
 
+func (s *RemoteRuleSet) Update(ctx context.Context) error {
+	s.updateAccess.Lock()
+	defer s.updateAccess.Unlock()
+	err := s.fetchOnce(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if s.refs.Load() == 0 {
+		s.rules = nil
+	}
+	return nil
+}
+
 func (s *RemoteRuleSet) fetchOnce(ctx context.Context, startContext *adapter.HTTPStartContext) error {
 	s.logger.Debug("updating rule-set ", s.options.Tag, " from URL: ", s.options.RemoteOptions.URL)
 	var httpClient *http.Client
@@ -280,6 +319,13 @@ func (s *RemoteRuleSet) fetchOnce(ctx context.Context, startContext *adapter.HTT
 		response.Body.Close()
 		return err
 	}
+	if s.verifier != nil {
+		err = s.verifier.Verify(ctx, httpClient, request, response, content)
+		if err != nil {
+			response.Body.Close()
+			return E.Cause(err, "verify rule-set")
+		}
+	}
 	err = s.loadBytes(content)
 	if err != nil {
 		response.Body.Close()
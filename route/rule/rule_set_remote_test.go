@@ -0,0 +1,34 @@
+package rule
+
+import (
+	"context"
+	"testing"
+
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteRuleSetRuleCount(t *testing.T) {
+	t.Parallel()
+	ruleSet, err := NewRemoteRuleSet(context.Background(), log.NewNOPFactory().Logger(), option.RuleSet{
+		Type:   C.RuleSetTypeRemote,
+		Tag:    "test",
+		Format: C.RuleSetFormatSource,
+	})
+	require.NoError(t, err)
+	defer ruleSet.Close()
+	require.NoError(t, ruleSet.loadBytes([]byte(`{
+  "version": 2,
+  "rules": [
+    {"domain_suffix": ["example.org"]},
+    {"ip_cidr": ["10.0.0.0/8"]}
+  ]
+}`)))
+	require.Equal(t, 2, ruleSet.RuleCount())
+	// rules are released when the rule-set is not referenced
+	ruleSet.Cleanup()
+	require.Equal(t, 2, ruleSet.RuleCount())
+}
@@ -28,6 +28,21 @@ func TestV2RayHTTPPlainSelf(t *testing.T) {
 	})
 }
 
+func TestV2RaySplitHTTPSelf(t *testing.T) {
+	testV2RayTransportSelf(t, &option.V2RayTransportOptions{
+		Type: C.V2RayTransportTypeSplitHTTP,
+		SplitHTTPOptions: option.V2RaySplitHTTPOptions{
+			MaxUploadSize: 1024,
+		},
+	})
+}
+
+func TestV2RaySplitHTTPPlainSelf(t *testing.T) {
+	testV2RayTransportNOTLSSelf(t, &option.V2RayTransportOptions{
+		Type: C.V2RayTransportTypeSplitHTTP,
+	})
+}
+
 func testV2RayTransportSelf(t *testing.T, transport *option.V2RayTransportOptions) {
 	testV2RayTransportSelfWith(t, transport, transport)
 }
@@ -0,0 +1,85 @@
+package shadowsocksr
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+
+	"github.com/sagernet/sing-shadowsocks"
+	E "github.com/sagernet/sing/common/exceptions"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+var MethodList = []string{
+	"none",
+	"aes-128-ctr",
+	"aes-192-ctr",
+	"aes-256-ctr",
+	"aes-128-cfb",
+	"aes-192-cfb",
+	"aes-256-cfb",
+	"rc4-md5",
+	"chacha20-ietf",
+	"xchacha20",
+}
+
+type streamMethod struct {
+	keyLength          int
+	ivLength           int
+	encryptConstructor func(key []byte, iv []byte) (cipher.Stream, error)
+	decryptConstructor func(key []byte, iv []byte) (cipher.Stream, error)
+}
+
+func newStreamMethod(method string) (*streamMethod, error) {
+	switch method {
+	case "none":
+		return &streamMethod{keyLength: 16}, nil
+	case "aes-128-ctr":
+		return &streamMethod{16, aes.BlockSize, blockStream(cipher.NewCTR), blockStream(cipher.NewCTR)}, nil
+	case "aes-192-ctr":
+		return &streamMethod{24, aes.BlockSize, blockStream(cipher.NewCTR), blockStream(cipher.NewCTR)}, nil
+	case "aes-256-ctr":
+		return &streamMethod{32, aes.BlockSize, blockStream(cipher.NewCTR), blockStream(cipher.NewCTR)}, nil
+	case "aes-128-cfb":
+		return &streamMethod{16, aes.BlockSize, blockStream(cipher.NewCFBEncrypter), blockStream(cipher.NewCFBDecrypter)}, nil
+	case "aes-192-cfb":
+		return &streamMethod{24, aes.BlockSize, blockStream(cipher.NewCFBEncrypter), blockStream(cipher.NewCFBDecrypter)}, nil
+	case "aes-256-cfb":
+		return &streamMethod{32, aes.BlockSize, blockStream(cipher.NewCFBEncrypter), blockStream(cipher.NewCFBDecrypter)}, nil
+	case "rc4-md5":
+		return &streamMethod{16, 16, rc4MD5Stream, rc4MD5Stream}, nil
+	case "chacha20-ietf":
+		return &streamMethod{chacha20.KeySize, chacha20.NonceSize, chacha20Stream, chacha20Stream}, nil
+	case "xchacha20":
+		return &streamMethod{chacha20.KeySize, chacha20.NonceSizeX, chacha20Stream, chacha20Stream}, nil
+	default:
+		return nil, E.New("unsupported method: ", method)
+	}
+}
+
+func (m *streamMethod) Key(password string) []byte {
+	return shadowsocks.Key([]byte(password), m.keyLength)
+}
+
+func blockStream(streamCreator func(block cipher.Block, iv []byte) cipher.Stream) func([]byte, []byte) (cipher.Stream, error) {
+	return func(key []byte, iv []byte) (cipher.Stream, error) {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return streamCreator(block, iv), nil
+	}
+}
+
+func rc4MD5Stream(key []byte, iv []byte) (cipher.Stream, error) {
+	hash := md5.New()
+	hash.Write(key)
+	hash.Write(iv)
+	return rc4.NewCipher(hash.Sum(nil))
+}
+
+func chacha20Stream(key []byte, iv []byte) (cipher.Stream, error) {
+	return chacha20.NewUnauthenticatedCipher(key, iv)
+}
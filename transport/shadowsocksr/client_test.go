@@ -0,0 +1,469 @@
+package shadowsocksr
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rc4"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	mRand "math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sagernet/sing-shadowsocks"
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+)
+
+// testClientConn is a minimal ShadowsocksR client used to test the server side.
+type testClientConn struct {
+	net.Conn
+	method      *streamMethod
+	key         []byte
+	obfs        testClientObfs
+	protocol    testClientProtocol
+	writeStream cipher.Stream
+	readStream  cipher.Stream
+	iv          []byte
+	cipherBuf   bytes.Buffer
+	protoBuf    bytes.Buffer
+	readBuf     bytes.Buffer
+}
+
+type testClientObfs interface {
+	Encode(conn net.Conn, data []byte) error
+	Decode(conn net.Conn, dst *bytes.Buffer, data []byte) error
+}
+
+type testClientProtocol interface {
+	Encode(dst *bytes.Buffer, data []byte)
+	Decode(dst *bytes.Buffer, src *bytes.Buffer) error
+}
+
+func newTestClientConn(conn net.Conn, methodName, password, obfsName, obfsParam, protocolName, protocolParam string) (*testClientConn, error) {
+	method, err := newStreamMethod(methodName)
+	if err != nil {
+		return nil, err
+	}
+	c := &testClientConn{Conn: conn, method: method, key: method.Key(password)}
+	c.iv = make([]byte, method.ivLength)
+	common.Must1(rand.Read(c.iv))
+	if method.ivLength > 0 {
+		c.writeStream = common.Must1(method.encryptConstructor(c.key, c.iv))
+	}
+	switch obfsName {
+	case "plain":
+		c.obfs = &testPlainObfs{}
+	case "http_simple":
+		c.obfs = &testHTTPSimpleObfs{host: obfsParam, ivLength: method.ivLength}
+	case "tls1.2_ticket_auth":
+		c.obfs = newTestTLSObfs(c.key)
+	}
+	switch protocolName {
+	case "origin":
+		c.protocol = &testOriginProtocol{}
+	case "auth_aes128_md5":
+		c.protocol = newTestAuthAES128(c.key, c.iv, protocolParam, "auth_aes128_md5", md5.New)
+	case "auth_aes128_sha1":
+		c.protocol = newTestAuthAES128(c.key, c.iv, protocolParam, "auth_aes128_sha1", sha1.New)
+	case "auth_chain_a":
+		c.protocol = newTestAuthChainA(c.key, c.iv, protocolParam)
+	}
+	return c, nil
+}
+
+func (c *testClientConn) Write(p []byte) (int, error) {
+	var buffer bytes.Buffer
+	if c.iv != nil {
+		buffer.Write(c.iv)
+	}
+	ivLength := buffer.Len()
+	c.protocol.Encode(&buffer, p)
+	if c.writeStream != nil {
+		encrypted := buffer.Bytes()[ivLength:]
+		c.writeStream.XORKeyStream(encrypted, encrypted)
+	}
+	c.iv = nil
+	return len(p), c.obfs.Encode(c.Conn, buffer.Bytes())
+}
+
+func (c *testClientConn) Read(p []byte) (int, error) {
+	buffer := make([]byte, 32*1024)
+	for c.readBuf.Len() == 0 {
+		n, err := c.Conn.Read(buffer)
+		if err != nil {
+			return 0, err
+		}
+		err = c.obfs.Decode(c.Conn, &c.cipherBuf, buffer[:n])
+		if err != nil {
+			return 0, err
+		}
+		if c.readStream == nil && c.method.ivLength > 0 {
+			if c.cipherBuf.Len() < c.method.ivLength {
+				continue
+			}
+			c.readStream = common.Must1(c.method.decryptConstructor(c.key, c.cipherBuf.Next(c.method.ivLength)))
+		}
+		data := c.cipherBuf.Bytes()
+		if c.readStream != nil {
+			c.readStream.XORKeyStream(data, data)
+		}
+		c.protoBuf.Write(data)
+		c.cipherBuf.Reset()
+		err = c.protocol.Decode(&c.readBuf, &c.protoBuf)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return c.readBuf.Read(p)
+}
+
+type testPlainObfs struct{}
+
+func (o *testPlainObfs) Encode(conn net.Conn, data []byte) error {
+	_, err := conn.Write(data)
+	return err
+}
+
+func (o *testPlainObfs) Decode(conn net.Conn, dst *bytes.Buffer, data []byte) error {
+	dst.Write(data)
+	return nil
+}
+
+type testHTTPSimpleObfs struct {
+	host           string
+	ivLength       int
+	headerSent     bool
+	headerReceived bool
+	receiveBuffer  []byte
+}
+
+func (o *testHTTPSimpleObfs) Encode(conn net.Conn, data []byte) error {
+	if o.headerSent {
+		_, err := conn.Write(data)
+		return err
+	}
+	o.headerSent = true
+	headLength := min(len(data), o.ivLength+30+mRand.Intn(65))
+	var request bytes.Buffer
+	request.WriteString("GET /")
+	for _, b := range data[:headLength] {
+		fmt.Fprintf(&request, "%%%02x", b)
+	}
+	request.WriteString(" HTTP/1.1\r\nHost: " + o.host + ":80\r\nUser-Agent: curl/8.0\r\nAccept: */*\r\nConnection: keep-alive\r\n\r\n")
+	request.Write(data[headLength:])
+	_, err := conn.Write(request.Bytes())
+	return err
+}
+
+func (o *testHTTPSimpleObfs) Decode(conn net.Conn, dst *bytes.Buffer, data []byte) error {
+	if o.headerReceived {
+		dst.Write(data)
+		return nil
+	}
+	o.receiveBuffer = append(o.receiveBuffer, data...)
+	headerEnd := bytes.Index(o.receiveBuffer, []byte("\r\n\r\n"))
+	if headerEnd == -1 {
+		return nil
+	}
+	if !bytes.HasPrefix(o.receiveBuffer, []byte("HTTP/1.1 200 OK\r\n")) {
+		return E.New("bad response")
+	}
+	dst.Write(o.receiveBuffer[headerEnd+4:])
+	o.headerReceived = true
+	return nil
+}
+
+type testTLSObfs struct {
+	key           []byte
+	clientID      []byte
+	status        int
+	sendBuffer    bytes.Buffer
+	receiveBuffer []byte
+}
+
+func newTestTLSObfs(key []byte) *testTLSObfs {
+	clientID := make([]byte, 32)
+	common.Must1(rand.Read(clientID))
+	return &testTLSObfs{key: key, clientID: clientID}
+}
+
+func (o *testTLSObfs) hmac(data []byte) []byte {
+	hash := hmac.New(sha1.New, append(bytes.Clone(o.key), o.clientID...))
+	hash.Write(data)
+	return hash.Sum(nil)[:10]
+}
+
+func (o *testTLSObfs) Encode(conn net.Conn, data []byte) error {
+	if o.status == 2 {
+		var buffer bytes.Buffer
+		(&tlsTicketAuthObfs{}).Encode(&buffer, data)
+		_, err := conn.Write(buffer.Bytes())
+		return err
+	}
+	(&tlsTicketAuthObfs{}).Encode(&o.sendBuffer, data)
+	if o.status == 1 {
+		return nil
+	}
+	o.status = 1
+	var hello bytes.Buffer
+	hello.Write([]byte{0x03, 0x03})
+	authData := make([]byte, 22)
+	binary.BigEndian.PutUint32(authData, uint32(time.Now().Unix()))
+	common.Must1(rand.Read(authData[4:]))
+	hello.Write(authData)
+	hello.Write(o.hmac(authData))
+	hello.WriteByte(0x20)
+	hello.Write(o.clientID)
+	hello.Write([]byte{0x00, 0x02, 0xc0, 0x2f, 0x01, 0x00, 0x00, 0x00})
+	var record bytes.Buffer
+	record.Write([]byte{0x16, 0x03, 0x01})
+	common.Must(binary.Write(&record, binary.BigEndian, uint16(hello.Len()+4)))
+	record.Write([]byte{0x01, 0x00})
+	common.Must(binary.Write(&record, binary.BigEndian, uint16(hello.Len())))
+	record.Write(hello.Bytes())
+	_, err := conn.Write(record.Bytes())
+	return err
+}
+
+func (o *testTLSObfs) Decode(conn net.Conn, dst *bytes.Buffer, data []byte) error {
+	o.receiveBuffer = append(o.receiveBuffer, data...)
+	if o.status != 2 {
+		serverHello := o.receiveBuffer
+		if len(serverHello) < 11+32+1+32 {
+			return E.New("short server hello")
+		}
+		if !hmac.Equal(serverHello[33:43], o.hmac(serverHello[11:33])) {
+			return E.New("bad server hello hmac")
+		}
+		if !hmac.Equal(serverHello[len(serverHello)-10:], o.hmac(serverHello[:len(serverHello)-10])) {
+			return E.New("bad server finished hmac")
+		}
+		o.receiveBuffer = nil
+		var finished bytes.Buffer
+		finished.Write([]byte{0x14, 0x03, 0x03, 0x00, 0x01, 0x01, 0x16, 0x03, 0x03, 0x00, 0x20})
+		random := make([]byte, 22)
+		common.Must1(rand.Read(random))
+		finished.Write(random)
+		finished.Write(o.hmac(finished.Bytes()))
+		finished.Write(o.sendBuffer.Bytes())
+		o.status = 2
+		_, err := conn.Write(finished.Bytes())
+		return err
+	}
+	for len(o.receiveBuffer) >= 5 {
+		length := int(binary.BigEndian.Uint16(o.receiveBuffer[3:5]))
+		if len(o.receiveBuffer) < 5+length {
+			break
+		}
+		dst.Write(o.receiveBuffer[5 : 5+length])
+		o.receiveBuffer = o.receiveBuffer[5+length:]
+	}
+	return nil
+}
+
+type testOriginProtocol struct{}
+
+func (p *testOriginProtocol) Encode(dst *bytes.Buffer, data []byte) {
+	dst.Write(data)
+}
+
+func (p *testOriginProtocol) Decode(dst *bytes.Buffer, src *bytes.Buffer) error {
+	_, err := src.WriteTo(dst)
+	return err
+}
+
+type testAuthBase struct {
+	key        []byte
+	iv         []byte
+	salt       string
+	hashFunc   func() hash.Hash
+	userID     [4]byte
+	userKey    []byte
+	headerSent bool
+	packID     uint32
+	recvID     uint32
+}
+
+func (p *testAuthBase) init(protocolParam string, hashKey func(password string) []byte) {
+	p.packID = 1
+	p.recvID = 1
+	if id, password, found := strings.Cut(protocolParam, ":"); found {
+		binary.LittleEndian.PutUint32(p.userID[:], uint32(common.Must1(strconv.ParseUint(id, 10, 32))))
+		p.userKey = hashKey(password)
+	} else {
+		common.Must1(rand.Read(p.userID[:]))
+		p.userKey = p.key
+	}
+}
+
+func (p *testAuthBase) hmac(key []byte, data []byte) []byte {
+	hash := hmac.New(p.hashFunc, key)
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func (p *testAuthBase) macKey(id uint32) []byte {
+	return binary.LittleEndian.AppendUint32(bytes.Clone(p.userKey), id)
+}
+
+func (p *testAuthBase) encryptedAuthData(first, second uint16) []byte {
+	authData := make([]byte, 16)
+	binary.LittleEndian.PutUint32(authData, uint32(time.Now().Unix()))
+	common.Must1(rand.Read(authData[4:12]))
+	binary.LittleEndian.PutUint16(authData[12:], first)
+	binary.LittleEndian.PutUint16(authData[14:], second)
+	key := shadowsocks.Key([]byte(base64.StdEncoding.EncodeToString(p.userKey)+p.salt), 16)
+	cipher.NewCBCEncrypter(common.Must1(aes.NewCipher(key)), make([]byte, 16)).CryptBlocks(authData, authData)
+	return authData
+}
+
+type testAuthAES128 struct {
+	testAuthBase
+	server *authAES128Protocol
+}
+
+func newTestAuthAES128(key, iv []byte, protocolParam, salt string, hashFunc func() hash.Hash) *testAuthAES128 {
+	p := &testAuthAES128{testAuthBase: testAuthBase{key: key, iv: iv, salt: salt, hashFunc: hashFunc}}
+	p.init(protocolParam, func(password string) []byte {
+		hash := hashFunc()
+		hash.Write([]byte(password))
+		return hash.Sum(nil)
+	})
+	// the packet format is symmetric, so the server packer is reused with client state
+	p.server = &authAES128Protocol{authBase{iv: iv, salt: salt, hashFunc: hashFunc, userKey: p.userKey, packID: 1, recvID: 1, headerDecoded: true}}
+	return p
+}
+
+func (p *testAuthAES128) Encode(dst *bytes.Buffer, data []byte) {
+	if !p.headerSent {
+		p.headerSent = true
+		headLength := min(len(data), 30+mRand.Intn(32))
+		randomLength := mRand.Intn(512)
+		length := 7 + 4 + 16 + 4 + randomLength + headLength + 4
+		macKey := append(bytes.Clone(p.iv), p.key...)
+		packet := []byte{byte(mRand.Intn(256))}
+		packet = append(packet, p.hmac(macKey, packet)[:6]...)
+		packet = append(packet, p.userID[:]...)
+		packet = append(packet, p.encryptedAuthData(uint16(length), uint16(randomLength))...)
+		packet = append(packet, p.hmac(macKey, packet[7:])[:4]...)
+		packet = append(packet, make([]byte, randomLength)...)
+		packet = append(packet, data[:headLength]...)
+		packet = append(packet, p.hmac(p.userKey, packet)[:4]...)
+		dst.Write(packet)
+		data = data[headLength:]
+		if len(data) == 0 {
+			return
+		}
+	}
+	p.server.Encode(dst, data)
+}
+
+func (p *testAuthAES128) Decode(dst *bytes.Buffer, src *bytes.Buffer) error {
+	return p.server.Decode(dst, src)
+}
+
+type testAuthChainA struct {
+	testAuthBase
+	lastClientHash []byte
+	lastServerHash []byte
+	encryptStream  cipher.Stream
+	decryptStream  cipher.Stream
+	randomClient   xorShift128Plus
+	randomServer   xorShift128Plus
+}
+
+func newTestAuthChainA(key, iv []byte, protocolParam string) *testAuthChainA {
+	p := &testAuthChainA{testAuthBase: testAuthBase{key: key, iv: iv, salt: "auth_chain_a", hashFunc: md5.New}}
+	p.init(protocolParam, func(password string) []byte {
+		return []byte(password)
+	})
+	return p
+}
+
+func (p *testAuthChainA) Encode(dst *bytes.Buffer, data []byte) {
+	if !p.headerSent {
+		p.headerSent = true
+		checkHead := make([]byte, 4)
+		common.Must1(rand.Read(checkHead))
+		p.lastClientHash = p.hmac(append(bytes.Clone(p.iv), p.key...), checkHead)
+		header := append(checkHead, p.lastClientHash[:8]...)
+		header = binary.LittleEndian.AppendUint32(header, binary.LittleEndian.Uint32(p.userID[:])^binary.LittleEndian.Uint32(p.lastClientHash[8:12]))
+		header = append(header, p.encryptedAuthData(0, 0)...)
+		p.lastServerHash = p.hmac(p.userKey, header[12:])
+		header = append(header, p.lastServerHash[:4]...)
+		dst.Write(header)
+		rc4Key := shadowsocks.Key([]byte(base64.StdEncoding.EncodeToString(p.userKey)+base64.StdEncoding.EncodeToString(p.lastClientHash)), 16)
+		p.encryptStream = common.Must1(rc4.NewCipher(rc4Key))
+		p.decryptStream = common.Must1(rc4.NewCipher(rc4Key))
+	}
+	for len(data) > 2800 {
+		p.packData(dst, data[:2800])
+		data = data[2800:]
+	}
+	if len(data) > 0 {
+		p.packData(dst, data)
+	}
+}
+
+func (p *testAuthChainA) packData(dst *bytes.Buffer, data []byte) {
+	encrypted := make([]byte, len(data))
+	p.encryptStream.XORKeyStream(encrypted, data)
+	randomLength := authChainARandomLength(len(data), p.lastClientHash, &p.randomClient)
+	packet := binary.LittleEndian.AppendUint16(nil, uint16(len(data))^binary.LittleEndian.Uint16(p.lastClientHash[14:16]))
+	randomData := make([]byte, randomLength)
+	if randomLength > 0 {
+		start := authChainARandomStart(randomLength, &p.randomClient)
+		packet = append(packet, randomData[:start]...)
+		packet = append(packet, encrypted...)
+		packet = append(packet, randomData[start:]...)
+	} else {
+		packet = append(packet, encrypted...)
+	}
+	p.lastClientHash = p.hmac(p.macKey(p.packID), packet)
+	packet = append(packet, p.lastClientHash[:2]...)
+	p.packID++
+	dst.Write(packet)
+}
+
+func (p *testAuthChainA) Decode(dst *bytes.Buffer, src *bytes.Buffer) error {
+	for src.Len() > 4 {
+		packet := src.Bytes()
+		dataLength := int(binary.LittleEndian.Uint16(packet[:2]) ^ binary.LittleEndian.Uint16(p.lastServerHash[14:16]))
+		randomLength := authChainARandomLength(dataLength, p.lastServerHash, &p.randomServer)
+		length := dataLength + randomLength
+		if length >= 4096 {
+			return E.New("bad length")
+		}
+		if src.Len() < length+4 {
+			break
+		}
+		serverHash := p.hmac(p.macKey(p.recvID), packet[:length+2])
+		if !hmac.Equal(serverHash[:2], packet[length+2:length+4]) {
+			return E.New("bad checksum")
+		}
+		p.lastServerHash = serverHash
+		position := 2
+		if dataLength > 0 && randomLength > 0 {
+			position += authChainARandomStart(randomLength, &p.randomServer)
+		}
+		data := packet[position : position+dataLength]
+		p.decryptStream.XORKeyStream(data, data)
+		if p.recvID == 1 {
+			data = data[2:]
+		}
+		dst.Write(data)
+		p.recvID++
+		src.Next(length + 4)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package shadowsocksr
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	mRand "math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+)
+
+var ObfsList = []string{
+	"plain",
+	"http_simple",
+	"tls1.2_ticket_auth",
+}
+
+// serverObfs removes the obfuscation of client data and applies it to server data.
+type serverObfs interface {
+	// Decode appends the data decoded from src to dst.
+	// A non-nil response must be written back to the client as is.
+	Decode(dst *bytes.Buffer, src []byte) (response []byte, err error)
+	Encode(dst *bytes.Buffer, src []byte)
+}
+
+func newServerObfs(name string, config *serverConfig) (serverObfs, error) {
+	switch name {
+	case "", "plain":
+		return (*plainObfs)(nil), nil
+	case "http_simple":
+		return &httpSimpleObfs{serverConfig: config}, nil
+	case "tls1.2_ticket_auth":
+		return &tlsTicketAuthObfs{serverConfig: config}, nil
+	default:
+		return nil, E.New("unsupported obfs: ", name)
+	}
+}
+
+type plainObfs struct{}
+
+func (o *plainObfs) Decode(dst *bytes.Buffer, src []byte) ([]byte, error) {
+	dst.Write(src)
+	return nil, nil
+}
+
+func (o *plainObfs) Encode(dst *bytes.Buffer, src []byte) {
+	dst.Write(src)
+}
+
+// httpSimpleObfs accepts the first client packet as an HTTP request with the head data
+// URL-encoded in the path, and prepends an HTTP response header to the first server packet.
+type httpSimpleObfs struct {
+	*serverConfig
+	receiveBuffer  []byte
+	headerReceived bool
+	headerSent     bool
+}
+
+func (o *httpSimpleObfs) Decode(dst *bytes.Buffer, src []byte) ([]byte, error) {
+	if o.headerReceived {
+		dst.Write(src)
+		return nil, nil
+	}
+	o.receiveBuffer = append(o.receiveBuffer, src...)
+	if len(o.receiveBuffer) < 10 {
+		return nil, nil
+	}
+	if !bytes.HasPrefix(o.receiveBuffer, []byte("GET ")) && !bytes.HasPrefix(o.receiveBuffer, []byte("POST ")) {
+		return nil, E.New("http_simple: not a http request")
+	}
+	headerEnd := bytes.Index(o.receiveBuffer, []byte("\r\n\r\n"))
+	if headerEnd == -1 {
+		if len(o.receiveBuffer) > 65536 {
+			return nil, E.New("http_simple: header too long")
+		}
+		return nil, nil
+	}
+	header := string(o.receiveBuffer[:headerEnd])
+	body := o.receiveBuffer[headerEnd+4:]
+	lines := strings.Split(header, "\r\n")
+	if o.obfsParam != "" {
+		var host string
+		for _, line := range lines[1:] {
+			key, value, found := strings.Cut(line, ":")
+			if found && strings.EqualFold(strings.TrimSpace(key), "Host") {
+				host = strings.TrimSpace(value)
+				break
+			}
+		}
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		hosts, _, _ := strings.Cut(o.obfsParam, "#")
+		if !common.Contains(strings.Split(hosts, ","), host) {
+			return nil, E.New("http_simple: unexpected host: ", host)
+		}
+	}
+	headData, err := decodeURLEncodedHeadData(lines[0])
+	if err != nil {
+		return nil, E.Cause(err, "http_simple: decode head data")
+	}
+	dst.Write(headData)
+	dst.Write(body)
+	o.headerReceived = true
+	o.receiveBuffer = nil
+	return nil, nil
+}
+
+func decodeURLEncodedHeadData(requestLine string) ([]byte, error) {
+	var headData []byte
+	items := strings.Split(requestLine, "%")
+	for _, item := range items[1:] {
+		if len(item) < 2 {
+			item = "0" + item
+		}
+		value, err := hex.DecodeString(item[:2])
+		if err != nil {
+			return nil, err
+		}
+		headData = append(headData, value...)
+		if len(item) > 2 {
+			break
+		}
+	}
+	return headData, nil
+}
+
+func (o *httpSimpleObfs) Encode(dst *bytes.Buffer, src []byte) {
+	if !o.headerSent {
+		dst.WriteString("HTTP/1.1 200 OK\r\nConnection: keep-alive\r\nContent-Encoding: gzip\r\nContent-Type: text/html\r\nDate: ")
+		dst.WriteString(o.timeFunc().UTC().Format(time.RFC1123))
+		dst.WriteString("\r\nServer: nginx\r\nVary: Accept-Encoding\r\n\r\n")
+		o.headerSent = true
+	}
+	dst.Write(src)
+}
+
+const (
+	tlsStatusClientHello = iota
+	tlsStatusClientFinished
+	tlsStatusApplicationData
+)
+
+// tlsTicketAuthObfs imitates a TLS 1.2 session resumption handshake authenticated with
+// HMAC-SHA1 of the server key and the client session ID, then frames data as application data records.
+type tlsTicketAuthObfs struct {
+	*serverConfig
+	status        int
+	clientID      []byte
+	receiveBuffer []byte
+}
+
+func (o *tlsTicketAuthObfs) Decode(dst *bytes.Buffer, src []byte) ([]byte, error) {
+	o.receiveBuffer = append(o.receiveBuffer, src...)
+	var response []byte
+	for {
+		switch o.status {
+		case tlsStatusClientHello:
+			if len(o.receiveBuffer) < 5 {
+				return response, nil
+			}
+			if !bytes.Equal(o.receiveBuffer[:3], []byte{0x16, 0x03, 0x01}) {
+				return nil, E.New("tls1.2_ticket_auth: not a client hello")
+			}
+			recordLength := int(binary.BigEndian.Uint16(o.receiveBuffer[3:5]))
+			if len(o.receiveBuffer) < 5+recordLength {
+				return response, nil
+			}
+			err := o.readClientHello(o.receiveBuffer[5 : 5+recordLength])
+			if err != nil {
+				return nil, E.Cause(err, "tls1.2_ticket_auth")
+			}
+			o.receiveBuffer = o.receiveBuffer[5+recordLength:]
+			response = o.serverHello()
+			o.status = tlsStatusClientFinished
+		case tlsStatusClientFinished:
+			// ChangeCipherSpec(6), Finished header(5), random(22), HMAC(10)
+			if len(o.receiveBuffer) < 43 {
+				return response, nil
+			}
+			if !bytes.Equal(o.receiveBuffer[:11], []byte{0x14, 0x03, 0x03, 0x00, 0x01, 0x01, 0x16, 0x03, 0x03, 0x00, 0x20}) {
+				return nil, E.New("tls1.2_ticket_auth: bad client finished")
+			}
+			if !hmac.Equal(o.hmac(o.receiveBuffer[:33]), o.receiveBuffer[33:43]) {
+				return nil, E.New("tls1.2_ticket_auth: bad client finished hmac")
+			}
+			o.receiveBuffer = o.receiveBuffer[43:]
+			o.status = tlsStatusApplicationData
+		default:
+			for len(o.receiveBuffer) >= 5 {
+				if !bytes.Equal(o.receiveBuffer[:3], []byte{0x17, 0x03, 0x03}) {
+					return nil, E.New("tls1.2_ticket_auth: bad application data")
+				}
+				recordLength := int(binary.BigEndian.Uint16(o.receiveBuffer[3:5]))
+				if len(o.receiveBuffer) < 5+recordLength {
+					break
+				}
+				dst.Write(o.receiveBuffer[5 : 5+recordLength])
+				o.receiveBuffer = o.receiveBuffer[5+recordLength:]
+			}
+			return response, nil
+		}
+	}
+}
+
+func (o *tlsTicketAuthObfs) readClientHello(hello []byte) error {
+	// handshake type(1), length(3), version(2), random(32), session ID length(1)
+	if len(hello) < 39 || hello[0] != 0x01 || hello[1] != 0x00 {
+		return E.New("not a client hello")
+	}
+	if int(binary.BigEndian.Uint16(hello[2:4])) != len(hello)-4 {
+		return E.New("bad client hello length")
+	}
+	if hello[4] != 0x03 || hello[5] != 0x03 {
+		return E.New("bad tls version")
+	}
+	verifyID := hello[6:38]
+	sessionIDLength := int(hello[38])
+	if sessionIDLength < 32 || len(hello) < 39+sessionIDLength {
+		return E.New("bad session ID")
+	}
+	o.clientID = bytes.Clone(hello[39 : 39+sessionIDLength])
+	if !hmac.Equal(o.hmac(verifyID[:22]), verifyID[22:]) {
+		return E.New("bad client hello hmac")
+	}
+	maxTimeDiff := defaultMaxTimeDiff
+	if o.obfsParam != "" {
+		if value, err := strconv.ParseUint(o.obfsParam, 10, 32); err == nil {
+			maxTimeDiff = time.Duration(value) * time.Second
+		}
+	}
+	if !o.checkTime(binary.BigEndian.Uint32(verifyID[:4]), maxTimeDiff) {
+		return E.New("bad client hello time")
+	}
+	if !o.checkReplay("tls" + string(verifyID[:22])) {
+		return E.New("replay detected")
+	}
+	return nil
+}
+
+func (o *tlsTicketAuthObfs) serverHello() []byte {
+	var hello bytes.Buffer
+	hello.Write([]byte{0x03, 0x03})
+	o.putAuthData(&hello)
+	hello.WriteByte(0x20)
+	hello.Write(o.clientID)
+	hello.Write([]byte{0xc0, 0x2f, 0x00, 0x00, 0x05, 0xff, 0x01, 0x00, 0x01, 0x00})
+	var response bytes.Buffer
+	response.Write([]byte{0x16, 0x03, 0x03})
+	common.Must(binary.Write(&response, binary.BigEndian, uint16(hello.Len()+4)))
+	response.Write([]byte{0x02, 0x00})
+	common.Must(binary.Write(&response, binary.BigEndian, uint16(hello.Len())))
+	response.Write(hello.Bytes())
+	if mRand.Intn(9) == 0 {
+		ticket := make([]byte, mRand.Intn(164)*2+64)
+		common.Must1(rand.Read(ticket))
+		response.Write([]byte{0x16, 0x03, 0x03})
+		common.Must(binary.Write(&response, binary.BigEndian, uint16(len(ticket)+4)))
+		response.Write([]byte{0x04, 0x00})
+		common.Must(binary.Write(&response, binary.BigEndian, uint16(len(ticket))))
+		response.Write(ticket)
+	}
+	response.Write([]byte{0x14, 0x03, 0x03, 0x00, 0x01, 0x01})
+	finishedLength := 32
+	if mRand.Intn(2) == 0 {
+		finishedLength = 40
+	}
+	response.Write([]byte{0x16, 0x03, 0x03})
+	common.Must(binary.Write(&response, binary.BigEndian, uint16(finishedLength)))
+	finished := make([]byte, finishedLength-10)
+	common.Must1(rand.Read(finished))
+	response.Write(finished)
+	response.Write(o.hmac(response.Bytes()))
+	return response.Bytes()
+}
+
+func (o *tlsTicketAuthObfs) putAuthData(dst *bytes.Buffer) {
+	authData := make([]byte, 22)
+	binary.BigEndian.PutUint32(authData, uint32(o.timeFunc().Unix()))
+	common.Must1(rand.Read(authData[4:]))
+	dst.Write(authData)
+	dst.Write(o.hmac(authData))
+}
+
+func (o *tlsTicketAuthObfs) hmac(data []byte) []byte {
+	hash := hmac.New(sha1.New, append(bytes.Clone(o.key), o.clientID...))
+	hash.Write(data)
+	return hash.Sum(nil)[:10]
+}
+
+func (o *tlsTicketAuthObfs) Encode(dst *bytes.Buffer, src []byte) {
+	for len(src) > 2048 {
+		size := min(mRand.Intn(4096)+100, len(src))
+		putTLSApplicationData(dst, src[:size])
+		src = src[size:]
+	}
+	if len(src) > 0 {
+		putTLSApplicationData(dst, src)
+	}
+}
+
+func putTLSApplicationData(dst *bytes.Buffer, data []byte) {
+	dst.Write([]byte{0x17, 0x03, 0x03})
+	common.Must(binary.Write(dst, binary.BigEndian, uint16(len(data))))
+	dst.Write(data)
+}
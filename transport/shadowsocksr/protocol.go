@@ -0,0 +1,428 @@
+package shadowsocksr
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rc4"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"hash"
+	mRand "math/rand"
+
+	"github.com/sagernet/sing-shadowsocks"
+	"github.com/sagernet/sing/common"
+	E "github.com/sagernet/sing/common/exceptions"
+)
+
+var ProtocolList = []string{
+	"origin",
+	"auth_aes128_md5",
+	"auth_aes128_sha1",
+	"auth_chain_a",
+}
+
+// serverProtocol removes the protocol framing of decrypted client data and applies it
+// to server data before encryption.
+type serverProtocol interface {
+	// Decode consumes complete packets from src and appends their payload to dst.
+	Decode(dst *bytes.Buffer, src *bytes.Buffer) error
+	Encode(dst *bytes.Buffer, src []byte)
+	// UserID returns the authenticated user once the first packet is decoded.
+	UserID() (uint32, bool)
+}
+
+func newServerProtocol(name string, config *serverConfig, iv []byte) (serverProtocol, error) {
+	switch name {
+	case "", "origin":
+		return (*originProtocol)(nil), nil
+	case "auth_aes128_md5":
+		return newAuthAES128Protocol(config, iv, "auth_aes128_md5", md5.New), nil
+	case "auth_aes128_sha1":
+		return newAuthAES128Protocol(config, iv, "auth_aes128_sha1", sha1.New), nil
+	case "auth_chain_a":
+		return newAuthChainAProtocol(config, iv), nil
+	default:
+		return nil, E.New("unsupported protocol: ", name)
+	}
+}
+
+func isAuthProtocol(name string) bool {
+	return name != "" && name != "origin"
+}
+
+type originProtocol struct{}
+
+func (p *originProtocol) Decode(dst *bytes.Buffer, src *bytes.Buffer) error {
+	_, err := src.WriteTo(dst)
+	return err
+}
+
+func (p *originProtocol) Encode(dst *bytes.Buffer, src []byte) {
+	dst.Write(src)
+}
+
+func (p *originProtocol) UserID() (uint32, bool) {
+	return 0, false
+}
+
+type authBase struct {
+	*serverConfig
+	iv            []byte
+	salt          string
+	hashFunc      func() hash.Hash
+	userID        uint32
+	userKey       []byte
+	headerDecoded bool
+	isUser        bool
+	packID        uint32
+	recvID        uint32
+}
+
+func (p *authBase) hmac(key []byte, data ...[]byte) []byte {
+	hash := hmac.New(p.hashFunc, key)
+	for _, item := range data {
+		hash.Write(item)
+	}
+	return hash.Sum(nil)
+}
+
+func (p *authBase) macKey(id uint32) []byte {
+	return binary.LittleEndian.AppendUint32(bytes.Clone(p.userKey), id)
+}
+
+// selectUser resolves the user key from the claimed user ID. Without configured users, the
+// server key is used; an unknown user gets a key that fails the following HMAC check.
+func (p *authBase) selectUser(userID uint32, hashKey func(password string) []byte) {
+	p.userID = userID
+	password, loaded := p.userPassword(userID)
+	if loaded {
+		p.userKey = hashKey(password)
+		p.isUser = true
+	} else if p.hasUsers() {
+		p.userKey = p.iv
+	} else {
+		p.userKey = p.key
+	}
+}
+
+// decryptAuthData decrypts the 16 bytes of auth data with AES-128-CBC keyed by the user key and the protocol salt.
+func (p *authBase) decryptAuthData(encrypted []byte) []byte {
+	key := shadowsocks.Key([]byte(base64.StdEncoding.EncodeToString(p.userKey)+p.salt), 16)
+	block := common.Must1(aes.NewCipher(key))
+	authData := make([]byte, aes.BlockSize)
+	cipher.NewCBCDecrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(authData, encrypted)
+	return authData
+}
+
+// checkAuthData verifies the time and the client and connection IDs of decrypted auth data.
+func (p *authBase) checkAuthData(authData []byte) error {
+	if !p.checkTime(binary.LittleEndian.Uint32(authData[:4]), defaultMaxTimeDiff) {
+		return E.New("bad auth time")
+	}
+	if !p.checkReplay(string(binary.LittleEndian.AppendUint32(bytes.Clone(authData[4:12]), p.userID))) {
+		return E.New("replay detected")
+	}
+	return nil
+}
+
+func (p *authBase) UserID() (uint32, bool) {
+	return p.userID, p.isUser
+}
+
+type authAES128Protocol struct {
+	authBase
+}
+
+func newAuthAES128Protocol(config *serverConfig, iv []byte, salt string, hashFunc func() hash.Hash) *authAES128Protocol {
+	return &authAES128Protocol{authBase{
+		serverConfig: config,
+		iv:           iv,
+		salt:         salt,
+		hashFunc:     hashFunc,
+		packID:       1,
+		recvID:       1,
+	}}
+}
+
+func (p *authAES128Protocol) Decode(dst *bytes.Buffer, src *bytes.Buffer) error {
+	if !p.headerDecoded {
+		// check head(1), HMAC of check head(6), user ID(4), encrypted auth data(16), HMAC(4)
+		if src.Len() < 31 {
+			return nil
+		}
+		header := src.Bytes()
+		macKey := append(bytes.Clone(p.iv), p.key...)
+		if !hmac.Equal(p.hmac(macKey, header[:1])[:6], header[1:7]) {
+			return E.New("auth_aes128: bad check head")
+		}
+		if !hmac.Equal(p.hmac(macKey, header[7:27])[:4], header[27:31]) {
+			return E.New("auth_aes128: bad auth data hmac")
+		}
+		p.selectUser(binary.LittleEndian.Uint32(header[7:11]), func(password string) []byte {
+			hash := p.hashFunc()
+			hash.Write([]byte(password))
+			return hash.Sum(nil)
+		})
+		authData := p.decryptAuthData(header[11:27])
+		length := int(binary.LittleEndian.Uint16(authData[12:14]))
+		randomLength := int(binary.LittleEndian.Uint16(authData[14:16]))
+		if length < 31+randomLength+4 || length >= 8192 {
+			return E.New("auth_aes128: bad packet length")
+		}
+		if src.Len() < length {
+			return nil
+		}
+		if !hmac.Equal(p.hmac(p.userKey, header[:length-4])[:4], header[length-4:length]) {
+			return E.New("auth_aes128: bad checksum")
+		}
+		err := p.checkAuthData(authData)
+		if err != nil {
+			return E.Cause(err, "auth_aes128")
+		}
+		dst.Write(header[31+randomLength : length-4])
+		src.Next(length)
+		p.headerDecoded = true
+	}
+	for src.Len() > 4 {
+		packet := src.Bytes()
+		macKey := p.macKey(p.recvID)
+		if !hmac.Equal(p.hmac(macKey, packet[:2])[:2], packet[2:4]) {
+			return E.New("auth_aes128: bad packet length hmac")
+		}
+		length := int(binary.LittleEndian.Uint16(packet[:2]))
+		if length >= 8192 || length < 7 {
+			return E.New("auth_aes128: bad packet length")
+		}
+		if src.Len() < length {
+			break
+		}
+		if !hmac.Equal(p.hmac(macKey, packet[:length-4])[:4], packet[length-4:length]) {
+			return E.New("auth_aes128: bad packet checksum")
+		}
+		p.recvID++
+		position := int(packet[4])
+		if position < 255 {
+			position += 4
+		} else {
+			position = int(binary.LittleEndian.Uint16(packet[5:7])) + 4
+		}
+		if position > length-4 {
+			return E.New("auth_aes128: bad padding length")
+		}
+		dst.Write(packet[position : length-4])
+		src.Next(length)
+	}
+	return nil
+}
+
+func (p *authAES128Protocol) Encode(dst *bytes.Buffer, src []byte) {
+	fullLength := len(src)
+	for len(src) > 8100 {
+		p.packData(dst, src[:8100], fullLength)
+		src = src[8100:]
+	}
+	p.packData(dst, src, fullLength)
+}
+
+func (p *authAES128Protocol) packData(dst *bytes.Buffer, data []byte, fullLength int) {
+	var randomLength int
+	if fullLength >= 32*1024 || len(data) > 1200 {
+		randomLength = 0
+	} else if p.packID > 4 {
+		randomLength = mRand.Intn(32)
+	} else if len(data) > 900 {
+		randomLength = mRand.Intn(128)
+	} else {
+		randomLength = mRand.Intn(512)
+	}
+	packet := make([]byte, 0, 4+3+randomLength+len(data)+4)
+	packet = append(packet, 0, 0, 0, 0)
+	if randomLength < 128 {
+		packet = append(packet, byte(randomLength+1))
+	} else {
+		packet = append(packet, 255)
+		packet = binary.LittleEndian.AppendUint16(packet, uint16(randomLength+3))
+	}
+	packet = append(packet, make([]byte, randomLength)...)
+	common.Must1(rand.Read(packet[len(packet)-randomLength:]))
+	packet = append(packet, data...)
+	binary.LittleEndian.PutUint16(packet, uint16(len(packet)+4))
+	macKey := p.macKey(p.packID)
+	copy(packet[2:4], p.hmac(macKey, packet[:2]))
+	packet = append(packet, p.hmac(macKey, packet)[:4]...)
+	p.packID++
+	dst.Write(packet)
+}
+
+type authChainAProtocol struct {
+	authBase
+	clientOverhead int
+	lastClientHash []byte
+	lastServerHash []byte
+	encryptStream  cipher.Stream
+	decryptStream  cipher.Stream
+	randomClient   xorShift128Plus
+	randomServer   xorShift128Plus
+}
+
+func newAuthChainAProtocol(config *serverConfig, iv []byte) *authChainAProtocol {
+	return &authChainAProtocol{authBase: authBase{
+		serverConfig: config,
+		iv:           iv,
+		salt:         "auth_chain_a",
+		hashFunc:     md5.New,
+		packID:       1,
+		recvID:       1,
+	}}
+}
+
+func (p *authChainAProtocol) Decode(dst *bytes.Buffer, src *bytes.Buffer) error {
+	if !p.headerDecoded {
+		// check head(4), HMAC of check head(8), user ID(4), encrypted auth data(16), HMAC(4)
+		if src.Len() < 36 {
+			return nil
+		}
+		header := src.Bytes()
+		p.lastClientHash = p.hmac(append(bytes.Clone(p.iv), p.key...), header[:4])
+		if !hmac.Equal(p.lastClientHash[:8], header[4:12]) {
+			return E.New("auth_chain_a: bad check head")
+		}
+		p.selectUser(binary.LittleEndian.Uint32(header[12:16])^binary.LittleEndian.Uint32(p.lastClientHash[8:12]), func(password string) []byte {
+			return []byte(password)
+		})
+		p.lastServerHash = p.hmac(p.userKey, header[12:32])
+		if !hmac.Equal(p.lastServerHash[:4], header[32:36]) {
+			return E.New("auth_chain_a: bad auth data hmac")
+		}
+		authData := p.decryptAuthData(header[16:32])
+		err := p.checkAuthData(authData)
+		if err != nil {
+			return E.Cause(err, "auth_chain_a")
+		}
+		p.clientOverhead = int(binary.LittleEndian.Uint16(authData[12:14]))
+		rc4Key := shadowsocks.Key([]byte(base64.StdEncoding.EncodeToString(p.userKey)+base64.StdEncoding.EncodeToString(p.lastClientHash)), 16)
+		p.encryptStream = common.Must1(rc4.NewCipher(rc4Key))
+		p.decryptStream = common.Must1(rc4.NewCipher(rc4Key))
+		src.Next(36)
+		p.headerDecoded = true
+	}
+	for src.Len() > 4 {
+		packet := src.Bytes()
+		dataLength := int(binary.LittleEndian.Uint16(packet[:2]) ^ binary.LittleEndian.Uint16(p.lastClientHash[14:16]))
+		randomLength := authChainARandomLength(dataLength, p.lastClientHash, &p.randomClient)
+		length := dataLength + randomLength
+		if length >= 4096 {
+			return E.New("auth_chain_a: bad packet length")
+		}
+		if src.Len() < length+4 {
+			break
+		}
+		clientHash := p.hmac(p.macKey(p.recvID), packet[:length+2])
+		if !hmac.Equal(clientHash[:2], packet[length+2:length+4]) {
+			return E.New("auth_chain_a: bad packet checksum")
+		}
+		p.recvID++
+		position := 2
+		if dataLength > 0 && randomLength > 0 {
+			position += authChainARandomStart(randomLength, &p.randomClient)
+		}
+		data := packet[position : position+dataLength]
+		p.decryptStream.XORKeyStream(data, data)
+		dst.Write(data)
+		p.lastClientHash = clientHash
+		src.Next(length + 4)
+	}
+	return nil
+}
+
+const authChainATCPMSS = 1460
+
+func (p *authChainAProtocol) Encode(dst *bytes.Buffer, src []byte) {
+	unitLength := 2800
+	if p.packID == 1 {
+		// the first server packet starts with the TCP MSS, which clients use to size their packets
+		src = append(binary.LittleEndian.AppendUint16(nil, authChainATCPMSS), src...)
+	}
+	if authChainATCPMSS-p.clientOverhead > 0 {
+		unitLength = authChainATCPMSS - p.clientOverhead
+	}
+	for len(src) > unitLength {
+		p.packData(dst, src[:unitLength])
+		src = src[unitLength:]
+	}
+	p.packData(dst, src)
+}
+
+func (p *authChainAProtocol) packData(dst *bytes.Buffer, data []byte) {
+	encrypted := make([]byte, len(data))
+	p.encryptStream.XORKeyStream(encrypted, data)
+	randomLength := authChainARandomLength(len(data), p.lastServerHash, &p.randomServer)
+	packet := make([]byte, 2, 2+randomLength+len(data)+2)
+	binary.LittleEndian.PutUint16(packet, uint16(len(data))^binary.LittleEndian.Uint16(p.lastServerHash[14:16]))
+	randomData := make([]byte, randomLength)
+	common.Must1(rand.Read(randomData))
+	if len(data) > 0 && randomLength > 0 {
+		start := authChainARandomStart(randomLength, &p.randomServer)
+		packet = append(packet, randomData[:start]...)
+		packet = append(packet, encrypted...)
+		packet = append(packet, randomData[start:]...)
+	} else {
+		packet = append(packet, randomData...)
+		packet = append(packet, encrypted...)
+	}
+	p.lastServerHash = p.hmac(p.macKey(p.packID), packet)
+	packet = append(packet, p.lastServerHash[:2]...)
+	p.packID++
+	dst.Write(packet)
+}
+
+func authChainARandomLength(dataLength int, lastHash []byte, random *xorShift128Plus) int {
+	if dataLength > 1440 {
+		return 0
+	}
+	random.initFromBinAndLength(lastHash, dataLength)
+	if dataLength > 1300 {
+		return int(random.next() % 31)
+	}
+	if dataLength > 900 {
+		return int(random.next() % 127)
+	}
+	if dataLength > 400 {
+		return int(random.next() % 521)
+	}
+	return int(random.next() % 1021)
+}
+
+func authChainARandomStart(randomLength int, random *xorShift128Plus) int {
+	return int(random.next() % 8589934609 % uint64(randomLength))
+}
+
+type xorShift128Plus struct {
+	s [2]uint64
+}
+
+func (r *xorShift128Plus) next() uint64 {
+	x := r.s[0]
+	y := r.s[1]
+	r.s[0] = y
+	x ^= x << 23
+	x ^= y ^ (x >> 17) ^ (y >> 26)
+	r.s[1] = x
+	return x + y
+}
+
+func (r *xorShift128Plus) initFromBinAndLength(bin []byte, length int) {
+	var seed [16]byte
+	copy(seed[:], bin)
+	binary.LittleEndian.PutUint16(seed[:], uint16(length))
+	r.s[0] = binary.LittleEndian.Uint64(seed[:8])
+	r.s[1] = binary.LittleEndian.Uint64(seed[8:])
+	for i := 0; i < 4; i++ {
+		r.next()
+	}
+}
@@ -0,0 +1,291 @@
+package shadowsocksr
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/auth"
+	E "github.com/sagernet/sing/common/exceptions"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+	"github.com/sagernet/sing/contrab/freelru"
+	"github.com/sagernet/sing/contrab/maphash"
+)
+
+const (
+	defaultMaxTimeDiff = 24 * time.Hour
+	replayFilterSize   = 65536
+)
+
+type ServiceOptions struct {
+	Method    string
+	Password  string
+	Obfs      string
+	ObfsParam string
+	Protocol  string
+	TimeFunc  func() time.Time
+	Handler   N.TCPConnectionHandlerEx
+}
+
+// Service accepts ShadowsocksR connections. Users are identified by the user ID and
+// password of the auth protocols, while the stream cipher and obfs always use the server password.
+type Service[K comparable] struct {
+	method   *streamMethod
+	obfs     string
+	protocol string
+	config   *serverConfig
+	handler  N.TCPConnectionHandlerEx
+	access   sync.RWMutex
+	users    map[uint32]serviceUser[K]
+}
+
+type serviceUser[K comparable] struct {
+	user     K
+	password string
+}
+
+type serverConfig struct {
+	key          []byte
+	obfsParam    string
+	timeFunc     func() time.Time
+	replay       freelru.Cache[string, struct{}]
+	userPassword func(userID uint32) (string, bool)
+	hasUsers     func() bool
+}
+
+func NewService[K comparable](options ServiceOptions) (*Service[K], error) {
+	method, err := newStreamMethod(options.Method)
+	if err != nil {
+		return nil, err
+	}
+	if options.Password == "" {
+		return nil, E.New("missing password")
+	}
+	timeFunc := options.TimeFunc
+	if timeFunc == nil {
+		timeFunc = time.Now
+	}
+	service := &Service[K]{
+		method:   method,
+		obfs:     options.Obfs,
+		protocol: options.Protocol,
+		handler:  options.Handler,
+		users:    make(map[uint32]serviceUser[K]),
+	}
+	service.config = &serverConfig{
+		key:          method.Key(options.Password),
+		obfsParam:    options.ObfsParam,
+		timeFunc:     timeFunc,
+		replay:       common.Must1(freelru.NewSharded[string, struct{}](replayFilterSize, maphash.NewHasher[string]().Hash32)),
+		userPassword: service.userPassword,
+		hasUsers:     service.hasUsers,
+	}
+	service.config.replay.SetLifetime(2 * defaultMaxTimeDiff)
+	_, err = newServerObfs(options.Obfs, service.config)
+	if err != nil {
+		return nil, err
+	}
+	_, err = newServerProtocol(options.Protocol, service.config, nil)
+	if err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// UpdateUsers replaces the users of auth protocols, identified by user ID and password.
+func (s *Service[K]) UpdateUsers(userList []K, userIDList []uint32, passwordList []string) error {
+	if len(userList) > 0 && !isAuthProtocol(s.protocol) {
+		return E.New("users require an auth protocol")
+	}
+	users := make(map[uint32]serviceUser[K])
+	for i, user := range userList {
+		if _, loaded := users[userIDList[i]]; loaded {
+			return E.New("duplicate user ID: ", userIDList[i])
+		}
+		users[userIDList[i]] = serviceUser[K]{user, passwordList[i]}
+	}
+	s.access.Lock()
+	s.users = users
+	s.access.Unlock()
+	return nil
+}
+
+func (s *Service[K]) userPassword(userID uint32) (string, bool) {
+	s.access.RLock()
+	defer s.access.RUnlock()
+	user, loaded := s.users[userID]
+	return user.password, loaded
+}
+
+func (s *Service[K]) hasUsers() bool {
+	s.access.RLock()
+	defer s.access.RUnlock()
+	return len(s.users) > 0
+}
+
+func (s *Service[K]) NewConnection(ctx context.Context, conn net.Conn, source M.Socksaddr, onClose N.CloseHandlerFunc) error {
+	obfs, _ := newServerObfs(s.obfs, s.config)
+	serverConn := &serverConn{
+		Conn:        conn,
+		newProtocol: s.newProtocol,
+		method:      s.method,
+		key:         s.config.key,
+		obfs:        obfs,
+		buffer:      make([]byte, 32*1024),
+	}
+	// ShadowsocksR reuses the high bits of the address type as flags
+	var addressType [1]byte
+	_, err := io.ReadFull(serverConn, addressType[:])
+	if err != nil {
+		return E.Cause(err, "read destination")
+	}
+	addressType[0] &= 0x07
+	destination, err := M.SocksaddrSerializer.ReadAddrPort(io.MultiReader(bytes.NewReader(addressType[:]), serverConn))
+	if err != nil {
+		return E.Cause(err, "read destination")
+	}
+	if userID, isUser := serverConn.protocol.UserID(); isUser {
+		s.access.RLock()
+		user, loaded := s.users[userID]
+		s.access.RUnlock()
+		if !loaded {
+			return E.New("user ", userID, " removed")
+		}
+		ctx = auth.ContextWithUser(ctx, user.user)
+	}
+	s.handler.NewConnectionEx(ctx, serverConn, source, destination, onClose)
+	return nil
+}
+
+func (c *serverConfig) checkTime(unixTime uint32, maxTimeDiff time.Duration) bool {
+	timeDiff := time.Duration(int32(unixTime-uint32(c.timeFunc().Unix()))) * time.Second
+	return timeDiff >= -maxTimeDiff && timeDiff <= maxTimeDiff
+}
+
+func (c *serverConfig) checkReplay(key string) bool {
+	if c.replay.Contains(key) {
+		return false
+	}
+	c.replay.Add(key, struct{}{})
+	return true
+}
+
+func (s *Service[K]) newProtocol(iv []byte) (serverProtocol, error) {
+	return newServerProtocol(s.protocol, s.config, iv)
+}
+
+type serverConn struct {
+	net.Conn
+	newProtocol    func(iv []byte) (serverProtocol, error)
+	method         *streamMethod
+	key            []byte
+	obfs           serverObfs
+	protocol       serverProtocol
+	buffer         []byte
+	cipherBuffer   bytes.Buffer
+	protocolBuffer bytes.Buffer
+	readBuffer     bytes.Buffer
+	readStream     cipher.Stream
+	writeAccess    sync.Mutex
+	writeStream    cipher.Stream
+	writeBuffer    bytes.Buffer
+	obfsBuffer     bytes.Buffer
+	ivWritten      bool
+}
+
+func (c *serverConn) Read(p []byte) (n int, err error) {
+	for c.readBuffer.Len() == 0 {
+		n, err = c.Conn.Read(c.buffer)
+		if n > 0 {
+			decodeErr := c.decode(c.buffer[:n])
+			if decodeErr != nil {
+				return 0, decodeErr
+			}
+		}
+		if err != nil {
+			if c.readBuffer.Len() > 0 {
+				break
+			}
+			return 0, err
+		}
+	}
+	return c.readBuffer.Read(p)
+}
+
+func (c *serverConn) decode(data []byte) error {
+	response, err := c.obfs.Decode(&c.cipherBuffer, data)
+	if err != nil {
+		return err
+	}
+	if response != nil {
+		c.writeAccess.Lock()
+		_, err = c.Conn.Write(response)
+		c.writeAccess.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	if c.protocol == nil {
+		if c.cipherBuffer.Len() < c.method.ivLength {
+			return nil
+		}
+		iv := bytes.Clone(c.cipherBuffer.Next(c.method.ivLength))
+		if c.method.ivLength > 0 {
+			c.readStream, err = c.method.decryptConstructor(c.key, iv)
+			if err != nil {
+				return err
+			}
+		}
+		c.protocol, err = c.newProtocol(iv)
+		if err != nil {
+			return err
+		}
+	}
+	encrypted := c.cipherBuffer.Bytes()
+	if c.readStream != nil {
+		c.readStream.XORKeyStream(encrypted, encrypted)
+	}
+	c.protocolBuffer.Write(encrypted)
+	c.cipherBuffer.Reset()
+	return c.protocol.Decode(&c.readBuffer, &c.protocolBuffer)
+}
+
+func (c *serverConn) Write(p []byte) (n int, err error) {
+	c.writeAccess.Lock()
+	defer c.writeAccess.Unlock()
+	c.writeBuffer.Reset()
+	c.obfsBuffer.Reset()
+	if !c.ivWritten && c.method.ivLength > 0 {
+		iv := make([]byte, c.method.ivLength)
+		common.Must1(rand.Read(iv))
+		c.writeStream, err = c.method.encryptConstructor(c.key, iv)
+		if err != nil {
+			return
+		}
+		c.writeBuffer.Write(iv)
+	}
+	ivLength := c.writeBuffer.Len()
+	c.ivWritten = true
+	c.protocol.Encode(&c.writeBuffer, p)
+	if c.writeStream != nil {
+		encrypted := c.writeBuffer.Bytes()[ivLength:]
+		c.writeStream.XORKeyStream(encrypted, encrypted)
+	}
+	c.obfs.Encode(&c.obfsBuffer, c.writeBuffer.Bytes())
+	_, err = c.Conn.Write(c.obfsBuffer.Bytes())
+	if err != nil {
+		return
+	}
+	return len(p), nil
+}
+
+func (c *serverConn) Upstream() any {
+	return c.Conn
+}
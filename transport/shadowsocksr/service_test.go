@@ -0,0 +1,149 @@
+package shadowsocksr
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/auth"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+
+	"github.com/stretchr/testify/require"
+)
+
+type echoHandler struct {
+	t           *testing.T
+	destination M.Socksaddr
+	user        chan string
+}
+
+func (h *echoHandler) NewConnectionEx(ctx context.Context, conn net.Conn, source M.Socksaddr, destination M.Socksaddr, onClose N.CloseHandlerFunc) {
+	require.Equal(h.t, h.destination, destination)
+	user, _ := auth.UserFromContext[string](ctx)
+	h.user <- user
+	_, _ = io.Copy(conn, conn)
+	conn.Close()
+}
+
+func TestService(t *testing.T) {
+	t.Parallel()
+	for _, method := range MethodList {
+		for _, obfs := range ObfsList {
+			for _, protocol := range ProtocolList {
+				t.Run(method+"/"+obfs+"/"+protocol, func(t *testing.T) {
+					t.Parallel()
+					var users []string
+					var userIDs []uint32
+					var passwords []string
+					var protocolParam string
+					if isAuthProtocol(protocol) {
+						users = []string{"alice", "bob"}
+						userIDs = []uint32{1, 2}
+						passwords = []string{"alice-password", "bob-password"}
+						protocolParam = "2:bob-password"
+					}
+					testService(t, method, obfs, protocol, protocolParam, users, userIDs, passwords)
+				})
+			}
+		}
+	}
+	t.Run("single user", func(t *testing.T) {
+		t.Parallel()
+		testService(t, "aes-256-cfb", "plain", "auth_chain_a", "", nil, nil, nil)
+	})
+}
+
+func TestServiceBadUser(t *testing.T) {
+	t.Parallel()
+	for _, protocol := range ProtocolList[1:] {
+		t.Run(protocol, func(t *testing.T) {
+			t.Parallel()
+			service, err := NewService[string](ServiceOptions{
+				Method:   "aes-128-ctr",
+				Password: "password",
+				Protocol: protocol,
+				Handler:  &echoHandler{t: t},
+			})
+			require.NoError(t, err)
+			require.NoError(t, service.UpdateUsers([]string{"alice"}, []uint32{1}, []string{"alice-password"}))
+			serverConn, conn := net.Pipe()
+			defer conn.Close()
+			result := make(chan error, 1)
+			go func() {
+				result <- service.NewConnection(context.Background(), serverConn, M.Socksaddr{}, nil)
+			}()
+			clientConn, err := newTestClientConn(conn, "aes-128-ctr", "password", "plain", "", protocol, "1:bad-password")
+			require.NoError(t, err)
+			request := bytes.NewBuffer(nil)
+			require.NoError(t, M.SocksaddrSerializer.WriteAddrPort(request, M.ParseSocksaddr("example.com:443")))
+			_, err = clientConn.Write(request.Bytes())
+			require.NoError(t, err)
+			conn.Close()
+			require.Error(t, <-result)
+		})
+	}
+}
+
+func testService(t *testing.T, method, obfs, protocol, protocolParam string, users []string, userIDs []uint32, passwords []string) {
+	destination := M.ParseSocksaddr("example.com:443")
+	handler := &echoHandler{t: t, destination: destination, user: make(chan string, 1)}
+	service, err := NewService[string](ServiceOptions{
+		Method:    method,
+		Password:  "password",
+		Obfs:      obfs,
+		ObfsParam: "example.org",
+		Protocol:  protocol,
+		Handler:   handler,
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.UpdateUsers(users, userIDs, passwords))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		err = service.NewConnection(context.Background(), conn, M.SocksaddrFromNet(conn.RemoteAddr()), nil)
+		if err != nil {
+			conn.Close()
+			handler.user <- err.Error()
+		}
+	}()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	clientConn, err := newTestClientConn(conn, method, "password", obfs, "example.org", protocol, protocolParam)
+	require.NoError(t, err)
+	request := bytes.NewBuffer(nil)
+	require.NoError(t, M.SocksaddrSerializer.WriteAddrPort(request, destination))
+	request.WriteString("hello")
+	_, err = clientConn.Write(request.Bytes())
+	require.NoError(t, err)
+	response := make([]byte, 5)
+	_, err = io.ReadFull(clientConn, response)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(response))
+	if len(users) > 0 {
+		require.Equal(t, "bob", <-handler.user)
+	} else {
+		require.Equal(t, "", <-handler.user)
+	}
+	for _, size := range []int{1, 1000, 1500, 8200, 64 * 1024} {
+		payload := make([]byte, size)
+		common.Must1(rand.Read(payload))
+		go func() {
+			_, _ = clientConn.Write(payload)
+		}()
+		received := make([]byte, size)
+		_, err = io.ReadFull(clientConn, received)
+		require.NoError(t, err)
+		require.Equal(t, payload, received)
+	}
+}
@@ -0,0 +1,244 @@
+package v2raysplithttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/common/tls"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing-box/transport/v2rayhttp"
+	E "github.com/sagernet/sing/common/exceptions"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+	sHTTP "github.com/sagernet/sing/protocol/http"
+
+	"github.com/gofrs/uuid/v5"
+	"golang.org/x/net/http2"
+)
+
+var _ adapter.V2RayClientTransport = (*Client)(nil)
+
+type Client struct {
+	ctx                  context.Context
+	transport            http.RoundTripper
+	requestURL           url.URL
+	host                 string
+	headers              http.Header
+	maxUploadSize        int
+	maxConcurrentUploads int
+}
+
+func NewClient(ctx context.Context, dialer N.Dialer, serverAddr M.Socksaddr, options option.V2RaySplitHTTPOptions, tlsConfig tls.Config) (adapter.V2RayClientTransport, error) {
+	var transport http.RoundTripper
+	if tlsConfig == nil {
+		transport = &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, serverAddr)
+			},
+		}
+	} else {
+		if len(tlsConfig.NextProtos()) == 0 {
+			tlsConfig.SetNextProtos([]string{http2.NextProtoTLS})
+		}
+		transport = &http2.Transport{
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.STDConfig) (net.Conn, error) {
+				conn, err := dialer.DialContext(ctx, network, serverAddr)
+				if err != nil {
+					return nil, err
+				}
+				return tls.ClientHandshake(ctx, conn, tlsConfig)
+			},
+		}
+	}
+	var host string
+	if options.Host != "" {
+		host = options.Host
+	} else if tlsConfig != nil && tlsConfig.ServerName() != "" {
+		host = tlsConfig.ServerName()
+	} else {
+		host = serverAddr.String()
+	}
+	var requestURL url.URL
+	if tlsConfig == nil {
+		requestURL.Scheme = "http"
+	} else {
+		requestURL.Scheme = "https"
+	}
+	requestURL.Host = serverAddr.String()
+	err := sHTTP.URLSetPath(&requestURL, normalizePath(options.Path))
+	if err != nil {
+		return nil, E.Cause(err, "parse path")
+	}
+	client := &Client{
+		ctx:                  ctx,
+		transport:            transport,
+		requestURL:           requestURL,
+		host:                 host,
+		headers:              options.Headers.Build(),
+		maxUploadSize:        int(options.MaxUploadSize),
+		maxConcurrentUploads: int(options.MaxConcurrentUploads),
+	}
+	if client.maxUploadSize == 0 {
+		client.maxUploadSize = defaultMaxUploadSize
+	}
+	if client.maxConcurrentUploads == 0 {
+		client.maxConcurrentUploads = defaultMaxConcurrentUploads
+	}
+	return client, nil
+}
+
+func (c *Client) DialContext(ctx context.Context) (net.Conn, error) {
+	sessionURL := c.requestURL
+	sessionURL.Path += uuid.Must(uuid.NewV4()).String()
+	sessionCtx, cancel := context.WithCancelCause(c.ctx)
+	writer := &uploadWriter{
+		ctx:     sessionCtx,
+		cancel:  cancel,
+		client:  c,
+		baseURL: sessionURL,
+		uploads: make(chan struct{}, c.maxConcurrentUploads),
+		ready:   make(chan struct{}),
+	}
+	conn := &clientConn{v2rayhttp.NewLateHTTPConn(writer), writer}
+	go func() {
+		request := c.newRequest(sessionCtx, http.MethodGet, sessionURL, nil)
+		response, err := c.transport.RoundTrip(request)
+		if err == nil && response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			err = E.New("v2ray-splithttp: unexpected status: ", response.Status)
+		}
+		if err != nil {
+			cancel(err)
+			conn.Setup(nil, err)
+			return
+		}
+		conn.Setup(response.Body, nil)
+		close(writer.ready)
+	}()
+	return conn, nil
+}
+
+type clientConn struct {
+	*v2rayhttp.HTTP2Conn
+	writer *uploadWriter
+}
+
+// Close flushes pending uploads before closing the download, which closes the session on the server.
+func (c *clientConn) Close() error {
+	return E.Errors(c.writer.Close(), c.HTTP2Conn.Close())
+}
+
+func (c *Client) newRequest(ctx context.Context, method string, requestURL url.URL, body []byte) *http.Request {
+	query := requestURL.Query()
+	query.Set("x_padding", randomPadding())
+	requestURL.RawQuery = query.Encode()
+	request := &http.Request{
+		Method: method,
+		URL:    &requestURL,
+		Header: c.headers.Clone(),
+		Host:   c.host,
+	}
+	if host := request.Header.Get("Host"); host != "" {
+		request.Header.Del("Host")
+		request.Host = host
+	}
+	if body != nil {
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		request.ContentLength = int64(len(body))
+	}
+	return request.WithContext(ctx)
+}
+
+func (c *Client) Close() error {
+	if transport, isHTTPTransport := c.transport.(*http.Transport); isHTTPTransport {
+		transport.CloseIdleConnections()
+	} else if transport, isHTTP2Transport := c.transport.(*http2.Transport); isHTTP2Transport {
+		transport.CloseIdleConnections()
+	}
+	return nil
+}
+
+// uploadWriter sends each write as POST requests of at most maxUploadSize bytes,
+// with at most maxConcurrentUploads requests in flight.
+type uploadWriter struct {
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	client  *Client
+	baseURL url.URL
+	access  sync.Mutex
+	nextSeq uint64
+	uploads chan struct{}
+	ready   chan struct{}
+}
+
+func (w *uploadWriter) Write(p []byte) (n int, err error) {
+	w.access.Lock()
+	defer w.access.Unlock()
+	select {
+	case <-w.ready:
+	case <-w.ctx.Done():
+		return 0, context.Cause(w.ctx)
+	}
+	for len(p) > 0 {
+		select {
+		case w.uploads <- struct{}{}:
+		case <-w.ctx.Done():
+			return n, context.Cause(w.ctx)
+		}
+		chunkSize := min(len(p), w.client.maxUploadSize)
+		payload := make([]byte, chunkSize)
+		copy(payload, p)
+		go w.upload(w.nextSeq, payload)
+		w.nextSeq++
+		n += chunkSize
+		p = p[chunkSize:]
+	}
+	return
+}
+
+func (w *uploadWriter) upload(seq uint64, payload []byte) {
+	defer func() {
+		<-w.uploads
+	}()
+	uploadURL := w.baseURL
+	uploadURL.Path += "/" + strconv.FormatUint(seq, 10)
+	response, err := w.client.transport.RoundTrip(w.client.newRequest(w.ctx, http.MethodPost, uploadURL, payload))
+	if err == nil {
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			err = E.New("v2ray-splithttp: unexpected upload status: ", response.Status)
+		}
+	}
+	if err != nil {
+		w.cancel(err)
+	}
+}
+
+// Close waits for pending uploads before cancelling the session.
+func (w *uploadWriter) Close() error {
+	w.access.Lock()
+	defer w.access.Unlock()
+	timer := time.NewTimer(C.TCPTimeout)
+	defer timer.Stop()
+flush:
+	for i := 0; i < cap(w.uploads); i++ {
+		select {
+		case w.uploads <- struct{}{}:
+		case <-w.ctx.Done():
+			break flush
+		case <-timer.C:
+			break flush
+		}
+	}
+	w.cancel(net.ErrClosed)
+	return nil
+}
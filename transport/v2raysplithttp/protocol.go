@@ -0,0 +1,37 @@
+package v2raysplithttp
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// SplitHTTP carries the downlink of a session in a streaming GET response to {path}/{session},
+// and the uplink in POST requests to {path}/{session}/{seq}, reordered by seq on the server.
+//
+// The server creates a session on its first GET or POST request, since other clients may upload before the GET,
+// and closes it when the GET request ends, or after sessionTimeout if no GET arrives.
+const (
+	defaultMaxUploadSize        = 1000000
+	defaultMaxConcurrentUploads = 100
+	maxSessions                 = 4096
+	maxSessionBufferSize        = 10 * 1024 * 1024
+	maxBufferSize               = 256 * 1024 * 1024
+	sessionTimeout              = 30 * time.Second
+	paddingMinLength            = 100
+	paddingMaxLength            = 1000
+)
+
+func normalizePath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return path
+}
+
+func randomPadding() string {
+	return strings.Repeat("X", paddingMinLength+rand.Intn(paddingMaxLength-paddingMinLength+1))
+}
@@ -0,0 +1,105 @@
+package v2raysplithttp
+
+import (
+	"io"
+	"sync"
+
+	"github.com/sagernet/sing/common/atomic"
+	E "github.com/sagernet/sing/common/exceptions"
+)
+
+var errBufferFull = E.New("too many buffered bytes of all sessions")
+
+// uploadQueue reassembles uploads that may arrive out of order.
+//
+// Buffered bytes are limited for the queue, and for all queues sharing totalSize.
+type uploadQueue struct {
+	access       sync.Mutex
+	cond         *sync.Cond
+	packets      map[uint64][]byte
+	current      []byte
+	nextSeq      uint64
+	maxPackets   int
+	size         int64
+	maxSize      int64
+	totalSize    *atomic.Int64
+	maxTotalSize int64
+	closed       bool
+}
+
+func newUploadQueue(maxPackets int, maxSize int64, totalSize *atomic.Int64, maxTotalSize int64) *uploadQueue {
+	queue := &uploadQueue{
+		packets:      make(map[uint64][]byte),
+		maxPackets:   maxPackets,
+		maxSize:      maxSize,
+		totalSize:    totalSize,
+		maxTotalSize: maxTotalSize,
+	}
+	queue.cond = sync.NewCond(&queue.access)
+	return queue
+}
+
+func (q *uploadQueue) Push(seq uint64, payload []byte) error {
+	q.access.Lock()
+	defer q.access.Unlock()
+	if q.closed {
+		return io.ErrClosedPipe
+	}
+	if seq < q.nextSeq {
+		return E.New("duplicate upload: ", seq)
+	}
+	if _, loaded := q.packets[seq]; loaded {
+		return E.New("duplicate upload: ", seq)
+	}
+	if len(q.packets) >= q.maxPackets {
+		return E.New("too many buffered uploads")
+	}
+	payloadSize := int64(len(payload))
+	if q.size+payloadSize > q.maxSize {
+		return E.New("too many buffered bytes")
+	}
+	if q.totalSize.Add(payloadSize) > q.maxTotalSize {
+		q.totalSize.Add(-payloadSize)
+		return errBufferFull
+	}
+	q.size += payloadSize
+	q.packets[seq] = payload
+	q.cond.Broadcast()
+	return nil
+}
+
+func (q *uploadQueue) Read(p []byte) (n int, err error) {
+	q.access.Lock()
+	defer q.access.Unlock()
+	for len(q.current) == 0 {
+		if payload, loaded := q.packets[q.nextSeq]; loaded {
+			delete(q.packets, q.nextSeq)
+			q.nextSeq++
+			q.release(int64(len(payload)))
+			q.current = payload
+			continue
+		}
+		if q.closed {
+			return 0, io.EOF
+		}
+		q.cond.Wait()
+	}
+	n = copy(p, q.current)
+	q.current = q.current[n:]
+	return
+}
+
+func (q *uploadQueue) Close() error {
+	q.access.Lock()
+	defer q.access.Unlock()
+	q.closed = true
+	q.release(q.size)
+	q.packets = nil
+	q.cond.Broadcast()
+	return nil
+}
+
+func (q *uploadQueue) release(size int64) {
+	q.size -= size
+	q.totalSize.Add(-size)
+}
@@ -0,0 +1,293 @@
+package v2raysplithttp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/common/tls"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing-box/transport/v2rayhttp"
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/atomic"
+	E "github.com/sagernet/sing/common/exceptions"
+	"github.com/sagernet/sing/common/logger"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+	aTLS "github.com/sagernet/sing/common/tls"
+	sHttp "github.com/sagernet/sing/protocol/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+var _ adapter.V2RayServerTransport = (*Server)(nil)
+
+var errTooManySessions = E.New("too many sessions")
+
+type Server struct {
+	ctx                  context.Context
+	logger               logger.ContextLogger
+	tlsConfig            tls.ServerConfig
+	handler              adapter.V2RayServerTransportHandler
+	httpServer           *http.Server
+	h2cHandler           http.Handler
+	host                 string
+	path                 string
+	headers              http.Header
+	maxUploadSize        int
+	maxConcurrentUploads int
+	maxSessionBuffer     int64
+	maxBuffer            int64
+	bufferSize           atomic.Int64
+	sessionTimeout       time.Duration
+	sessionAccess        sync.Mutex
+	sessions             map[string]*serverSession
+}
+
+type serverSession struct {
+	queue       *uploadQueue
+	reaper      *time.Timer
+	downloading bool
+}
+
+func NewServer(ctx context.Context, logger logger.ContextLogger, options option.V2RaySplitHTTPOptions, tlsConfig tls.ServerConfig, handler adapter.V2RayServerTransportHandler) (*Server, error) {
+	server := &Server{
+		ctx:                  ctx,
+		logger:               logger,
+		tlsConfig:            tlsConfig,
+		handler:              handler,
+		host:                 options.Host,
+		path:                 normalizePath(options.Path),
+		headers:              options.Headers.Build(),
+		maxUploadSize:        int(options.MaxUploadSize),
+		maxConcurrentUploads: int(options.MaxConcurrentUploads),
+		sessionTimeout:       sessionTimeout,
+		sessions:             make(map[string]*serverSession),
+	}
+	if server.maxUploadSize == 0 {
+		server.maxUploadSize = defaultMaxUploadSize
+	}
+	if server.maxConcurrentUploads == 0 {
+		server.maxConcurrentUploads = defaultMaxConcurrentUploads
+	}
+	server.maxSessionBuffer = max(maxSessionBufferSize, int64(server.maxUploadSize))
+	server.maxBuffer = max(maxBufferSize, server.maxSessionBuffer)
+	server.httpServer = &http.Server{
+		Handler:           server,
+		ReadHeaderTimeout: C.TCPTimeout,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return log.ContextWithNewID(ctx)
+		},
+	}
+	server.h2cHandler = h2c.NewHandler(server, &http2.Server{})
+	return server, nil
+}
+
+func (s *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method == "PRI" && len(request.Header) == 0 && request.URL.Path == "*" && request.Proto == "HTTP/2.0" {
+		s.h2cHandler.ServeHTTP(writer, request)
+		return
+	}
+	if s.host != "" && request.Host != s.host {
+		s.invalidRequest(writer, request, http.StatusBadRequest, E.New("bad host: ", request.Host))
+		return
+	}
+	if !strings.HasPrefix(request.URL.Path, s.path) {
+		s.invalidRequest(writer, request, http.StatusNotFound, E.New("bad path: ", request.URL.Path))
+		return
+	}
+	sessionID, seqString, isUpload := strings.Cut(strings.TrimPrefix(request.URL.Path, s.path), "/")
+	if sessionID == "" {
+		s.invalidRequest(writer, request, http.StatusBadRequest, E.New("missing session ID"))
+		return
+	}
+	for key, values := range s.headers {
+		for _, value := range values {
+			writer.Header().Set(key, value)
+		}
+	}
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.Header().Set("X-Padding", randomPadding())
+	if isUpload {
+		if request.Method != http.MethodPost {
+			s.invalidRequest(writer, request, http.StatusMethodNotAllowed, E.New("bad method: ", request.Method))
+			return
+		}
+		seq, err := strconv.ParseUint(seqString, 10, 64)
+		if err != nil {
+			s.invalidRequest(writer, request, http.StatusBadRequest, E.Cause(err, "bad upload seq"))
+			return
+		}
+		s.handleUpload(writer, request, sessionID, seq)
+	} else {
+		if request.Method != http.MethodGet {
+			s.invalidRequest(writer, request, http.StatusMethodNotAllowed, E.New("bad method: ", request.Method))
+			return
+		}
+		s.handleDownload(writer, request, sessionID)
+	}
+}
+
+func (s *Server) handleUpload(writer http.ResponseWriter, request *http.Request, sessionID string, seq uint64) {
+	session, err := s.loadSession(sessionID, false)
+	if err != nil {
+		s.invalidRequest(writer, request, http.StatusServiceUnavailable, err)
+		return
+	}
+	payload, err := io.ReadAll(io.LimitReader(request.Body, int64(s.maxUploadSize)+1))
+	if err != nil {
+		s.invalidRequest(writer, request, 0, E.Cause(err, "read upload"))
+		return
+	}
+	if len(payload) > s.maxUploadSize {
+		s.invalidRequest(writer, request, http.StatusRequestEntityTooLarge, E.New("upload too large"))
+		return
+	}
+	err = session.queue.Push(seq, payload)
+	if err != nil {
+		s.closeSession(sessionID, session)
+		if err == errBufferFull {
+			s.invalidRequest(writer, request, http.StatusServiceUnavailable, err)
+		} else {
+			s.invalidRequest(writer, request, http.StatusBadRequest, E.Cause(err, "push upload"))
+		}
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleDownload(writer http.ResponseWriter, request *http.Request, sessionID string) {
+	session, err := s.loadSession(sessionID, true)
+	if err == errTooManySessions {
+		s.invalidRequest(writer, request, http.StatusServiceUnavailable, err)
+		return
+	} else if err != nil {
+		s.invalidRequest(writer, request, http.StatusConflict, err)
+		return
+	}
+	defer s.closeSession(sessionID, session)
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.WriteHeader(http.StatusOK)
+	flusher, isFlusher := writer.(http.Flusher)
+	if !isFlusher {
+		s.invalidRequest(writer, request, 0, E.New("streaming not supported"))
+		return
+	}
+	flusher.Flush()
+	done := make(chan struct{})
+	conn := v2rayhttp.NewHTTP2Wrapper(&v2rayhttp.ServerHTTPConn{
+		HTTP2Conn: v2rayhttp.NewHTTPConn(session.queue, writer),
+		Flusher:   flusher,
+	})
+	s.handler.NewConnectionEx(request.Context(), conn, sHttp.SourceAddress(request), M.Socksaddr{}, N.OnceClose(func(it error) {
+		close(done)
+	}))
+	select {
+	case <-done:
+	case <-request.Context().Done():
+	}
+	conn.CloseWrapper()
+}
+
+// loadSession returns the session, creating it if it does not exist.
+//
+// Clients may upload before or alongside the download, so sessions are also created by uploads,
+// and closed by the reaper if the download does not arrive in time.
+func (s *Server) loadSession(sessionID string, download bool) (*serverSession, error) {
+	s.sessionAccess.Lock()
+	defer s.sessionAccess.Unlock()
+	session, loaded := s.sessions[sessionID]
+	if !loaded {
+		if len(s.sessions) >= maxSessions {
+			return nil, errTooManySessions
+		}
+		session = &serverSession{
+			queue: newUploadQueue(s.maxConcurrentUploads, s.maxSessionBuffer, &s.bufferSize, s.maxBuffer),
+		}
+		s.sessions[sessionID] = session
+		if !download {
+			session.reaper = time.AfterFunc(s.sessionTimeout, func() {
+				s.reapSession(sessionID, session)
+			})
+		}
+	}
+	if download {
+		if session.downloading {
+			return nil, E.New("duplicate session: ", sessionID)
+		}
+		session.downloading = true
+		if session.reaper != nil {
+			session.reaper.Stop()
+		}
+	}
+	return session, nil
+}
+
+func (s *Server) reapSession(sessionID string, session *serverSession) {
+	s.sessionAccess.Lock()
+	if session.downloading || s.sessions[sessionID] != session {
+		s.sessionAccess.Unlock()
+		return
+	}
+	delete(s.sessions, sessionID)
+	s.sessionAccess.Unlock()
+	session.queue.Close()
+}
+
+func (s *Server) closeSession(sessionID string, session *serverSession) {
+	s.sessionAccess.Lock()
+	if s.sessions[sessionID] == session {
+		delete(s.sessions, sessionID)
+	}
+	s.sessionAccess.Unlock()
+	if session.reaper != nil {
+		session.reaper.Stop()
+	}
+	session.queue.Close()
+}
+
+func (s *Server) invalidRequest(writer http.ResponseWriter, request *http.Request, statusCode int, err error) {
+	if statusCode > 0 {
+		writer.WriteHeader(statusCode)
+	}
+	s.logger.ErrorContext(request.Context(), E.Cause(err, "process connection from ", request.RemoteAddr))
+}
+
+func (s *Server) Network() []string {
+	return []string{N.NetworkTCP}
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	if s.tlsConfig != nil {
+		if len(s.tlsConfig.NextProtos()) == 0 {
+			s.tlsConfig.SetNextProtos([]string{http2.NextProtoTLS, "http/1.1"})
+		} else if !common.Contains(s.tlsConfig.NextProtos(), http2.NextProtoTLS) {
+			s.tlsConfig.SetNextProtos(append([]string{http2.NextProtoTLS}, s.tlsConfig.NextProtos()...))
+		}
+		listener = aTLS.NewListener(listener, s.tlsConfig)
+	}
+	return s.httpServer.Serve(listener)
+}
+
+func (s *Server) ServePacket(listener net.PacketConn) error {
+	return os.ErrInvalid
+}
+
+func (s *Server) Close() error {
+	return common.Close(common.PtrOrNil(s.httpServer))
+}
@@ -0,0 +1,196 @@
+package v2raysplithttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/option"
+	F "github.com/sagernet/sing/common/format"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testHandler struct {
+	conns chan net.Conn
+}
+
+func (h *testHandler) NewConnectionEx(ctx context.Context, conn net.Conn, source M.Socksaddr, destination M.Socksaddr, onClose N.CloseHandlerFunc) {
+	h.conns <- &testConn{conn, onClose}
+}
+
+type testConn struct {
+	net.Conn
+	onClose N.CloseHandlerFunc
+}
+
+func (c *testConn) Close() error {
+	c.onClose(nil)
+	return c.Conn.Close()
+}
+
+func newTestServer(t *testing.T, options option.V2RaySplitHTTPOptions) (*Server, *testHandler, M.Socksaddr) {
+	handler := &testHandler{conns: make(chan net.Conn, 1)}
+	server, err := NewServer(context.Background(), log.NewNOPFactory().Logger(), options, nil, handler)
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	return server, handler, M.SocksaddrFromNet(listener.Addr())
+}
+
+func TestSplitHTTP(t *testing.T) {
+	t.Parallel()
+	options := option.V2RaySplitHTTPOptions{
+		Path:          "/split",
+		MaxUploadSize: 1024,
+	}
+	_, handler, serverAddr := newTestServer(t, options)
+	client, err := NewClient(context.Background(), N.SystemDialer, serverAddr, options, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	conn, err := client.DialContext(context.Background())
+	require.NoError(t, err)
+	payload := make([]byte, 64*1024)
+	_, err = rand.Read(payload)
+	require.NoError(t, err)
+	_, err = conn.Write(payload)
+	require.NoError(t, err)
+	var serverConn net.Conn
+	select {
+	case serverConn = <-handler.conns:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not accepted")
+	}
+	received := make([]byte, len(payload))
+	_, err = io.ReadFull(serverConn, received)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(payload, received))
+
+	_, err = serverConn.Write([]byte("pong"))
+	require.NoError(t, err)
+	response := make([]byte, 4)
+	_, err = io.ReadFull(conn, response)
+	require.NoError(t, err)
+	require.Equal(t, "pong", string(response))
+
+	// pending uploads are flushed on close
+	_, err = conn.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	_, err = io.ReadFull(serverConn, received)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(payload, received))
+	serverConn.Close()
+}
+
+func TestSplitHTTPUploadBeforeDownload(t *testing.T) {
+	t.Parallel()
+	_, handler, serverAddr := newTestServer(t, option.V2RaySplitHTTPOptions{Path: "/split"})
+	sessionURL := "http://" + serverAddr.String() + "/split/session"
+	upload := func(seq int, payload string) {
+		response, err := http.Post(F.ToString(sessionURL, "/", seq), "", bytes.NewReader([]byte(payload)))
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	}
+	upload(1, "pong")
+	upload(0, "ping")
+	response, err := http.Get(sessionURL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var serverConn net.Conn
+	select {
+	case serverConn = <-handler.conns:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not accepted")
+	}
+	defer serverConn.Close()
+	received := make([]byte, 8)
+	_, err = io.ReadFull(serverConn, received)
+	require.NoError(t, err)
+	require.Equal(t, "pingpong", string(received))
+	upload(2, "ping")
+	_, err = io.ReadFull(serverConn, received[:4])
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(received[:4]))
+
+	_, err = serverConn.Write([]byte("pong"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(response.Body, received[:4])
+	require.NoError(t, err)
+	require.Equal(t, "pong", string(received[:4]))
+
+	duplicateResponse, err := http.Get(sessionURL)
+	require.NoError(t, err)
+	duplicateResponse.Body.Close()
+	require.Equal(t, http.StatusConflict, duplicateResponse.StatusCode)
+}
+
+func TestSplitHTTPSession(t *testing.T) {
+	t.Parallel()
+	server, _, serverAddr := newTestServer(t, option.V2RaySplitHTTPOptions{})
+	server.sessionTimeout = 100 * time.Millisecond
+	response, err := http.Post("http://"+serverAddr.String()+"/unknown/1", "", bytes.NewReader([]byte("ping")))
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	// sessions without a download are reaped
+	require.Eventually(t, func() bool {
+		server.sessionAccess.Lock()
+		defer server.sessionAccess.Unlock()
+		return len(server.sessions) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Zero(t, server.bufferSize.Load())
+
+	server.sessionAccess.Lock()
+	for i := 0; i < maxSessions; i++ {
+		server.sessions[F.ToString("session", i)] = &serverSession{}
+	}
+	server.sessionAccess.Unlock()
+	response, err = http.Get("http://" + serverAddr.String() + "/session")
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	response, err = http.Post("http://"+serverAddr.String()+"/session/0", "", bytes.NewReader([]byte("ping")))
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+}
+
+func TestSplitHTTPBufferLimit(t *testing.T) {
+	t.Parallel()
+	server, _, serverAddr := newTestServer(t, option.V2RaySplitHTTPOptions{})
+	server.maxSessionBuffer = 8
+	server.maxBuffer = 12
+	upload := func(sessionID string, seq int) int {
+		response, err := http.Post(F.ToString("http://", serverAddr.String(), "/", sessionID, "/", seq), "", bytes.NewReader([]byte("ping")))
+		require.NoError(t, err)
+		response.Body.Close()
+		return response.StatusCode
+	}
+	// uploads waiting for seq 0 are buffered
+	require.Equal(t, http.StatusOK, upload("session1", 1))
+	require.Equal(t, http.StatusOK, upload("session1", 2))
+	require.Equal(t, http.StatusBadRequest, upload("session1", 3))
+	require.Zero(t, server.bufferSize.Load())
+
+	require.Equal(t, http.StatusOK, upload("session2", 1))
+	require.Equal(t, http.StatusOK, upload("session2", 2))
+	require.Equal(t, http.StatusOK, upload("session3", 1))
+	require.Equal(t, http.StatusServiceUnavailable, upload("session3", 2))
+	require.Equal(t, int64(8), server.bufferSize.Load())
+}